@@ -2,7 +2,15 @@ package main
 
 import (
 	"context"
-	"log"
+	"crypto/rand"
+	"crypto/tls"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,40 +19,157 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/krawwwwy/book-library-api/internal/api"
+	"github.com/krawwwwy/book-library-api/internal/cache"
 	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/database"
+	"github.com/krawwwwy/book-library-api/internal/health"
+	"github.com/krawwwwy/book-library-api/internal/logging"
+	"github.com/krawwwwy/book-library-api/internal/metrics"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
 	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/openapi"
+	"github.com/krawwwwy/book-library-api/internal/ratelimit"
 	"github.com/krawwwwy/book-library-api/internal/repository"
 	"github.com/krawwwwy/book-library-api/internal/service"
-	"gorm.io/driver/postgres"
+	"github.com/krawwwwy/book-library-api/internal/sso"
+	"github.com/krawwwwy/book-library-api/internal/tracing"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"gorm.io/gorm"
 )
 
+// version задается при сборке: -ldflags "-X main.version=1.2.0"
+var version = "dev"
+
+// Общие сведения для спецификации OpenAPI, которую собирает go generate ./internal/openapi
+//
+// @title Book Library API
+// @version 1.0
+// @description API библиотеки: каталог книг, ревизии, импорт и выгрузка, библиографические ссылки и OAI-PMH.
+// @BasePath /
+//
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access-токен в виде "Bearer <токен>" или API-ключ в виде "ApiKey <ключ>"
 func main() {
-	// Загрузка конфигурации
-	cfg := config.GetConfig()
+	// Подкоманды работают с базой данных без запуска сервера
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			runMigrate(os.Args[0]+" migrate", os.Args[2:])
+			return
+		case "seed":
+			runSeed(os.Args[0]+" seed", os.Args[2:])
+			return
+		}
+	}
+
+	// Загрузка конфигурации: файл, переменные окружения и флаги
+	cfg, opts, err := config.Load(os.Args[0], os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fatal("Ошибка загрузки конфигурации", "error", err)
+	}
+	if opts.PrintConfig {
+		if err := cfg.WriteRedacted(os.Stdout); err != nil {
+			fatal("Ошибка вывода конфигурации", "error", err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		fatal("Неверная конфигурация", "error", err)
+	}
+	if opts.PrintConfig {
+		return
+	}
+	logger := setupLogger(cfg.Log)
+
+	// Сигнал остановки прерывает и запуск, например ожидание базы данных
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// Подключение к базе данных
-	db, err := gorm.Open(postgres.Open(cfg.DB.GetDSN()), &gorm.Config{})
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing, os.Stdout)
 	if err != nil {
-		log.Fatalf("Ошибка подключения к базе данных: %v", err)
+		fatal("Ошибка настройки трассировки", "error", err)
 	}
 
-	// Автоматическая миграция моделей
-	if err := db.AutoMigrate(&model.Book{}); err != nil {
-		log.Fatalf("Ошибка миграции базы данных: %v", err)
+	// Подключение к базе данных с повторными попытками, пока PostgreSQL запускается
+	db, err := database.Open(ctx, cfg.DB)
+	if err != nil {
+		fatal("Ошибка подключения к базе данных", "error", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		fatal("Ошибка подключения к базе данных", "error", err)
+	}
+
+	// Применение версионированных миграций схемы
+	if err := migrateOnStart(ctx, db, cfg.DB); err != nil {
+		fatal("Ошибка миграции базы данных", "error", err)
 	}
 
 	// Инициализация репозитория
-	bookRepo := repository.NewBookRepository(db)
+	bookStore, revisionStore := newBookStores(db, cfg.DB.Driver)
+
+	// Метрики Prometheus: время запросов к хранилищу измеряется обертками репозиториев
+	var appMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		appMetrics = metrics.New()
+		appMetrics.RegisterDB(sqlDB)
+		bookStore = appMetrics.BookStore(bookStore)
+		revisionStore = appMetrics.RevisionStore(revisionStore)
+	}
+	userRepo := repository.NewUserRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
 
 	// Инициализация сервиса
-	bookService := service.NewBookService(bookRepo)
+	bookService := service.NewBookService(bookStore)
+	if appMetrics != nil {
+		bookService.SetEvents(appMetrics)
+	}
+	if cfg.Cache.Enabled {
+		bookService.SetCache(cache.NewLRU(cfg.Cache.Size), cfg.Cache.TTL.Duration)
+	}
+	revisionService := service.NewRevisionService(revisionStore, bookService)
+
+	if cfg.Auth.JWTSecret == "" {
+		cfg.Auth.JWTSecret = generateSecret()
+		slog.Warn("JWT_SECRET не задан: используется случайный ключ, токены перестанут действовать после перезапуска")
+	}
+	authService := service.NewAuthService(userRepo, cfg.Auth)
+	userService := service.NewUserService(userRepo)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	if cfg.Auth.AdminUsername != "" {
+		if err := authService.EnsureUser(ctx, cfg.Auth.AdminUsername, cfg.Auth.AdminPassword, model.RoleAdmin); err != nil {
+			fatal("Ошибка создания администратора", "error", err)
+		}
+	}
 
 	// Инициализация обработчика
 	bookHandler := api.NewBookHandler(bookService)
+	revisionHandler := api.NewRevisionHandler(revisionService)
+	oaiHandler := api.NewOAIHandler(bookService, cfg.OAI)
+	authHandler := api.NewAuthHandler(authService)
+	userHandler := api.NewUserHandler(userService)
+	apiKeyHandler := api.NewAPIKeyHandler(apiKeyService)
+	systemHandler := api.NewSystemHandler(sqlDB)
+	checker, err := newHealthChecker(sqlDB, cfg.DB)
+	if err != nil {
+		fatal("Ошибка настройки проверок готовности", "error", err)
+	}
+	healthHandler := api.NewHealthHandler(checker)
 
-	// Инициализация роутера Gin
-	router := gin.Default()
+	// Инициализация роутера Gin. Запросы записываются в журнал с идентификатором,
+	// который передается в контексте до запросов к базе данных и в ответы с ошибками.
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.Tracing(), middleware.Logger(logger), middleware.Recovery(logger))
+
+	// Сжатие ответов, включая статические файлы и выгрузку каталога
+	if cfg.Server.Compression {
+		router.Use(middleware.Compress())
+	}
 
 	// Обслуживание статических файлов
 	router.Static("/css", "./public/css")
@@ -52,36 +177,218 @@ func main() {
 	router.StaticFile("/", "./public/index.html")
 	router.StaticFile("/books.html", "./public/books.html")
 
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		fatal("Ошибка настройки доверенных прокси", "error", err)
+	}
+
+	// Учет запросов в метриках, включая отклоненные ограничением частоты
+	if appMetrics != nil {
+		router.Use(middleware.Metrics(appMetrics))
+		router.GET(cfg.Metrics.Path, gin.WrapH(appMetrics.Handler()))
+	}
+
+	// Пробы, спецификация OpenAPI и Swagger UI доступны без авторизации и ограничения частоты
+	healthHandler.RegisterProbes(router)
+	api.NewDocsHandler().RegisterRoutes(router)
+
+	// Ограничение времени обработки запроса; контекст запроса передается до запросов к базе данных
+	router.Use(middleware.Timeout(cfg.Server.RequestTimeout.Duration, timeoutRules(cfg.Server.RouteTimeouts)))
+
+	// Неудачные попытки аутентификации ограничиваются по IP до проверки учетных данных
+	rateLimitStore := ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Enabled {
+		failedAuth := cfg.RateLimit.FailedAuth
+		router.Use(middleware.LimitFailedAuth(rateLimitStore, ratelimit.PerMinute(failedAuth.RequestsPerMinute, failedAuth.Burst)))
+	}
+
+	// Аутентификация по заголовку Authorization: access-токен или API-ключ
+	router.Use(middleware.Authenticate(authService, apiKeyService))
+
+	// Ограничение частоты запросов по клиенту
+	if cfg.RateLimit.Enabled {
+		router.Use(middleware.RateLimit(rateLimitStore, rateLimitRules(cfg.RateLimit)))
+	}
+
+	// Проверка запросов и ответов по спецификации OpenAPI
+	if cfg.OpenAPI.Enabled() {
+		doc, err := openapi.Load()
+		if err != nil {
+			fatal("Ошибка загрузки спецификации OpenAPI", "error", err)
+		}
+		router.Use(middleware.OpenAPIValidator(doc, middleware.OpenAPIValidatorOptions{
+			Requests:  cfg.OpenAPI.ValidateRequests,
+			Responses: cfg.OpenAPI.ValidateResponses,
+		}, logger))
+	}
+
 	// Регистрация API маршрутов
+	authHandler.RegisterRoutes(router)
+	if cfg.OIDC.Enabled() {
+		// Недоступный провайдер не должен задерживать запуск без ограничения
+		discoveryCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ssoClient, err := sso.NewClient(discoveryCtx, cfg.OIDC)
+		cancel()
+		if err != nil {
+			fatal("Ошибка настройки OIDC", "error", err)
+		}
+		api.NewOIDCHandler(ssoClient, authService).RegisterRoutes(router)
+	}
+	userHandler.RegisterRoutes(router)
+	apiKeyHandler.RegisterRoutes(router)
+	systemHandler.RegisterRoutes(router)
+	healthHandler.RegisterRoutes(router)
 	bookHandler.RegisterRoutes(router)
+	revisionHandler.RegisterRoutes(router)
+	oaiHandler.RegisterRoutes(router)
 
-	// Настройка сервера
+	// Настройка сервера. Контексты запросов наследуются от requestCtx,
+	// чтобы при затянувшемся выключении прервать запросы к базе данных.
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 	srv := &http.Server{
-		Addr:    ":" + cfg.Server.Port,
-		Handler: router,
+		Addr:        fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return requestCtx },
+	}
+
+	// С TLS сервер согласует HTTP/2 автоматически; h2c - HTTP/2 без TLS
+	// для внутренних сетей, где TLS завершается на балансировщике
+	scheme := "http"
+	switch {
+	case cfg.Server.TLSEnabled():
+		scheme = "https"
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	case cfg.Server.H2C:
+		h2s := &http2.Server{}
+		// ConfigureServer связывает соединения HTTP/2 с srv.Shutdown
+		if err := http2.ConfigureServer(srv, h2s); err != nil {
+			fatal("Ошибка настройки HTTP/2", "error", err)
+		}
+		srv.Handler = h2c.NewHandler(router, h2s)
 	}
 
 	// Запуск сервера в горутине
 	go func() {
-		log.Printf("Сервер запущен на http://localhost:%s", cfg.Server.Port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Ошибка запуска сервера: %s\n", err)
+		slog.Info("Сервер запущен", "addr", srv.Addr, "url", fmt.Sprintf("%s://localhost:%d", scheme, cfg.Server.Port),
+			"h2c", cfg.Server.H2C, "compression", cfg.Server.Compression)
+		var err error
+		if cfg.Server.TLSEnabled() {
+			err = srv.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			fatal("Ошибка запуска сервера", "error", err)
 		}
 	}()
 
-	// Ожидание сигнала для graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("Выключение сервера...")
+	// Ожидание сигнала для graceful shutdown; повторный сигнал завершает процесс сразу
+	<-ctx.Done()
+	stop()
+	slog.Info("Выключение сервера")
+
+	// /readyz начинает отвечать 503; пока балансировщик это замечает,
+	// сервер продолжает принимать запросы
+	checker.Shutdown()
+	if delay := cfg.Server.ShutdownDelay.Duration; delay > 0 {
+		slog.Info("Ожидание перед закрытием сервера", "delay", delay.String())
+		time.Sleep(delay)
+	}
 
 	// Контекст для graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Ошибка при выключении сервера:", err)
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		// Незавершенные запросы отменяются вместе с их запросами к базе данных
+		slog.Warn("Ошибка при выключении сервера; незавершенные запросы отменены", "error", err)
+		cancelRequests()
+		srv.Close()
+	}
+
+	if err := sqlDB.Close(); err != nil {
+		slog.Error("Ошибка закрытия соединений с базой данных", "error", err)
+	}
+
+	// Оставшиеся спаны отправляются до выхода
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Ошибка отправки спанов", "error", err)
+	}
+
+	slog.Info("Сервер успешно остановлен")
+}
+
+// newHealthChecker создает проверки готовности: соединение с базой данных и,
+// для PostgreSQL, применение всех встроенных миграций
+func newHealthChecker(db *sql.DB, cfg config.DBConfig) (*health.Checker, error) {
+	checker := health.NewChecker(health.ReadBuildInfo(version))
+	checker.Add("database", db.PingContext)
+	if cfg.Driver == config.DriverPostgres {
+		migrator, err := newMigrator(db)
+		if err != nil {
+			return nil, err
+		}
+		checker.Add("migrations", migrator.Check)
+	}
+	return checker, nil
+}
+
+// newBookStores возвращает хранилища книг и ревизий для выбранного драйвера.
+// При драйвере memory книги хранятся в памяти процесса, а учетные записи
+// остаются в базе, открытой database.Open.
+func newBookStores(db *gorm.DB, driver string) (repository.BookStore, repository.RevisionStore) {
+	if driver == config.DriverMemory {
+		store := repository.NewMemoryBookStore()
+		return store, store
 	}
+	return repository.NewBookRepository(db), repository.NewRevisionRepository(db)
+}
 
-	log.Println("Сервер успешно остановлен")
-} 
\ No newline at end of file
+// setupLogger создает журнал по конфигурации и делает его журналом по умолчанию,
+// в том числе для пакета log. Режим отладки Gin выводит текст в обход журнала,
+// поэтому он включается только явно через GIN_MODE.
+func setupLogger(cfg config.LogConfig) *slog.Logger {
+	logger := logging.New(cfg, os.Stderr)
+	slog.SetDefault(logger)
+	if os.Getenv(gin.EnvGinMode) == "" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	return logger
+}
+
+// fatal записывает ошибку в журнал и завершает процесс
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// generateSecret возвращает случайный ключ подписи токенов
+func generateSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		fatal("Ошибка генерации ключа", "error", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// timeoutRules преобразует настройки маршрутов в правила ограничения времени обработки
+func timeoutRules(routes []config.RouteTimeout) []middleware.TimeoutRule {
+	rules := make([]middleware.TimeoutRule, 0, len(routes))
+	for _, route := range routes {
+		rules = append(rules, middleware.TimeoutRule{PathPrefix: route.PathPrefix, Timeout: route.Timeout.Duration})
+	}
+	return rules
+}
+
+// rateLimitRules преобразует настройки групп в правила ограничения частоты запросов
+func rateLimitRules(cfg config.RateLimitConfig) []ratelimit.Rule {
+	rules := make([]ratelimit.Rule, 0, len(cfg.Groups))
+	for _, group := range cfg.Groups {
+		rules = append(rules, ratelimit.Rule{
+			Name:       group.Name,
+			PathPrefix: group.PathPrefix,
+			Limit:      ratelimit.PerMinute(group.RequestsPerMinute, group.Burst),
+		})
+	}
+	return rules
+}
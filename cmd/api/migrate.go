@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/database"
+	"github.com/krawwwwy/book-library-api/internal/migrate"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `Использование: %s [флаги] <действие>
+
+Действия:
+  up           применить все непримененные миграции
+  down [N]     откатить N последних миграций (по умолчанию 1)
+  status       показать состояние миграций
+  create NAME  создать пару файлов новой миграции в ` + migrate.Dir + `
+`
+
+// runMigrate выполняет подкоманду migrate. Флаги и переменные окружения
+// те же, что у сервера, действие задается первым аргументом после флагов.
+func runMigrate(name string, args []string) {
+	cfg, opts, err := config.Load(name, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, migrateUsage, name)
+			return
+		}
+		fatal("Ошибка загрузки конфигурации", "error", err)
+	}
+	if len(opts.Args) == 0 {
+		fmt.Fprintf(os.Stderr, migrateUsage, name)
+		os.Exit(2)
+	}
+	action, rest := opts.Args[0], opts.Args[1:]
+
+	// Создание файлов не требует подключения к базе данных
+	if action == "create" {
+		if len(rest) == 0 {
+			fatal("Не указано название миграции")
+		}
+		up, down, err := migrate.Create(migrate.Dir, strings.Join(rest, "_"))
+		if err != nil {
+			fatal("Ошибка создания миграции", "error", err)
+		}
+		fmt.Printf("Созданы %s и %s\n", up, down)
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fatal("Неверная конфигурация", "error", err)
+	}
+	setupLogger(cfg.Log)
+	if cfg.DB.Driver != config.DriverPostgres {
+		fatal("Миграции применяются только к PostgreSQL; схема для этого драйвера создается при запуске", "driver", cfg.DB.Driver)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.Open(ctx, cfg.DB)
+	if err != nil {
+		fatal("Ошибка подключения к базе данных", "error", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		fatal("Ошибка подключения к базе данных", "error", err)
+	}
+	defer sqlDB.Close()
+
+	migrator, err := newMigrator(sqlDB)
+	if err != nil {
+		fatal("Ошибка загрузки миграций", "error", err)
+	}
+
+	switch action {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		printMigrations("Применена", applied)
+		if err != nil {
+			fatal("Ошибка миграции базы данных", "error", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Непримененных миграций нет")
+		}
+	case "down":
+		steps := 1
+		if len(rest) > 0 {
+			if steps, err = strconv.Atoi(rest[0]); err != nil {
+				fatal("Неверное число миграций", "steps", rest[0])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		printMigrations("Откачена", reverted)
+		if errors.Is(err, migrate.ErrNoMigrations) {
+			fmt.Println("Нет примененных миграций")
+			return
+		}
+		if err != nil {
+			fatal("Ошибка отката миграции", "error", err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fatal("Ошибка получения состояния миграций", "error", err)
+		}
+		printStatus(statuses)
+	default:
+		fmt.Fprintf(os.Stderr, migrateUsage, name)
+		os.Exit(2)
+	}
+}
+
+// migrateOnStart применяет миграции при запуске сервера либо, если это
+// отключено, только предупреждает о непримененных. SQL-миграции написаны
+// для PostgreSQL, для SQLite схема создается по моделям.
+func migrateOnStart(ctx context.Context, db *gorm.DB, cfg config.DBConfig) error {
+	if cfg.Driver != config.DriverPostgres {
+		return database.CreateSchema(ctx, db)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	migrator, err := newMigrator(sqlDB)
+	if err != nil {
+		return err
+	}
+
+	if !cfg.MigrateOnStart {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			slog.WarnContext(ctx, "Есть непримененные миграции, выполните migrate up", "pending", len(pending))
+		}
+		return nil
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, m := range applied {
+		slog.InfoContext(ctx, "Применена миграция", "version", m.Version, "name", m.Name)
+	}
+	return err
+}
+
+// newMigrator создает Migrator для миграций, встроенных в исполняемый файл
+func newMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	migrations, err := migrate.Embedded()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(db, migrations), nil
+}
+
+func printMigrations(verb string, migrations []migrate.Migration) {
+	for _, m := range migrations {
+		fmt.Printf("%s %04d_%s\n", verb, m.Version, m.Name)
+	}
+}
+
+func printStatus(statuses []migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ВЕРСИЯ\tНАЗВАНИЕ\tПРИМЕНЕНА")
+	for _, s := range statuses {
+		applied := "нет"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		if s.Missing {
+			applied += " (файл миграции отсутствует)"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+	}
+	w.Flush()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/database"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"github.com/krawwwwy/book-library-api/internal/seed"
+	"github.com/krawwwwy/book-library-api/internal/service"
+)
+
+const seedUsage = `Использование: %s [флаги] <набор или файл>...
+
+Наборы:
+  demo            несколько известных книг
+  load-test [N]   N сгенерированных книг (по умолчанию %d)
+
+Файлы .json, .yaml и .yml содержат список books с полями книги.
+Книги с уже существующим ISBN пропускаются, поэтому повторный запуск безопасен.
+`
+
+// runSeed выполняет подкоманду seed. Флаги и переменные окружения
+// те же, что у сервера; наборы и файлы перечисляются после флагов.
+func runSeed(name string, args []string) {
+	cfg, opts, err := config.Load(name, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, seedUsage, name, seed.DefaultLoadTestSize)
+			return
+		}
+		fatal("Ошибка загрузки конфигурации", "error", err)
+	}
+	if len(opts.Args) == 0 {
+		fmt.Fprintf(os.Stderr, seedUsage, name, seed.DefaultLoadTestSize)
+		os.Exit(2)
+	}
+	if err := cfg.Validate(); err != nil {
+		fatal("Неверная конфигурация", "error", err)
+	}
+	setupLogger(cfg.Log)
+	if cfg.DB.Driver == config.DriverMemory {
+		fatal("Драйвер memory не сохраняет данные после завершения команды; используйте postgres или sqlite")
+	}
+
+	// Данные читаются до подключения, чтобы ошибки в файлах не ждали базу данных
+	fixtures, err := seedFixtures(opts.Args)
+	if err != nil {
+		fatal("Ошибка загрузки данных", "error", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.Open(ctx, cfg.DB)
+	if err != nil {
+		fatal("Ошибка подключения к базе данных", "error", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		fatal("Ошибка подключения к базе данных", "error", err)
+	}
+	defer sqlDB.Close()
+
+	if err := migrateOnStart(ctx, db, cfg.DB); err != nil {
+		fatal("Ошибка миграции базы данных", "error", err)
+	}
+
+	// Команду запускает оператор с доступом к базе данных, поэтому книги
+	// добавляются с правами администратора
+	ctx = model.ContextWithPrincipal(ctx, &model.Principal{Username: "seed", Role: model.RoleAdmin})
+	bookService := service.NewBookService(repository.NewBookRepository(db))
+	for _, f := range fixtures {
+		report, err := seed.Seed(ctx, bookService, f.fixture)
+		fmt.Printf("%s: создано %d, пропущено %d\n", f.name, report.Created, report.Skipped)
+		if err != nil {
+			fatal("Ошибка заполнения", "dataset", f.name, "error", err)
+		}
+	}
+}
+
+type namedFixture struct {
+	name    string
+	fixture *seed.Fixture
+}
+
+// seedFixtures разбирает аргументы подкоманды: имена наборов
+// (load-test с необязательным числом книг) и пути к файлам
+func seedFixtures(args []string) ([]namedFixture, error) {
+	var result []namedFixture
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if filepath.Ext(arg) != "" {
+			fixture, err := seed.LoadFile(arg)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, namedFixture{name: arg, fixture: fixture})
+			continue
+		}
+
+		size := seed.DefaultLoadTestSize
+		if arg == seed.DatasetLoadTest && i+1 < len(args) {
+			if n, err := strconv.Atoi(args[i+1]); err == nil {
+				size = n
+				i++
+			}
+		}
+		fixture, err := seed.Dataset(arg, size)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, namedFixture{name: arg, fixture: fixture})
+	}
+	return result, nil
+}
@@ -0,0 +1,28 @@
+// Команда openapi собирает спецификацию OpenAPI 3 по аннотациям обработчиков.
+// Запускается через go generate ./internal/openapi
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/krawwwwy/book-library-api/internal/openapi/gen"
+)
+
+func main() {
+	root := flag.String("root", ".", "корень модуля")
+	out := flag.String("out", "internal/openapi/openapi.json", "файл спецификации относительно корня модуля")
+	flag.Parse()
+
+	if err := os.Chdir(*root); err != nil {
+		log.Fatalf("Ошибка перехода в корень модуля: %v", err)
+	}
+	spec, err := gen.Generate()
+	if err != nil {
+		log.Fatalf("Ошибка сборки спецификации: %v", err)
+	}
+	if err := os.WriteFile(*out, spec, 0o644); err != nil {
+		log.Fatalf("Ошибка записи спецификации: %v", err)
+	}
+}
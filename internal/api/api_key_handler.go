@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/service"
+)
+
+// APIKeyHandler представляет обработчик HTTP-запросов управления API-ключами
+type APIKeyHandler struct {
+	service *service.APIKeyService
+}
+
+// NewAPIKeyHandler создает новый экземпляр APIKeyHandler
+func NewAPIKeyHandler(service *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// RegisterRoutes регистрирует маршруты управления API-ключами
+func (h *APIKeyHandler) RegisterRoutes(router *gin.Engine) {
+	keys := router.Group("/api/admin/api-keys", middleware.RequirePermission(model.PermissionManageAPIKeys))
+	{
+		keys.GET("", h.GetKeys)
+		keys.POST("", h.CreateKey)
+		keys.DELETE("/:id", h.RevokeKey)
+	}
+}
+
+// GetKeys получает список API-ключей
+// @Summary Получение списка API-ключей
+// @Description Получает все API-ключи с префиксами, областями и временем последнего использования. Значения ключей не возвращаются.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.APIKey
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/api-keys [get]
+func (h *APIKeyHandler) GetKeys(c *gin.Context) {
+	keys, err := h.service.ListKeys(c.Request.Context())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateKey выпускает API-ключ
+// @Summary Выпуск API-ключа
+// @Description Выпускает API-ключ с указанными областями (например, books:read, books:write). Значение ключа возвращается только в этом ответе.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key body model.APIKeyCreate true "Название, области и срок действия ключа"
+// @Success 201 {object} model.APIKeyCreated
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/api-keys [post]
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	var keyCreate model.APIKeyCreate
+	if err := c.ShouldBindJSON(&keyCreate); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный формат данных"))
+		return
+	}
+
+	key, err := h.service.CreateKey(c.Request.Context(), &keyCreate)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// RevokeKey отзывает API-ключ
+// @Summary Отзыв API-ключа
+// @Description Отзывает API-ключ. Запросы с отозванным ключом отклоняются с 401.
+// @Tags admin
+// @Security BearerAuth
+// @Param id path int true "ID ключа"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
+		return
+	}
+
+	if err := h.service.RevokeKey(c.Request.Context(), uint(id)); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *APIKeyHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, middleware.ErrorBody(c, err.Error()))
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, middleware.ErrorBody(c, err.Error()))
+	case errors.Is(err, service.ErrInvalidScope), errors.Is(err, service.ErrInvalidExpiry):
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, err.Error()))
+	default:
+		internalError(c, err)
+	}
+}
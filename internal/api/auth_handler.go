@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/service"
+)
+
+// AuthHandler представляет обработчик HTTP-запросов аутентификации
+type AuthHandler struct {
+	service *service.AuthService
+}
+
+// NewAuthHandler создает новый экземпляр AuthHandler
+func NewAuthHandler(service *service.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// RegisterRoutes регистрирует маршруты аутентификации
+func (h *AuthHandler) RegisterRoutes(router *gin.Engine) {
+	auth := router.Group("/api/auth")
+	{
+		auth.POST("/login", h.Login)
+		auth.POST("/refresh", h.Refresh)
+		auth.POST("/logout", h.Logout)
+		auth.GET("/me", middleware.RequireAuth(), h.Me)
+	}
+}
+
+// Login выдает пару токенов по имени пользователя и паролю
+// @Summary Вход
+// @Description Проверяет имя пользователя и пароль и выдает access- и refresh-токены
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body model.LoginRequest true "Имя пользователя и пароль"
+// @Success 200 {object} model.TokenPair
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req model.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный формат данных"))
+		return
+	}
+
+	tokens, err := h.service.Login(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов
+// @Summary Обновление токенов
+// @Description Выдает новую пару токенов и отзывает предъявленный refresh-токен
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.RefreshRequest true "Refresh-токен"
+// @Success 200 {object} model.TokenPair
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req model.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный формат данных"))
+		return
+	}
+
+	tokens, err := h.service.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout отзывает refresh-токен
+// @Summary Выход
+// @Description Отзывает refresh-токен
+// @Tags auth
+// @Accept json
+// @Param request body model.RefreshRequest true "Refresh-токен"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req model.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный формат данных"))
+		return
+	}
+
+	if err := h.service.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Me возвращает текущего пользователя
+// @Summary Текущий пользователь
+// @Description Возвращает пользователя, которому выдан access-токен
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.Principal
+// @Failure 401 {object} map[string]string
+// @Router /api/auth/me [get]
+func (h *AuthHandler) Me(c *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(c)
+	c.JSON(http.StatusOK, principal)
+}
+
+func (h *AuthHandler) writeError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidCredentials) || errors.Is(err, service.ErrInvalidToken) {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, err.Error()))
+		return
+	}
+	internalError(c, err)
+}
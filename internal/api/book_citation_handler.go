@@ -0,0 +1,108 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/citation"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/service"
+)
+
+// maxCitationIDs ограничивает количество книг в одном запросе ссылок
+const maxCitationIDs = 500
+
+// CiteBook формирует библиографическую ссылку на книгу
+// @Summary Ссылка на книгу
+// @Description Формирует библиографическую ссылку на книгу в указанном формате
+// @Tags citations
+// @Produce plain
+// @Param id path int true "ID книги"
+// @Param format query string false "Формат ссылки" Enums(bibtex, ris, csl-json, apa, gost) default(bibtex)
+// @Success 200 {string} string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/books/{id}/cite [get]
+func (h *BookHandler) CiteBook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
+		return
+	}
+
+	book, err := h.service.GetBookByID(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrBookNotFound) {
+			c.JSON(http.StatusNotFound, middleware.ErrorBody(c, err.Error()))
+			return
+		}
+		internalError(c, err)
+		return
+	}
+
+	writeCitations(c, []model.Book{*book})
+}
+
+// CiteBooks формирует библиографические ссылки на несколько книг
+// @Summary Ссылки на список книг
+// @Description Формирует библиографические ссылки на книги с перечисленными ID в порядке их перечисления
+// @Tags citations
+// @Produce plain
+// @Param ids query string true "ID книг через запятую"
+// @Param format query string false "Формат ссылки" Enums(bibtex, ris, csl-json, apa, gost) default(bibtex)
+// @Success 200 {string} string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/books/cite [get]
+func (h *BookHandler) CiteBooks(c *gin.Context) {
+	var ids []uint
+	for _, part := range strings.Split(c.Query("ids"), ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
+			return
+		}
+		ids = append(ids, uint(id))
+	}
+	if len(ids) == 0 || len(ids) > maxCitationIDs {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "укажите от 1 до 500 ID книг в параметре ids"))
+		return
+	}
+
+	books, err := h.service.GetBooksByIDs(c.Request.Context(), ids)
+	if err != nil {
+		if errors.Is(err, service.ErrBookNotFound) {
+			c.JSON(http.StatusNotFound, middleware.ErrorBody(c, err.Error()))
+			return
+		}
+		internalError(c, err)
+		return
+	}
+
+	writeCitations(c, books)
+}
+
+// writeCitations отправляет ссылки в формате из параметра format
+func writeCitations(c *gin.Context, books []model.Book) {
+	format := c.DefaultQuery("format", citation.FormatBibTeX)
+	result, err := citation.Format(books, format)
+	if err != nil {
+		if errors.Is(err, citation.ErrUnknownFormat) {
+			c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, err.Error()))
+			return
+		}
+		internalError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, citation.ContentType(format), []byte(result))
+}
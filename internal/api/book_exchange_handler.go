@@ -0,0 +1,225 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/service"
+	"github.com/krawwwwy/book-library-api/pkg/marc"
+)
+
+// maxImportSize ограничивает размер загружаемого файла импорта
+const maxImportSize = 10 << 20
+
+// ExportBooks выгружает каталог книг
+// @Summary Выгрузка каталога
+// @Description Потоково выгружает все книги, удовлетворяющие фильтрам
+// @Tags exchange
+// @Produce text/csv
+// @Produce application/marc
+// @Produce application/marcxml+xml
+// @Param format query string false "Формат выгрузки" Enums(csv, marc, marcxml) default(csv)
+// @Param q query string false "Поиск по названию или автору"
+// @Param author query string false "Автор"
+// @Param publisher query string false "Издательство"
+// @Param year_from query int false "Год издания от"
+// @Param year_to query int false "Год издания до"
+// @Param available query bool false "Доступность"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Router /api/books/export [get]
+func (h *BookHandler) ExportBooks(c *gin.Context) {
+	filter, err := bookFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, err.Error()))
+		return
+	}
+
+	h.export(c, filter, "books")
+}
+
+// ExportBook выгружает одну книгу
+// @Summary Выгрузка книги
+// @Description Выгружает книгу в одном из форматов обмена
+// @Tags exchange
+// @Produce text/csv
+// @Produce application/marc
+// @Produce application/marcxml+xml
+// @Param id path int true "ID книги"
+// @Param format query string false "Формат выгрузки" Enums(csv, marc, marcxml) default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/books/{id}/export [get]
+func (h *BookHandler) ExportBook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
+		return
+	}
+
+	if _, err := h.service.GetBookByID(c.Request.Context(), uint(id)); err != nil {
+		if errors.Is(err, service.ErrBookNotFound) {
+			c.JSON(http.StatusNotFound, middleware.ErrorBody(c, err.Error()))
+			return
+		}
+		internalError(c, err)
+		return
+	}
+
+	h.export(c, &model.BookFilter{IDs: []uint{uint(id)}}, "book-"+c.Param("id"))
+}
+
+// export записывает книги, удовлетворяющие фильтру, в формате из параметра format
+func (h *BookHandler) export(c *gin.Context, filter *model.BookFilter, filename string) {
+	var contentType, ext string
+	var write func(w io.Writer) error
+
+	switch format := c.DefaultQuery("format", "csv"); format {
+	case "csv":
+		contentType, ext = "text/csv; charset=utf-8", "csv"
+		write = func(w io.Writer) error { return h.service.ExportCSV(c.Request.Context(), w, filter) }
+	case service.MARCFormatISO2709:
+		contentType, ext = "application/marc", "mrc"
+		write = func(w io.Writer) error { return h.service.ExportMARC(c.Request.Context(), w, filter, format) }
+	case service.MARCFormatXML:
+		contentType, ext = "application/marcxml+xml; charset=utf-8", "xml"
+		write = func(w io.Writer) error { return h.service.ExportMARC(c.Request.Context(), w, filter, format) }
+	default:
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неподдерживаемый формат выгрузки"))
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, ext))
+	c.Status(http.StatusOK)
+	if err := write(c.Writer); err != nil {
+		// Заголовки уже отправлены, поэтому ошибку можно только залогировать
+		_ = c.Error(err)
+	}
+}
+
+// ImportBooks загружает книги из файла
+// @Summary Импорт каталога
+// @Description Загружает книги из CSV или MARC21 (ISO 2709, MARCXML) с обновлением существующих по ISBN. Файл передается в поле file формы или телом запроса.
+// @Tags exchange
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Accept application/marc
+// @Accept application/marcxml+xml
+// @Produce json
+// @Param file formData file false "Файл импорта"
+// @Param format query string false "Формат файла" Enums(csv, marc, marcxml) default(csv)
+// @Param mapping query string false "Сопоставление полей книги и колонок в виде JSON, например {\"title\":\"Название\"}"
+// @Param delimiter query string false "Разделитель колонок" default(,)
+// @Param dry_run query bool false "Только проверить файл, не сохраняя изменения"
+// @Success 200 {object} model.ImportReport
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/books/import [post]
+func (h *BookHandler) ImportBooks(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный параметр dry_run"))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	body, err := importBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "не удалось прочитать файл импорта"))
+		return
+	}
+	defer body.Close()
+
+	var report *model.ImportReport
+	switch format := c.DefaultQuery("format", "csv"); format {
+	case service.MARCFormatISO2709, service.MARCFormatXML:
+		report, err = h.service.ImportMARC(c.Request.Context(), body, format, dryRun)
+	case "csv":
+		opts := service.CSVImportOptions{DryRun: dryRun}
+		if mapping := c.Query("mapping"); mapping != "" {
+			if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+				c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверное сопоставление колонок"))
+				return
+			}
+		}
+		if delimiter := c.Query("delimiter"); delimiter != "" {
+			r, size := utf8.DecodeRuneInString(delimiter)
+			if size != len(delimiter) {
+				c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "разделитель должен быть одним символом"))
+				return
+			}
+			opts.Delimiter = r
+		}
+		report, err = h.service.ImportCSV(c.Request.Context(), body, opts)
+	default:
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неподдерживаемый формат импорта"))
+		return
+	}
+
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCSV) || errors.Is(err, marc.ErrInvalidRecord) {
+			c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, err.Error()))
+			return
+		}
+		internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// importBody возвращает содержимое файла импорта из формы или тела запроса
+func importBody(c *gin.Context) (io.ReadCloser, error) {
+	if c.ContentType() == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		return header.Open()
+	}
+	return c.Request.Body, nil
+}
+
+// bookFilterFromQuery строит фильтр книг из параметров запроса
+func bookFilterFromQuery(c *gin.Context) (*model.BookFilter, error) {
+	filter := &model.BookFilter{
+		Query:     c.Query("q"),
+		Author:    c.Query("author"),
+		Publisher: c.Query("publisher"),
+	}
+
+	var err error
+	if v := c.Query("year_from"); v != "" {
+		if filter.YearFrom, err = strconv.Atoi(v); err != nil {
+			return nil, errors.New("неверный параметр year_from")
+		}
+	}
+	if v := c.Query("year_to"); v != "" {
+		if filter.YearTo, err = strconv.Atoi(v); err != nil {
+			return nil, errors.New("неверный параметр year_to")
+		}
+	}
+	if v := c.Query("available"); v != "" {
+		available, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.New("неверный параметр available")
+		}
+		filter.Available = &available
+	}
+
+	return filter, nil
+}
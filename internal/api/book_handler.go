@@ -1,10 +1,12 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
 	"github.com/krawwwwy/book-library-api/internal/model"
 	"github.com/krawwwwy/book-library-api/internal/service"
 )
@@ -23,15 +25,32 @@ func NewBookHandler(service *service.BookService) *BookHandler {
 // @Summary Регистрация маршрутов API для книг
 // @Description Регистрирует все доступные эндпоинты для работы с книгами
 func (h *BookHandler) RegisterRoutes(router *gin.Engine) {
+	// Каталог доступен для чтения без аутентификации
 	books := router.Group("/api/books")
 	{
-		books.POST("", h.CreateBook)
 		books.GET("", h.GetBooks)
 		books.GET("/:id", h.GetBook)
-		books.PUT("/:id", h.UpdateBook)
-		books.DELETE("/:id", h.DeleteBook)
 		books.GET("/search", h.SearchBooks)
-		books.POST("/:id/toggle-availability", h.ToggleAvailability)
+		books.GET("/export", h.ExportBooks)
+		books.GET("/cite", h.CiteBooks)
+		books.GET("/:id/export", h.ExportBook)
+		books.GET("/:id/cite", h.CiteBook)
+	}
+
+	// Изменение каталога доступно библиотекарям и администраторам
+	librarian := router.Group("/api/books", middleware.RequirePermission(model.PermissionWriteBooks))
+	{
+		librarian.POST("", h.CreateBook)
+		librarian.POST("/bulk", h.BulkBooks)
+		librarian.PUT("/:id", h.UpdateBook)
+		librarian.POST("/import", h.ImportBooks)
+		librarian.POST("/:id/toggle-availability", h.ToggleAvailability)
+	}
+
+	// Удаление книг доступно только администраторам
+	admin := router.Group("/api/books", middleware.RequirePermission(model.PermissionDeleteBooks))
+	{
+		admin.DELETE("/:id", h.DeleteBook)
 	}
 }
 
@@ -45,23 +64,71 @@ func (h *BookHandler) RegisterRoutes(router *gin.Engine) {
 // @Success 201 {object} model.Book
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Router /api/books [post]
 func (h *BookHandler) CreateBook(c *gin.Context) {
 	var bookCreate model.BookCreate
 	if err := c.ShouldBindJSON(&bookCreate); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "неверный формат данных"})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный формат данных"))
 		return
 	}
 
-	book, err := h.service.CreateBook(&bookCreate)
+	book, err := h.service.CreateBook(c.Request.Context(), &bookCreate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		internalError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, book)
 }
 
+// BulkBooks выполняет пакет операций над книгами
+// @Summary Пакетное создание, обновление и удаление книг
+// @Description Выполняет массив операций в одной транзакции (mode=transaction) или независимо (mode=best_effort) и возвращает результат по каждой операции
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param request body model.BulkRequest true "Пакет операций"
+// @Success 200 {object} model.BulkResult
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} model.BulkResult
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/books/bulk [post]
+func (h *BookHandler) BulkBooks(c *gin.Context) {
+	var req model.BulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный формат данных"))
+		return
+	}
+
+	result, err := h.service.BulkApply(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyOperations) || errors.Is(err, service.ErrUnknownBulkMode) {
+			c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, err.Error()))
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, middleware.ErrorBody(c, err.Error()))
+			return
+		}
+		internalError(c, err)
+		return
+	}
+
+	// Откаченная транзакция не изменила данные
+	if result.Mode == model.BulkModeTransaction && result.Failed > 0 {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // GetBooks получает список всех книг
 // @Summary Получение списка книг
 // @Description Получает список всех книг с пагинацией
@@ -70,18 +137,23 @@ func (h *BookHandler) CreateBook(c *gin.Context) {
 // @Param page query int false "Номер страницы" default(1)
 // @Param page_size query int false "Размер страницы" default(10)
 // @Success 200 {array} model.Book
+// @Header 200 {string} Cache-Control "public, no-cache"
+// @Header 200 {string} Last-Modified "Время последнего изменения книг на странице"
 // @Failure 500 {object} map[string]string
 // @Router /api/books [get]
 func (h *BookHandler) GetBooks(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 
-	books, err := h.service.GetAllBooks(page, pageSize)
+	books, err := h.service.GetAllBooks(c.Request.Context(), page, pageSize)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		internalError(c, err)
 		return
 	}
 
+	// Удаление книги не меняет время изменения остальных, поэтому на
+	// If-Modified-Since для списка 304 не отдается
+	setCatalogCacheHeaders(c, lastModified(books))
 	c.JSON(http.StatusOK, books)
 }
 
@@ -91,23 +163,37 @@ func (h *BookHandler) GetBooks(c *gin.Context) {
 // @Tags books
 // @Produce json
 // @Param id path int true "ID книги"
+// @Param If-Modified-Since header string false "Время из Last-Modified полученного ранее ответа"
 // @Success 200 {object} model.Book
+// @Header 200 {string} Cache-Control "public, no-cache"
+// @Header 200 {string} Last-Modified "Время последнего изменения книги"
+// @Success 304 "Книга не изменилась"
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
 // @Router /api/books/{id} [get]
 func (h *BookHandler) GetBook(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "неверный ID"})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
 		return
 	}
 
-	book, err := h.service.GetBookByID(uint(id))
+	book, err := h.service.GetBookByID(c.Request.Context(), uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "книга не найдена"})
+		if errors.Is(err, service.ErrBookNotFound) {
+			c.JSON(http.StatusNotFound, middleware.ErrorBody(c, err.Error()))
+			return
+		}
+		internalError(c, err)
 		return
 	}
 
+	setCatalogCacheHeaders(c, book.UpdatedAt)
+	if notModified(c, book.UpdatedAt) {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	c.JSON(http.StatusOK, book)
 }
 
@@ -122,23 +208,26 @@ func (h *BookHandler) GetBook(c *gin.Context) {
 // @Success 200 {object} model.Book
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Router /api/books/{id} [put]
 func (h *BookHandler) UpdateBook(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "неверный ID"})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
 		return
 	}
 
 	var bookUpdate model.BookCreate
 	if err := c.ShouldBindJSON(&bookUpdate); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "неверный формат данных"})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный формат данных"))
 		return
 	}
 
-	book, err := h.service.UpdateBook(uint(id), &bookUpdate)
+	book, err := h.service.UpdateBook(c.Request.Context(), uint(id), &bookUpdate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		internalError(c, err)
 		return
 	}
 
@@ -154,16 +243,19 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 // @Success 204 "No Content"
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Router /api/books/{id} [delete]
 func (h *BookHandler) DeleteBook(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "неверный ID"})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
 		return
 	}
 
-	if err := h.service.DeleteBook(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.DeleteBook(c.Request.Context(), uint(id)); err != nil {
+		internalError(c, err)
 		return
 	}
 
@@ -177,22 +269,25 @@ func (h *BookHandler) DeleteBook(c *gin.Context) {
 // @Produce json
 // @Param q query string true "Поисковый запрос"
 // @Success 200 {array} model.Book
+// @Header 200 {string} Cache-Control "public, no-cache"
+// @Header 200 {string} Last-Modified "Время последнего изменения найденных книг"
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/books/search [get]
 func (h *BookHandler) SearchBooks(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "параметр поиска не указан"})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "параметр поиска не указан"))
 		return
 	}
 
-	books, err := h.service.SearchBooks(query)
+	books, err := h.service.SearchBooks(c.Request.Context(), query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		internalError(c, err)
 		return
 	}
 
+	setCatalogCacheHeaders(c, lastModified(books))
 	c.JSON(http.StatusOK, books)
 }
 
@@ -205,17 +300,20 @@ func (h *BookHandler) SearchBooks(c *gin.Context) {
 // @Success 200 {object} model.Book
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Router /api/books/{id}/toggle-availability [post]
 func (h *BookHandler) ToggleAvailability(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "неверный ID"})
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
 		return
 	}
 
-	book, err := h.service.ToggleBookAvailability(uint(id))
+	book, err := h.service.ToggleBookAvailability(c.Request.Context(), uint(id))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		internalError(c, err)
 		return
 	}
 
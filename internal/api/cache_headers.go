@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// catalogCacheControl разрешает клиентам и прокси хранить ответы каталога, но требует
+// перед каждым использованием проверять их актуальность запросом с If-Modified-Since
+const catalogCacheControl = "public, no-cache"
+
+// setCatalogCacheHeaders задает заголовки кэширования ответа каталога.
+// Last-Modified - время последнего изменения книг в ответе.
+func setCatalogCacheHeaders(c *gin.Context, modified time.Time) {
+	c.Header("Cache-Control", catalogCacheControl)
+	if !modified.IsZero() {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified проверяет, что книга не менялась с момента из заголовка If-Modified-Since.
+// Заголовок передается с точностью до секунды, поэтому доли секунды не учитываются.
+func notModified(c *gin.Context, modified time.Time) bool {
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// lastModified возвращает время последнего изменения книг списка
+func lastModified(books []model.Book) time.Time {
+	var latest time.Time
+	for _, book := range books {
+		if book.UpdatedAt.After(latest) {
+			latest = book.UpdatedAt
+		}
+	}
+	return latest
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"github.com/krawwwwy/book-library-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookCacheHeaders(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	books := service.NewBookService(repository.NewMemoryBookStore())
+	admin := model.ContextWithPrincipal(context.Background(), &model.Principal{Role: model.RoleAdmin})
+	book, err := books.CreateBook(admin, &model.BookCreate{Title: "Мы", Author: "Евгений Замятин", ISBN: "9785170900011", Year: 1920})
+	require.NoError(t, err)
+	router := gin.New()
+	NewBookHandler(books).RegisterRoutes(router)
+	modified := book.UpdatedAt.UTC().Format(http.TimeFormat)
+
+	testCases := []struct {
+		name            string
+		path            string
+		ifModifiedSince string
+		expectedStatus  int
+	}{
+		{name: "Книга без условия", path: "/api/books/1", expectedStatus: http.StatusOK},
+		{name: "Книга не изменилась", path: "/api/books/1", ifModifiedSince: modified, expectedStatus: http.StatusNotModified},
+		{name: "Книга изменилась", path: "/api/books/1", ifModifiedSince: book.UpdatedAt.Add(-time.Hour).UTC().Format(http.TimeFormat), expectedStatus: http.StatusOK},
+		{name: "Неверная дата в условии", path: "/api/books/1", ifModifiedSince: "вчера", expectedStatus: http.StatusOK},
+		{name: "Список не отвечает 304", path: "/api/books", ifModifiedSince: modified, expectedStatus: http.StatusOK},
+		{name: "Поиск", path: "/api/books/search?q=Замятин", expectedStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.ifModifiedSince != "" {
+				req.Header.Set("If-Modified-Since", tc.ifModifiedSince)
+			}
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, catalogCacheControl, w.Header().Get("Cache-Control"))
+			assert.Equal(t, modified, w.Header().Get("Last-Modified"))
+			if tc.expectedStatus == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
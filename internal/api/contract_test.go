@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/logging"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/openapi"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"github.com/krawwwwy/book-library-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// adminVerifier принимает любой токен как токен администратора
+type adminVerifier struct{}
+
+func (adminVerifier) VerifyAccessToken(context.Context, string) (*model.Principal, error) {
+	return &model.Principal{UserID: 1, Username: "admin", Role: model.RoleAdmin}, nil
+}
+
+func (adminVerifier) VerifyAPIKey(context.Context, string) (*model.Principal, error) {
+	return &model.Principal{UserID: 1, Username: "admin", Role: model.RoleAdmin}, nil
+}
+
+// TestBookRoutesMatchSpec проверяет, что запросы по спецификации принимаются,
+// а ответы обработчиков каталога соответствуют спецификации
+func TestBookRoutesMatchSpec(t *testing.T) {
+	// Arrange
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	logger := logging.New(config.LogConfig{Level: config.LogLevelInfo, Format: config.LogFormatJSON}, &buf)
+
+	store := repository.NewMemoryBookStore()
+	books := service.NewBookService(store)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(
+		middleware.Authenticate(adminVerifier{}, adminVerifier{}),
+		middleware.OpenAPIValidator(doc, middleware.OpenAPIValidatorOptions{Requests: true, Responses: true}, logger),
+	)
+	NewBookHandler(books).RegisterRoutes(router)
+	NewRevisionHandler(service.NewRevisionService(store, books)).RegisterRoutes(router)
+
+	book := `{"title":"Мастер и Маргарита","author":"Михаил Булгаков","isbn":"9785170987654","year":1967,"publisher":"Посев"}`
+	steps := []struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{method: http.MethodGet, path: "/api/books/1/revisions", expectedStatus: http.StatusNotFound},
+		{method: http.MethodPost, path: "/api/books", body: book, expectedStatus: http.StatusCreated},
+		{method: http.MethodGet, path: "/api/books?limit=10&offset=0", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/api/books/1", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/api/books/2", expectedStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: "/api/books/search?q=Булгаков", expectedStatus: http.StatusOK},
+		{method: http.MethodPut, path: "/api/books/1", body: strings.Replace(book, "1967", "1966", 1), expectedStatus: http.StatusOK},
+		{method: http.MethodPost, path: "/api/books/1/toggle-availability", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/api/books/1/revisions", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/api/books/1/revisions/1", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/api/books/1/revisions/diff?from=1&to=2", expectedStatus: http.StatusOK},
+		{method: http.MethodPost, path: "/api/books/1/revert?to=1", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/api/books/1/cite?style=bibtex", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/api/books/export?format=csv", expectedStatus: http.StatusOK},
+		{method: http.MethodPost, path: "/api/books/bulk", body: `{"operations":[{"op":"create","book":{"title":"Бег","author":"Михаил Булгаков","isbn":"9785170900004","year":1937}}]}`, expectedStatus: http.StatusOK},
+		{method: http.MethodDelete, path: "/api/books/1", expectedStatus: http.StatusNoContent},
+	}
+
+	for _, step := range steps {
+		// Act
+		req := httptest.NewRequest(step.method, step.path, strings.NewReader(step.body))
+		req.Header.Set("Authorization", "Bearer admin")
+		if step.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, step.expectedStatus, w.Code, "%s %s: %s", step.method, step.path, w.Body.String())
+	}
+	assert.Empty(t, buf.String(), "ответы соответствуют спецификации")
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/openapi"
+)
+
+// DocsHandler представляет обработчик спецификации OpenAPI и Swagger UI
+type DocsHandler struct {
+	ui http.Handler
+}
+
+// NewDocsHandler создает новый экземпляр DocsHandler
+func NewDocsHandler() *DocsHandler {
+	return &DocsHandler{ui: openapi.UI("/docs")}
+}
+
+// RegisterRoutes регистрирует /openapi.json и Swagger UI на /docs
+func (h *DocsHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/openapi.json", h.GetSpec)
+	router.GET("/docs", func(c *gin.Context) { c.Redirect(http.StatusMovedPermanently, "/docs/") })
+	router.GET("/docs/*filepath", gin.WrapH(h.ui))
+}
+
+// GetSpec возвращает спецификацию OpenAPI 3
+func (h *DocsHandler) GetSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", openapi.Spec())
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/database"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
+	"github.com/krawwwwy/book-library-api/internal/service"
+)
+
+// statusClientClosedRequest сообщает в журнале, что клиент отключился, не дождавшись ответа
+const statusClientClosedRequest = 499
+
+// errorStatus возвращает код ответа для непредвиденной ошибки сервиса
+func errorStatus(err error) int {
+	switch {
+	case database.IsTimeout(err):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// internalError отвечает на непредвиденную ошибку сервиса. Истечение срока
+// обработки запроса отдается как 504, а отказ сервиса в правах - как 403.
+func internalError(c *gin.Context, err error) {
+	status := errorStatus(err)
+	if status == http.StatusGatewayTimeout {
+		c.JSON(status, middleware.ErrorBody(c, "превышено время обработки запроса"))
+		return
+	}
+	c.JSON(status, middleware.ErrorBody(c, err.Error()))
+}
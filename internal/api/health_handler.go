@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/health"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// HealthHandler представляет обработчик проб живости и готовности
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+// NewHealthHandler создает новый экземпляр HealthHandler
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// RegisterProbes регистрирует пробы /healthz и /readyz. Пробы не требуют
+// авторизации, поэтому регистрируются до middleware аутентификации и лимитов.
+func (h *HealthHandler) RegisterProbes(router *gin.Engine) {
+	router.GET("/healthz", h.Live)
+	router.GET("/readyz", h.Ready)
+}
+
+// RegisterRoutes регистрирует маршрут подробного состояния сервиса
+func (h *HealthHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/api/admin/status", middleware.RequirePermission(model.PermissionViewSystem), h.GetStatus)
+}
+
+// Live сообщает, что процесс работает
+// @Summary Проба живости
+// @Description Отвечает 200, пока процесс обрабатывает запросы. Зависимости не проверяются.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Ready сообщает, готов ли сервис принимать запросы
+// @Summary Проба готовности
+// @Description Проверяет соединение с базой данных и применение миграций. Во время остановки сервиса отвечает 503.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.checker.Check(c.Request.Context())
+	// Текст ошибок доступен только в /api/admin/status: он может раскрывать адреса зависимостей
+	for name, dep := range report.Dependencies {
+		dep.Error = ""
+		report.Dependencies[name] = dep
+	}
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// GetStatus возвращает подробное состояние сервиса
+// @Summary Состояние сервиса
+// @Description Возвращает версию, сведения о сборке, время работы и состояние зависимостей с текстом ошибок
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} health.Status
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/admin/status [get]
+func (h *HealthHandler) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.checker.Status(c.Request.Context()))
+}
@@ -0,0 +1,302 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/oai"
+	"github.com/krawwwwy/book-library-api/internal/service"
+)
+
+// oaiPageSize задает количество записей на странице ListRecords и ListIdentifiers
+const oaiPageSize = 100
+
+// oaiVerbArguments перечисляет допустимые аргументы каждого глагола OAI-PMH
+var oaiVerbArguments = map[string]map[string]bool{
+	"Identify":            {},
+	"ListMetadataFormats": {"identifier": true},
+	"ListSets":            {"resumptionToken": true},
+	"GetRecord":           {"identifier": true, "metadataPrefix": true},
+	"ListIdentifiers":     {"metadataPrefix": true, "from": true, "until": true, "set": true, "resumptionToken": true},
+	"ListRecords":         {"metadataPrefix": true, "from": true, "until": true, "set": true, "resumptionToken": true},
+}
+
+// OAIHandler представляет провайдер данных OAI-PMH 2.0 для сборщиков сводных каталогов
+type OAIHandler struct {
+	service *service.BookService
+	cfg     config.OAIConfig
+}
+
+// NewOAIHandler создает новый экземпляр OAIHandler
+func NewOAIHandler(service *service.BookService, cfg config.OAIConfig) *OAIHandler {
+	return &OAIHandler{service: service, cfg: cfg}
+}
+
+// RegisterRoutes регистрирует маршрут провайдера OAI-PMH
+func (h *OAIHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/oai", h.Handle)
+	router.POST("/oai", h.HandleForm)
+}
+
+// Handle обрабатывает запрос OAI-PMH
+// @Summary Провайдер OAI-PMH
+// @Description Отдает записи каталога в формате oai_dc по протоколу OAI-PMH 2.0 (Identify, ListMetadataFormats, ListSets, GetRecord, ListIdentifiers, ListRecords)
+// @Tags oai
+// @Produce xml
+// @Param verb query string true "Глагол OAI-PMH"
+// @Param identifier query string false "Идентификатор записи"
+// @Param metadataPrefix query string false "Формат метаданных" Enums(oai_dc)
+// @Param from query string false "Нижняя граница даты изменения"
+// @Param until query string false "Верхняя граница даты изменения"
+// @Param resumptionToken query string false "Маркер продолжения выборки"
+// @Success 200 {object} oai.Response
+// @Router /oai [get]
+func (h *OAIHandler) Handle(c *gin.Context) {
+	h.handle(c)
+}
+
+// HandleForm обрабатывает запрос OAI-PMH с аргументами в теле формы
+// @Summary Провайдер OAI-PMH (POST)
+// @Description То же, что GET /oai, но аргументы передаются в теле запроса
+// @Tags oai
+// @Accept x-www-form-urlencoded
+// @Produce xml
+// @Param verb formData string true "Глагол OAI-PMH"
+// @Param identifier formData string false "Идентификатор записи"
+// @Param metadataPrefix formData string false "Формат метаданных" Enums(oai_dc)
+// @Param from formData string false "Нижняя граница даты изменения"
+// @Param until formData string false "Верхняя граница даты изменения"
+// @Param resumptionToken formData string false "Маркер продолжения выборки"
+// @Success 200 {object} oai.Response
+// @Router /oai [post]
+func (h *OAIHandler) HandleForm(c *gin.Context) {
+	h.handle(c)
+}
+
+// handle разбирает аргументы из строки запроса или тела формы и выполняет глагол
+func (h *OAIHandler) handle(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		h.write(c, oai.NewResponse(oai.Request{BaseURL: h.cfg.BaseURL}, time.Now()), oai.ErrBadArgument, "неверные параметры запроса")
+		return
+	}
+	args := c.Request.Form
+	verb := args.Get("verb")
+
+	allowed, ok := oaiVerbArguments[verb]
+	if !ok || len(args["verb"]) != 1 {
+		h.write(c, oai.NewResponse(oai.Request{BaseURL: h.cfg.BaseURL}, time.Now()), oai.ErrBadVerb, "неизвестный или повторяющийся глагол")
+		return
+	}
+
+	// Ответы с badArgument не повторяют аргументы запроса
+	for name, values := range args {
+		if name != "verb" && (!allowed[name] || len(values) != 1) {
+			h.write(c, oai.NewResponse(oai.Request{BaseURL: h.cfg.BaseURL}, time.Now()), oai.ErrBadArgument, "недопустимый или повторяющийся аргумент "+name)
+			return
+		}
+	}
+
+	resp := oai.NewResponse(oai.Request{
+		BaseURL:         h.cfg.BaseURL,
+		Verb:            verb,
+		Identifier:      args.Get("identifier"),
+		MetadataPrefix:  args.Get("metadataPrefix"),
+		From:            args.Get("from"),
+		Until:           args.Get("until"),
+		Set:             args.Get("set"),
+		ResumptionToken: args.Get("resumptionToken"),
+	}, time.Now())
+
+	switch verb {
+	case "Identify":
+		h.identify(c, resp)
+	case "ListMetadataFormats":
+		h.listMetadataFormats(c, resp, args)
+	case "ListSets":
+		h.write(c, resp, oai.ErrNoSetHierarchy, "репозиторий не поддерживает наборы")
+	case "GetRecord":
+		h.getRecord(c, resp, args)
+	default:
+		h.list(c, resp, args, verb == "ListRecords")
+	}
+}
+
+func (h *OAIHandler) identify(c *gin.Context, resp *oai.Response) {
+	earliest, err := h.service.EarliestUpdate(c.Request.Context())
+	if err != nil {
+		c.XML(errorStatus(err), middleware.ErrorBody(c, err.Error()))
+		return
+	}
+	if earliest.IsZero() {
+		earliest = time.Now()
+	}
+
+	resp.Identify = &oai.Identify{
+		RepositoryName:    h.cfg.RepositoryName,
+		BaseURL:           h.cfg.BaseURL,
+		ProtocolVersion:   "2.0",
+		AdminEmail:        h.cfg.AdminEmail,
+		EarliestDatestamp: oai.FormatDatestamp(earliest),
+		DeletedRecord:     "no",
+		Granularity:       oai.Granularity,
+	}
+	h.write(c, resp, "", "")
+}
+
+func (h *OAIHandler) listMetadataFormats(c *gin.Context, resp *oai.Response, args url.Values) {
+	if identifier := args.Get("identifier"); identifier != "" {
+		if _, ok := h.findBook(c.Request.Context(), identifier); !ok {
+			h.write(c, resp, oai.ErrIDDoesNotExist, "запись не найдена")
+			return
+		}
+	}
+
+	resp.ListMetadataFormats = &oai.ListMetadataFormats{
+		Formats: []oai.MetadataFormat{{
+			Prefix:    oai.MetadataPrefixDC,
+			Schema:    oai.OAIDCSchema,
+			Namespace: oai.OAIDCNamespace,
+		}},
+	}
+	h.write(c, resp, "", "")
+}
+
+func (h *OAIHandler) getRecord(c *gin.Context, resp *oai.Response, args url.Values) {
+	identifier, prefix := args.Get("identifier"), args.Get("metadataPrefix")
+	if identifier == "" || prefix == "" {
+		h.write(c, resp, oai.ErrBadArgument, "не указаны identifier и metadataPrefix")
+		return
+	}
+	if prefix != oai.MetadataPrefixDC {
+		h.write(c, resp, oai.ErrCannotDisseminateFormat, "неподдерживаемый формат метаданных")
+		return
+	}
+
+	book, ok := h.findBook(c.Request.Context(), identifier)
+	if !ok {
+		h.write(c, resp, oai.ErrIDDoesNotExist, "запись не найдена")
+		return
+	}
+
+	resp.GetRecord = &oai.GetRecord{Record: oai.NewRecord(h.cfg.RepositoryIdentifier, book)}
+	h.write(c, resp, "", "")
+}
+
+func (h *OAIHandler) list(c *gin.Context, resp *oai.Response, args url.Values, withMetadata bool) {
+	var token *oai.Token
+	if value := args.Get("resumptionToken"); value != "" {
+		if len(args) > 2 {
+			h.write(c, resp, oai.ErrBadArgument, "resumptionToken нельзя сочетать с другими аргументами")
+			return
+		}
+		var err error
+		if token, err = oai.DecodeToken(value); err != nil {
+			h.write(c, resp, oai.ErrBadResumptionToken, "неверный маркер продолжения")
+			return
+		}
+	} else {
+		prefix := args.Get("metadataPrefix")
+		if prefix == "" {
+			h.write(c, resp, oai.ErrBadArgument, "не указан metadataPrefix")
+			return
+		}
+		if prefix != oai.MetadataPrefixDC {
+			h.write(c, resp, oai.ErrCannotDisseminateFormat, "неподдерживаемый формат метаданных")
+			return
+		}
+		if args.Get("set") != "" {
+			h.write(c, resp, oai.ErrNoSetHierarchy, "репозиторий не поддерживает наборы")
+			return
+		}
+		dateRange, err := oai.ParseRange(args.Get("from"), args.Get("until"))
+		if err != nil {
+			h.write(c, resp, oai.ErrBadArgument, err.Error())
+			return
+		}
+		token = &oai.Token{MetadataPrefix: prefix, From: dateRange.From, Until: dateRange.Until}
+	}
+
+	dateRange := token.Range()
+	filter := &model.BookFilter{UpdatedFrom: dateRange.From, UpdatedUntil: dateRange.Until}
+	books, err := h.service.ListChangedBooks(c.Request.Context(), filter, token.Cursor.UpdatedAt, token.Cursor.ID, oaiPageSize)
+	if err != nil {
+		c.XML(errorStatus(err), middleware.ErrorBody(c, err.Error()))
+		return
+	}
+	if len(books) == 0 {
+		if token.Cursor.ID != 0 {
+			// Выборка могла сократиться после выдачи маркера
+			h.write(c, resp, oai.ErrBadResumptionToken, "маркер продолжения больше не действителен")
+			return
+		}
+		h.write(c, resp, oai.ErrNoRecordsMatch, "нет записей, удовлетворяющих запросу")
+		return
+	}
+
+	total, err := h.service.CountBooks(c.Request.Context(), filter)
+	if err != nil {
+		c.XML(errorStatus(err), middleware.ErrorBody(c, err.Error()))
+		return
+	}
+
+	var resumption *oai.ResumptionToken
+	offset := token.Cursor.Offset
+	if offset > 0 || int64(offset+len(books)) < total {
+		resumption = &oai.ResumptionToken{CompleteListSize: total, Cursor: offset}
+		if int64(offset+len(books)) < total {
+			last := books[len(books)-1]
+			next := *token
+			next.Cursor = oai.Cursor{UpdatedAt: last.UpdatedAt, ID: last.ID, Offset: offset + len(books)}
+			resumption.Value = next.Encode()
+		}
+	}
+
+	if withMetadata {
+		list := &oai.ListRecords{ResumptionToken: resumption}
+		for i := range books {
+			list.Records = append(list.Records, oai.NewRecord(h.cfg.RepositoryIdentifier, &books[i]))
+		}
+		resp.ListRecords = list
+	} else {
+		list := &oai.ListIdentifiers{ResumptionToken: resumption}
+		for i := range books {
+			list.Headers = append(list.Headers, oai.NewHeader(h.cfg.RepositoryIdentifier, &books[i]))
+		}
+		resp.ListIdentifiers = list
+	}
+	h.write(c, resp, "", "")
+}
+
+// findBook находит книгу по идентификатору OAI
+func (h *OAIHandler) findBook(ctx context.Context, identifier string) (*model.Book, bool) {
+	id, ok := oai.ParseIdentifier(h.cfg.RepositoryIdentifier, identifier)
+	if !ok {
+		return nil, false
+	}
+	book, err := h.service.GetBookByID(ctx, id)
+	if err != nil {
+		return nil, false
+	}
+	return book, true
+}
+
+// write отправляет ответ OAI-PMH. Ошибки протокола передаются со статусом 200, как требует спецификация.
+func (h *OAIHandler) write(c *gin.Context, resp *oai.Response, code, message string) {
+	if code != "" {
+		resp.Errors = append(resp.Errors, oai.Error{Code: code, Message: message})
+	}
+
+	body, err := xml.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		c.XML(errorStatus(err), middleware.ErrorBody(c, err.Error()))
+		return
+	}
+	c.Data(http.StatusOK, "text/xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
+	"github.com/krawwwwy/book-library-api/internal/service"
+	"github.com/krawwwwy/book-library-api/internal/sso"
+)
+
+// oidcStateCookie хранит state начатого входа, чтобы callback принимался
+// только в том браузере, в котором вход был начат
+const oidcStateCookie = "oidc_state"
+
+// OIDCHandler представляет обработчик входа через провайдер OpenID Connect
+type OIDCHandler struct {
+	client *sso.Client
+	auth   *service.AuthService
+}
+
+// NewOIDCHandler создает новый экземпляр OIDCHandler
+func NewOIDCHandler(client *sso.Client, auth *service.AuthService) *OIDCHandler {
+	return &OIDCHandler{client: client, auth: auth}
+}
+
+// RegisterRoutes регистрирует маршруты входа через OIDC
+func (h *OIDCHandler) RegisterRoutes(router *gin.Engine) {
+	oidc := router.Group("/api/auth/oidc")
+	{
+		oidc.GET("/login", h.Login)
+		oidc.GET("/callback", h.Callback)
+	}
+}
+
+// Login перенаправляет пользователя на страницу входа провайдера
+// @Summary Вход через SSO
+// @Description Начинает вход через провайдер OpenID Connect (authorization code с PKCE) и перенаправляет на страницу входа провайдера
+// @Tags auth
+// @Success 302 "Переход к провайдеру"
+// @Failure 503 {object} map[string]string
+// @Router /api/auth/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authURL, state, err := h.client.AuthCodeURL()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, middleware.ErrorBody(c, err.Error()))
+		return
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, 600, "/api/auth/oidc", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback завершает вход через провайдер и выдает пару токенов
+// @Summary Завершение входа через SSO
+// @Description Принимает код авторизации от провайдера, проверяет ID-токен и выдает access- и refresh-токены
+// @Tags auth
+// @Produce json
+// @Param code query string true "Код авторизации"
+// @Param state query string true "Значение state"
+// @Success 200 {object} model.TokenPair
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if errCode := c.Query("error"); errCode != "" {
+		body := middleware.ErrorBody(c, "провайдер отклонил вход: "+errCode)
+		body["description"] = c.Query("error_description")
+		c.JSON(http.StatusUnauthorized, body)
+		return
+	}
+
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "не указаны code и state"))
+		return
+	}
+
+	cookie, err := c.Cookie(oidcStateCookie)
+	c.SetCookie(oidcStateCookie, "", -1, "/api/auth/oidc", "", c.Request.TLS != nil, true)
+	if err != nil || cookie != state {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, sso.ErrUnknownState.Error()))
+		return
+	}
+
+	identity, err := h.client.Exchange(c.Request.Context(), state, code)
+	if err != nil {
+		if errors.Is(err, sso.ErrUnknownState) {
+			c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, err.Error()))
+			return
+		}
+		c.JSON(http.StatusUnauthorized, middleware.ErrorBody(c, err.Error()))
+		return
+	}
+
+	tokens, err := h.auth.LoginExternal(c.Request.Context(), identity)
+	if err != nil {
+		if errors.Is(err, service.ErrDuplicateUsername) {
+			c.JSON(http.StatusConflict, middleware.ErrorBody(c, err.Error()))
+			return
+		}
+		internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
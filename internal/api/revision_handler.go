@@ -0,0 +1,190 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/service"
+)
+
+// RevisionHandler представляет обработчик HTTP-запросов для истории изменений книг
+type RevisionHandler struct {
+	service *service.RevisionService
+}
+
+// NewRevisionHandler создает новый экземпляр RevisionHandler
+func NewRevisionHandler(service *service.RevisionService) *RevisionHandler {
+	return &RevisionHandler{service: service}
+}
+
+// RegisterRoutes регистрирует маршруты для истории изменений книг
+func (h *RevisionHandler) RegisterRoutes(router *gin.Engine) {
+	books := router.Group("/api/books")
+	{
+		books.GET("/:id/revisions", h.GetRevisions)
+		books.GET("/:id/revisions/diff", h.DiffRevisions)
+		books.GET("/:id/revisions/:rev", h.GetRevision)
+		books.POST("/:id/revert", middleware.RequirePermission(model.PermissionWriteBooks), h.RevertBook)
+	}
+}
+
+// GetRevisions получает историю изменений книги
+// @Summary Получение истории изменений книги
+// @Description Получает все сохраненные ревизии книги в порядке возрастания номера
+// @Tags revisions
+// @Produce json
+// @Param id path int true "ID книги"
+// @Success 200 {array} model.BookRevision
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/books/{id}/revisions [get]
+func (h *RevisionHandler) GetRevisions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
+		return
+	}
+
+	revisions, err := h.service.GetRevisions(c.Request.Context(), uint(id))
+	if errors.Is(err, service.ErrBookNotFound) {
+		c.JSON(http.StatusNotFound, middleware.ErrorBody(c, err.Error()))
+		return
+	}
+	if err != nil {
+		internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetRevision получает состояние книги на момент ревизии
+// @Summary Получение ревизии книги
+// @Description Получает снимок книги на момент указанной ревизии
+// @Tags revisions
+// @Produce json
+// @Param id path int true "ID книги"
+// @Param rev path int true "Номер ревизии"
+// @Success 200 {object} model.BookRevision
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/books/{id}/revisions/{rev} [get]
+func (h *RevisionHandler) GetRevision(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
+		return
+	}
+
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный номер ревизии"))
+		return
+	}
+
+	revision, err := h.service.GetRevision(c.Request.Context(), uint(id), rev)
+	if err != nil {
+		if errors.Is(err, service.ErrRevisionNotFound) {
+			c.JSON(http.StatusNotFound, middleware.ErrorBody(c, err.Error()))
+			return
+		}
+		internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions сравнивает две ревизии книги
+// @Summary Сравнение ревизий книги
+// @Description Возвращает список полей, изменившихся между двумя ревизиями
+// @Tags revisions
+// @Produce json
+// @Param id path int true "ID книги"
+// @Param from query int true "Номер исходной ревизии"
+// @Param to query int true "Номер конечной ревизии"
+// @Success 200 {object} model.RevisionDiff
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/books/{id}/revisions/diff [get]
+func (h *RevisionHandler) DiffRevisions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
+		return
+	}
+
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный номер ревизии"))
+		return
+	}
+
+	diff, err := h.service.DiffRevisions(c.Request.Context(), uint(id), from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrRevisionNotFound) {
+			c.JSON(http.StatusNotFound, middleware.ErrorBody(c, err.Error()))
+			return
+		}
+		internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RevertBook возвращает книгу к состоянию указанной ревизии
+// @Summary Откат книги к ревизии
+// @Description Восстанавливает данные книги из указанной ревизии, создавая новую ревизию
+// @Tags revisions
+// @Produce json
+// @Param id path int true "ID книги"
+// @Param to query int true "Номер ревизии"
+// @Success 200 {object} model.Book
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/books/{id}/revert [post]
+func (h *RevisionHandler) RevertBook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
+		return
+	}
+
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный номер ревизии"))
+		return
+	}
+
+	book, err := h.service.RevertBook(c.Request.Context(), uint(id), to)
+	switch {
+	case errors.Is(err, service.ErrRevisionNotFound), errors.Is(err, service.ErrBookNotFound):
+		c.JSON(http.StatusNotFound, middleware.ErrorBody(c, err.Error()))
+		return
+	case errors.Is(err, service.ErrDuplicateISBN):
+		c.JSON(http.StatusConflict, middleware.ErrorBody(c, err.Error()))
+		return
+	case errors.Is(err, service.ErrInvalidBook):
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, err.Error()))
+		return
+	case err != nil:
+		internalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
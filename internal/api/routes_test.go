@@ -0,0 +1,106 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// undocumentedRoutes - служебные маршруты, которые не входят в спецификацию API
+var undocumentedRoutes = map[string]bool{
+	"/openapi.json":   true,
+	"/docs":           true,
+	"/docs/*filepath": true,
+}
+
+var ginParam = regexp.MustCompile(`:(\w+)`)
+
+// newDocumentedRouter регистрирует маршруты всех обработчиков так же, как main
+func newDocumentedRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	health := NewHealthHandler(nil)
+	health.RegisterProbes(router)
+	for _, handler := range []interface{ RegisterRoutes(*gin.Engine) }{
+		NewAuthHandler(nil),
+		NewOIDCHandler(nil, nil),
+		NewUserHandler(nil),
+		NewAPIKeyHandler(nil),
+		NewSystemHandler(nil),
+		health,
+		NewBookHandler(nil),
+		NewRevisionHandler(nil),
+		NewOAIHandler(nil, config.OAIConfig{}),
+		NewDocsHandler(),
+	} {
+		handler.RegisterRoutes(router)
+	}
+	return router
+}
+
+func TestRoutesDocumented(t *testing.T) {
+	// Arrange
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+	router := newDocumentedRouter()
+
+	// Act
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		if undocumentedRoutes[route.Path] {
+			continue
+		}
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+		registered[route.Method+" "+path] = true
+	}
+	documented := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method := range item.Operations() {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	// Assert
+	for route := range registered {
+		assert.True(t, documented[route], "маршрут %s не описан в спецификации: добавьте аннотации swag и выполните go generate ./internal/openapi", route)
+	}
+	for route := range documented {
+		assert.True(t, registered[route], "маршрут %s описан в спецификации, но не зарегистрирован", route)
+	}
+}
+
+func TestDocsHandler(t *testing.T) {
+	testCases := []struct {
+		name         string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "Спецификация", path: "/openapi.json", expectedCode: http.StatusOK, expectedBody: `"openapi": "3.0.3"`},
+		{name: "Переход к Swagger UI", path: "/docs", expectedCode: http.StatusMovedPermanently},
+		{name: "Страница Swagger UI", path: "/docs/", expectedCode: http.StatusOK, expectedBody: `url: "/openapi.json"`},
+		{name: "Файлы Swagger UI", path: "/docs/swagger-ui-bundle.js", expectedCode: http.StatusOK, expectedBody: "SwaggerUIBundle"},
+		{name: "Неизвестный файл", path: "/docs/missing.js", expectedCode: http.StatusNotFound},
+	}
+
+	router := newDocumentedRouter()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/database"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// SystemHandler представляет обработчик HTTP-запросов мониторинга сервиса
+type SystemHandler struct {
+	db *sql.DB
+}
+
+// NewSystemHandler создает новый экземпляр SystemHandler
+func NewSystemHandler(db *sql.DB) *SystemHandler {
+	return &SystemHandler{db: db}
+}
+
+// RegisterRoutes регистрирует маршруты мониторинга
+func (h *SystemHandler) RegisterRoutes(router *gin.Engine) {
+	system := router.Group("/api/admin", middleware.RequirePermission(model.PermissionViewSystem))
+	{
+		system.GET("/db/stats", h.GetDBStats)
+	}
+}
+
+// GetDBStats возвращает статистику пула соединений с базой данных
+// @Summary Статистика пула соединений
+// @Description Возвращает число открытых, занятых и простаивающих соединений, а также время ожидания свободного соединения
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} database.PoolStats
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/admin/db/stats [get]
+func (h *SystemHandler) GetDBStats(c *gin.Context) {
+	c.JSON(http.StatusOK, database.NewPoolStats(h.db.Stats()))
+}
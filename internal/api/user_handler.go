@@ -0,0 +1,166 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/middleware"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/service"
+)
+
+// UserHandler представляет обработчик HTTP-запросов управления пользователями
+type UserHandler struct {
+	service *service.UserService
+}
+
+// NewUserHandler создает новый экземпляр UserHandler
+func NewUserHandler(service *service.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+// RegisterRoutes регистрирует маршруты управления пользователями
+func (h *UserHandler) RegisterRoutes(router *gin.Engine) {
+	users := router.Group("/api/admin/users", middleware.RequirePermission(model.PermissionManageUsers))
+	{
+		users.GET("", h.GetUsers)
+		users.POST("", h.CreateUser)
+		users.PUT("/:id/role", h.SetRole)
+		users.DELETE("/:id", h.DeleteUser)
+	}
+}
+
+// GetUsers получает список пользователей
+// @Summary Получение списка пользователей
+// @Description Получает всех пользователей с их ролями
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.User
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/users [get]
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	users, err := h.service.ListUsers(c.Request.Context())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// CreateUser создает пользователя
+// @Summary Создание пользователя
+// @Description Создает пользователя с указанной ролью (patron, librarian, admin). По умолчанию назначается роль patron.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user body model.UserCreate true "Данные пользователя"
+// @Success 201 {object} model.User
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/users [post]
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var userCreate model.UserCreate
+	if err := c.ShouldBindJSON(&userCreate); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный формат данных"))
+		return
+	}
+
+	user, err := h.service.CreateUser(c.Request.Context(), &userCreate)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// SetRole изменяет роль пользователя
+// @Summary Изменение роли пользователя
+// @Description Назначает пользователю роль. Новая роль действует после обновления токенов пользователя.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Param role body model.RoleUpdate true "Новая роль"
+// @Success 200 {object} model.User
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/users/{id}/role [put]
+func (h *UserHandler) SetRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
+		return
+	}
+
+	var update model.RoleUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный формат данных"))
+		return
+	}
+
+	user, err := h.service.SetRole(c.Request.Context(), uint(id), update.Role)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteUser удаляет пользователя
+// @Summary Удаление пользователя
+// @Description Удаляет пользователя и отзывает его refresh-токены
+// @Tags admin
+// @Security BearerAuth
+// @Param id path int true "ID пользователя"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, "неверный ID"))
+		return
+	}
+
+	if err := h.service.DeleteUser(c.Request.Context(), uint(id)); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, middleware.ErrorBody(c, err.Error()))
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, middleware.ErrorBody(c, err.Error()))
+	case errors.Is(err, service.ErrDuplicateUsername):
+		c.JSON(http.StatusConflict, middleware.ErrorBody(c, err.Error()))
+	case errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrInvalidUsername), errors.Is(err, service.ErrWeakPassword), errors.Is(err, service.ErrSelfModification):
+		c.JSON(http.StatusBadRequest, middleware.ErrorBody(c, err.Error()))
+	default:
+		internalError(c, err)
+	}
+}
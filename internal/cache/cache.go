@@ -0,0 +1,113 @@
+// Package cache реализует кэш с ограниченным сроком жизни записей
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache хранит значения по строковым ключам. LRU подходит для одного экземпляра API;
+// для нескольких экземпляров нужна реализация поверх общего хранилища (например, Redis),
+// иначе после изменения данных остальные экземпляры отдают прежние значения до истечения срока.
+type Cache interface {
+	// Get возвращает значение ключа; false означает, что значения нет или срок истек
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set сохраняет значение на ttl; нулевой ttl не ограничивает срок жизни
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// entry представляет запись LRU
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// LRU хранит записи в памяти процесса. При превышении емкости вытесняются
+// записи, которые дольше всего не запрашивались.
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+	now      func() time.Time
+}
+
+// NewLRU создает LRU на capacity записей
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Get возвращает значение ключа
+func (c *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := elem.Value.(*entry)
+	if !e.expires.IsZero() && !c.now().Before(e.expires) {
+		c.remove(elem)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(elem)
+	return e.value, true, nil
+}
+
+// Set сохраняет значение ключа
+func (c *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.now().Add(ttl)
+	}
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*entry)
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Delete удаляет ключи
+func (c *LRU) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.entries[key]; ok {
+			c.remove(elem)
+		}
+	}
+	return nil
+}
+
+// Len возвращает число записей, включая записи с истекшим сроком, которые еще не удалены
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*entry).key)
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUExpiration(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewLRU(10)
+	c.now = func() time.Time { return now }
+	require.NoError(t, c.Set(ctx, "book:1", []byte("Мы"), time.Minute))
+	require.NoError(t, c.Set(ctx, "generation", []byte("1"), 0))
+
+	// Act & Assert: до истечения срока значение доступно
+	value, ok, err := c.Get(ctx, "book:1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("Мы"), value)
+
+	// По истечении срока значение удаляется
+	now = now.Add(time.Minute)
+	_, ok, _ = c.Get(ctx, "book:1")
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.Len())
+
+	// Запись без срока не истекает
+	now = now.Add(24 * time.Hour)
+	_, ok, _ = c.Get(ctx, "generation")
+	assert.True(t, ok)
+}
+
+func TestLRUEviction(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	c := NewLRU(2)
+	_ = c.Set(ctx, "a", []byte("1"), 0)
+	_ = c.Set(ctx, "b", []byte("2"), 0)
+
+	// Act: обращение к "a" делает вытесняемой запись "b"
+	_, _, _ = c.Get(ctx, "a")
+	_ = c.Set(ctx, "c", []byte("3"), 0)
+
+	// Assert
+	assert.Equal(t, 2, c.Len())
+	_, ok, _ := c.Get(ctx, "b")
+	assert.False(t, ok)
+	_, ok, _ = c.Get(ctx, "a")
+	assert.True(t, ok)
+	_, ok, _ = c.Get(ctx, "c")
+	assert.True(t, ok)
+}
+
+func TestLRUSetAndDelete(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	c := NewLRU(10)
+	_ = c.Set(ctx, "a", []byte("1"), 0)
+	_ = c.Set(ctx, "b", []byte("2"), 0)
+
+	// Act
+	_ = c.Set(ctx, "a", []byte("3"), 0)
+	require.NoError(t, c.Delete(ctx, "b", "missing"))
+
+	// Assert
+	value, ok, _ := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("3"), value, "значение перезаписывается")
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.Len())
+}
@@ -0,0 +1,99 @@
+package citation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// translit задает транслитерацию кириллицы для ключей BibTeX
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu",
+	'я': "ia",
+}
+
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"{", `\{`,
+	"}", `\}`,
+	"&", `\&`,
+	"%", `\%`,
+	"$", `\$`,
+	"#", `\#`,
+	"_", `\_`,
+	"~", `\textasciitilde{}`,
+	"^", `\textasciicircum{}`,
+)
+
+func formatBibTeX(books []model.Book) string {
+	var b strings.Builder
+	used := make(map[string]int, len(books))
+
+	for i := range books {
+		book := &books[i]
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		key := bibtexKey(book)
+		used[key]++
+		if n := used[key]; n > 1 {
+			// Одинаковые ключи различаются суффиксом: tolstoi1869b
+			key += string(rune('a' + n - 1))
+		}
+
+		authors := parseAuthors(book.Author)
+		inverted := make([]string, len(authors))
+		for j, a := range authors {
+			inverted[j] = a.inverted()
+		}
+
+		fmt.Fprintf(&b, "@book{%s,\n", key)
+		writeBibTeXField(&b, "author", strings.Join(inverted, " and "))
+		writeBibTeXField(&b, "title", book.Title)
+		writeBibTeXField(&b, "publisher", book.Publisher)
+		if book.Year > 0 {
+			writeBibTeXField(&b, "year", strconv.Itoa(book.Year))
+		}
+		writeBibTeXField(&b, "isbn", book.ISBN)
+		writeBibTeXField(&b, "abstract", book.Description)
+		b.WriteString("}\n")
+	}
+
+	return b.String()
+}
+
+func writeBibTeXField(b *strings.Builder, field, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, "  %s = {%s},\n", field, bibtexEscaper.Replace(value))
+}
+
+// bibtexKey строит ключ из транслитерированной фамилии первого автора и года
+func bibtexKey(book *model.Book) string {
+	var key strings.Builder
+	if authors := parseAuthors(book.Author); len(authors) > 0 {
+		for _, r := range strings.ToLower(authors[0].Family) {
+			switch {
+			case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+				key.WriteRune(r)
+			case translit[r] != "":
+				key.WriteString(translit[r])
+			}
+		}
+	}
+	if key.Len() == 0 {
+		key.WriteString("book" + strconv.FormatUint(uint64(book.ID), 10))
+	}
+	if book.Year > 0 {
+		key.WriteString(strconv.Itoa(book.Year))
+	}
+	return key.String()
+}
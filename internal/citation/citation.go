@@ -0,0 +1,130 @@
+// Package citation формирует библиографические ссылки на книги
+// в форматах BibTeX, RIS, CSL-JSON и стилях APA и ГОСТ Р 7.0.100-2018
+package citation
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// Поддерживаемые форматы ссылок
+const (
+	FormatBibTeX  = "bibtex"
+	FormatRIS     = "ris"
+	FormatCSLJSON = "csl-json"
+	FormatAPA     = "apa"
+	FormatGOST    = "gost"
+)
+
+// ErrUnknownFormat возвращается при неизвестном формате ссылки
+var ErrUnknownFormat = errors.New("неизвестный формат ссылки")
+
+// ContentType возвращает MIME-тип для формата ссылки
+func ContentType(format string) string {
+	switch format {
+	case FormatBibTeX:
+		return "application/x-bibtex; charset=utf-8"
+	case FormatRIS:
+		return "application/x-research-info-systems; charset=utf-8"
+	case FormatCSLJSON:
+		return "application/vnd.citationstyles.csl+json; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Format формирует ссылки на книги в указанном формате
+func Format(books []model.Book, format string) (string, error) {
+	switch format {
+	case FormatBibTeX:
+		return formatBibTeX(books), nil
+	case FormatRIS:
+		return formatRIS(books), nil
+	case FormatCSLJSON:
+		return formatCSLJSON(books)
+	case FormatAPA:
+		return formatLines(books, formatAPA), nil
+	case FormatGOST:
+		return formatLines(books, formatGOST), nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+func formatLines(books []model.Book, style func(book *model.Book) string) string {
+	var b strings.Builder
+	for i := range books {
+		b.WriteString(style(&books[i]))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// name представляет имя автора, разделенное на фамилию и имена
+type name struct {
+	Family string
+	Given  []string
+}
+
+// parseAuthors разбирает поле автора. В каталоге имена хранятся в прямом
+// порядке ("Лев Толстой"), несколько авторов разделяются запятой или точкой с запятой.
+func parseAuthors(author string) []name {
+	var names []name
+	for _, part := range strings.FieldsFunc(author, func(r rune) bool { return r == ',' || r == ';' }) {
+		words := strings.Fields(part)
+		if len(words) == 0 {
+			continue
+		}
+		names = append(names, name{Family: words[len(words)-1], Given: words[:len(words)-1]})
+	}
+	return names
+}
+
+// inverted возвращает имя в виде "Фамилия, Имя Отчество"
+func (n name) inverted() string {
+	if len(n.Given) == 0 {
+		return n.Family
+	}
+	return n.Family + ", " + strings.Join(n.Given, " ")
+}
+
+// initials возвращает инициалы, например "Л. Н."
+func (n name) initials() string {
+	parts := make([]string, 0, len(n.Given))
+	for _, given := range n.Given {
+		// Дефисные имена сокращаются по частям: Жан-Поль -> Ж.-П.
+		var hyphenated []string
+		for _, piece := range strings.Split(given, "-") {
+			if r, _ := utf8.DecodeRuneInString(piece); r != utf8.RuneError {
+				hyphenated = append(hyphenated, string(unicode.ToUpper(r))+".")
+			}
+		}
+		if len(hyphenated) > 0 {
+			parts = append(parts, strings.Join(hyphenated, "-"))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// join соединяет непустые части через разделитель
+func join(sep string, parts ...string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
+
+// withPeriod добавляет точку в конце, если строка не заканчивается знаком препинания
+func withPeriod(s string) string {
+	if s == "" || strings.HasSuffix(s, ".") || strings.HasSuffix(s, "?") || strings.HasSuffix(s, "!") {
+		return s
+	}
+	return s + "."
+}
@@ -0,0 +1,126 @@
+package citation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var warAndPeace = model.Book{
+	ID:        1,
+	Title:     "Война и мир",
+	Author:    "Лев Николаевич Толстой",
+	ISBN:      "9785171147440",
+	Year:      1869,
+	Publisher: "АСТ",
+}
+
+func TestFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		books    []model.Book
+		format   string
+		expected string
+	}{
+		{
+			name:   "BibTeX",
+			books:  []model.Book{warAndPeace},
+			format: FormatBibTeX,
+			expected: "@book{tolstoi1869,\n" +
+				"  author = {Толстой, Лев Николаевич},\n" +
+				"  title = {Война и мир},\n" +
+				"  publisher = {АСТ},\n" +
+				"  year = {1869},\n" +
+				"  isbn = {9785171147440},\n" +
+				"}\n",
+		},
+		{
+			name:   "RIS",
+			books:  []model.Book{warAndPeace},
+			format: FormatRIS,
+			expected: "TY  - BOOK\r\n" +
+				"AU  - Толстой, Лев Николаевич\r\n" +
+				"TI  - Война и мир\r\n" +
+				"PY  - 1869\r\n" +
+				"PB  - АСТ\r\n" +
+				"SN  - 9785171147440\r\n" +
+				"ER  - \r\n",
+		},
+		{
+			name:     "APA",
+			books:    []model.Book{warAndPeace},
+			format:   FormatAPA,
+			expected: "Толстой, Л. Н. (1869). Война и мир. АСТ.\n",
+		},
+		{
+			name:     "APA с несколькими авторами",
+			books:    []model.Book{{Title: "Двенадцать стульев", Author: "Илья Ильф, Евгений Петров", Year: 1928}},
+			format:   FormatAPA,
+			expected: "Ильф, И., & Петров, Е. (1928). Двенадцать стульев.\n",
+		},
+		{
+			name:     "ГОСТ",
+			books:    []model.Book{warAndPeace},
+			format:   FormatGOST,
+			expected: "Толстой, Л. Н. Война и мир / Л. Н. Толстой. – [Б. м.] : АСТ, 1869. – ISBN 9785171147440.\n",
+		},
+		{
+			name: "ГОСТ с четырьмя авторами",
+			books: []model.Book{{
+				Title:  "Сборник",
+				Author: "Анна Иванова, Борис Петров, Вера Сидорова, Глеб Смирнов",
+				Year:   2020,
+			}},
+			format:   FormatGOST,
+			expected: "Сборник / А. Иванова [и др.]. – 2020.\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			result, err := Format(tc.books, tc.format)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestFormatBibTeXUniqueKeys(t *testing.T) {
+	// Arrange
+	second := warAndPeace
+	second.ID = 2
+
+	// Act
+	result, err := Format([]model.Book{warAndPeace, second}, FormatBibTeX)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, result, "@book{tolstoi1869,")
+	assert.Contains(t, result, "@book{tolstoi1869b,")
+}
+
+func TestFormatCSLJSON(t *testing.T) {
+	// Act
+	result, err := Format([]model.Book{warAndPeace}, FormatCSLJSON)
+
+	// Assert
+	require.NoError(t, err)
+	var items []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(result), &items))
+	require.Len(t, items, 1)
+	assert.Equal(t, "book-1", items[0]["id"])
+	assert.Equal(t, "book", items[0]["type"])
+	assert.Equal(t, []any{map[string]any{"family": "Толстой", "given": "Лев Николаевич"}}, items[0]["author"])
+	assert.Equal(t, map[string]any{"date-parts": []any{[]any{float64(1869)}}}, items[0]["issued"])
+}
+
+func TestFormatUnknown(t *testing.T) {
+	_, err := Format([]model.Book{warAndPeace}, "mla")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,57 @@
+package citation
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// cslItem представляет запись CSL-JSON
+type cslItem struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title,omitempty"`
+	Author    []cslName `json:"author,omitempty"`
+	Issued    *cslDate  `json:"issued,omitempty"`
+	Publisher string    `json:"publisher,omitempty"`
+	ISBN      string    `json:"ISBN,omitempty"`
+	Abstract  string    `json:"abstract,omitempty"`
+}
+
+type cslName struct {
+	Family string `json:"family"`
+	Given  string `json:"given,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+func formatCSLJSON(books []model.Book) (string, error) {
+	items := make([]cslItem, 0, len(books))
+	for i := range books {
+		book := &books[i]
+		item := cslItem{
+			ID:        "book-" + strconv.FormatUint(uint64(book.ID), 10),
+			Type:      "book",
+			Title:     book.Title,
+			Publisher: book.Publisher,
+			ISBN:      book.ISBN,
+			Abstract:  book.Description,
+		}
+		for _, a := range parseAuthors(book.Author) {
+			item.Author = append(item.Author, cslName{Family: a.Family, Given: join(" ", a.Given...)})
+		}
+		if book.Year > 0 {
+			item.Issued = &cslDate{DateParts: [][]int{{book.Year}}}
+		}
+		items = append(items, item)
+	}
+
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,38 @@
+package citation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+func formatRIS(books []model.Book) string {
+	var b strings.Builder
+	for i := range books {
+		book := &books[i]
+		writeRISTag(&b, "TY", "BOOK")
+		for _, a := range parseAuthors(book.Author) {
+			writeRISTag(&b, "AU", a.inverted())
+		}
+		writeRISTag(&b, "TI", book.Title)
+		if book.Year > 0 {
+			writeRISTag(&b, "PY", strconv.Itoa(book.Year))
+		}
+		writeRISTag(&b, "PB", book.Publisher)
+		writeRISTag(&b, "SN", book.ISBN)
+		writeRISTag(&b, "AB", book.Description)
+		b.WriteString("ER  - \r\n")
+	}
+	return b.String()
+}
+
+func writeRISTag(b *strings.Builder, tag, value string) {
+	// Значение тега RIS должно занимать одну строку
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s  - %s\r\n", tag, value)
+}
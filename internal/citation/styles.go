@@ -0,0 +1,88 @@
+package citation
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// formatAPA формирует ссылку в стиле APA 7:
+// Толстой, Л. (1869). Война и мир. АСТ.
+func formatAPA(book *model.Book) string {
+	authors := parseAuthors(book.Author)
+	names := make([]string, len(authors))
+	for i, a := range authors {
+		names[i] = join(", ", a.Family, a.initials())
+	}
+
+	var author string
+	switch len(names) {
+	case 0:
+	case 1:
+		author = names[0]
+	default:
+		author = strings.Join(names[:len(names)-1], ", ") + ", & " + names[len(names)-1]
+	}
+
+	year := "n.d."
+	if book.Year > 0 {
+		year = strconv.Itoa(book.Year)
+	}
+
+	// Без автора на первое место выходит название
+	if author == "" {
+		return join(" ", withPeriod(book.Title), "("+year+").", withPeriod(book.Publisher))
+	}
+	return join(" ", withPeriod(author), "("+year+").", withPeriod(book.Title), withPeriod(book.Publisher))
+}
+
+// formatGOST формирует библиографическую запись по ГОСТ Р 7.0.100-2018.
+// Для одного-трех авторов запись начинается с заголовка по первому автору:
+// Толстой, Л. Война и мир / Л. Толстой. – [Б. м.] : АСТ, 1869. – ISBN 9785171147440.
+// При четырех и более авторах запись начинается с названия.
+func formatGOST(book *model.Book) string {
+	authors := parseAuthors(book.Author)
+
+	var heading, responsibility string
+	if len(authors) > 0 && len(authors) <= 3 {
+		heading = join(", ", authors[0].Family, authors[0].initials())
+		direct := make([]string, len(authors))
+		for i, a := range authors {
+			direct[i] = join(" ", a.initials(), a.Family)
+		}
+		responsibility = strings.Join(direct, ", ")
+	} else if len(authors) > 3 {
+		responsibility = join(" ", authors[0].initials(), authors[0].Family) + " [и др.]"
+	}
+
+	var b strings.Builder
+	if heading != "" {
+		b.WriteString(withPeriod(heading))
+		b.WriteString(" ")
+	}
+	b.WriteString(book.Title)
+	if responsibility != "" {
+		b.WriteString(" / ")
+		b.WriteString(responsibility)
+	}
+
+	// Место издания в каталоге не хранится, поэтому указывается [Б. м.]
+	var publication string
+	if book.Publisher != "" {
+		publication = "[Б. м.] : " + book.Publisher
+	}
+	if book.Year > 0 {
+		publication = join(", ", publication, strconv.Itoa(book.Year))
+	}
+	if publication != "" {
+		b.WriteString(". – ")
+		b.WriteString(publication)
+	}
+	if book.ISBN != "" {
+		b.WriteString(". – ISBN ")
+		b.WriteString(book.ISBN)
+	}
+
+	return withPeriod(b.String())
+}
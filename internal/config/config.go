@@ -2,57 +2,392 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"strings"
+	"time"
 )
 
-// Config представляет конфигурацию приложения
+// Config представляет конфигурацию приложения.
+// Значения собираются по слоям: значения по умолчанию, файл конфигурации,
+// переменные окружения и флаги командной строки (см. Load).
 type Config struct {
-	DB     DBConfig
-	Server ServerConfig
+	DB        DBConfig        `yaml:"db" toml:"db"`
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	OAI       OAIConfig       `yaml:"oai" toml:"oai"`
+	Auth      AuthConfig      `yaml:"auth" toml:"auth"`
+	OIDC      OIDCConfig      `yaml:"oidc" toml:"oidc"`
+	RateLimit RateLimitConfig `yaml:"rate_limit" toml:"rate_limit"`
+	Metrics   MetricsConfig   `yaml:"metrics" toml:"metrics"`
+	Log       LogConfig       `yaml:"log" toml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing" toml:"tracing"`
+	OpenAPI   OpenAPIConfig   `yaml:"openapi" toml:"openapi"`
+	Cache     CacheConfig     `yaml:"cache" toml:"cache"`
 }
 
+// Драйверы хранилища данных
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	// DriverMemory хранит книги в памяти процесса, а учетные записи - во временной базе SQLite
+	DriverMemory = "memory"
+)
+
 // DBConfig представляет конфигурацию базы данных
 type DBConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	// Driver выбирает хранилище: postgres, sqlite или memory
+	Driver string `yaml:"driver" toml:"driver"`
+	// SQLitePath задает файл базы данных для драйвера sqlite
+	SQLitePath string `yaml:"sqlite_path" toml:"sqlite_path"`
+
+	Host     string `yaml:"host" toml:"host"`
+	Port     int    `yaml:"port" toml:"port"`
+	User     string `yaml:"user" toml:"user"`
+	Password string `yaml:"password" toml:"password"`
+	DBName   string `yaml:"name" toml:"name"`
+	SSLMode  string `yaml:"sslmode" toml:"sslmode"`
+
+	// ConnectTimeout ограничивает установку одного соединения
+	ConnectTimeout Duration `yaml:"connect_timeout" toml:"connect_timeout"`
+	// QueryTimeout задает statement_timeout PostgreSQL; 0 снимает ограничение
+	QueryTimeout Duration `yaml:"query_timeout" toml:"query_timeout"`
+	// SlowQuery задает длительность, после которой запрос записывается в журнал
+	// с уровнем warn; 0 отключает запись медленных запросов
+	SlowQuery Duration `yaml:"slow_query" toml:"slow_query"`
+
+	// Настройки пула соединений; 0 в MaxOpenConns снимает ограничение
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+
+	// ConnectAttempts задает число попыток подключения при запуске. Пауза между
+	// попытками начинается с ConnectBackoff и удваивается до ConnectMaxBackoff.
+	ConnectAttempts   int      `yaml:"connect_attempts" toml:"connect_attempts"`
+	ConnectBackoff    Duration `yaml:"connect_backoff" toml:"connect_backoff"`
+	ConnectMaxBackoff Duration `yaml:"connect_max_backoff" toml:"connect_max_backoff"`
+
+	// MigrateOnStart применяет непримененные миграции схемы при запуске сервера
+	MigrateOnStart bool `yaml:"migrate_on_start" toml:"migrate_on_start"`
 }
 
 // ServerConfig представляет конфигурацию сервера
 type ServerConfig struct {
-	Port string
+	Port int `yaml:"port" toml:"port"`
+	// TrustedProxies перечисляет адреса прокси, которым разрешено передавать IP клиента
+	// в X-Forwarded-For. По умолчанию заголовку не доверяют.
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+	// RequestTimeout ограничивает время обработки запроса; по истечении срока
+	// запросы к базе данных отменяются. 0 снимает ограничение.
+	RequestTimeout Duration `yaml:"request_timeout" toml:"request_timeout"`
+	// RouteTimeouts переопределяет RequestTimeout для маршрутов с общим префиксом пути
+	RouteTimeouts []RouteTimeout `yaml:"route_timeouts" toml:"route_timeouts"`
+	// ShutdownDelay - пауза между сигналом остановки и закрытием сервера, за которую
+	// балансировщик замечает неготовность /readyz и перестает присылать запросы
+	ShutdownDelay Duration `yaml:"shutdown_delay" toml:"shutdown_delay"`
+	// Compression включает сжатие ответов gzip или brotli по заголовку Accept-Encoding
+	Compression bool `yaml:"compression" toml:"compression"`
+	// TLSCertFile и TLSKeyFile - пути к сертификату и ключу в формате PEM. Если они
+	// заданы, сервер принимает HTTPS и HTTP/2.
+	TLSCertFile string `yaml:"tls_cert_file" toml:"tls_cert_file"`
+	TLSKeyFile  string `yaml:"tls_key_file" toml:"tls_key_file"`
+	// H2C включает HTTP/2 без TLS для внутренних сетей, где TLS завершается
+	// на балансировщике или в service mesh
+	H2C bool `yaml:"h2c" toml:"h2c"`
+}
+
+// TLSEnabled сообщает, принимает ли сервер HTTPS
+func (c ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" || c.TLSKeyFile != ""
+}
+
+// RouteTimeout задает время обработки запросов к маршрутам с общим префиксом пути
+type RouteTimeout struct {
+	PathPrefix string   `yaml:"path_prefix" toml:"path_prefix"`
+	Timeout    Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// OAIConfig представляет настройки провайдера OAI-PMH
+type OAIConfig struct {
+	RepositoryName       string `yaml:"repository_name" toml:"repository_name"`
+	BaseURL              string `yaml:"base_url" toml:"base_url"`
+	AdminEmail           string `yaml:"admin_email" toml:"admin_email"`
+	RepositoryIdentifier string `yaml:"repository_identifier" toml:"repository_identifier"`
+}
+
+// AuthConfig представляет настройки аутентификации
+type AuthConfig struct {
+	// JWTSecret задает ключ подписи токенов. Если он пуст, ключ генерируется
+	// при запуске и выданные токены перестают действовать после перезапуска.
+	JWTSecret       string   `yaml:"jwt_secret" toml:"jwt_secret"`
+	Issuer          string   `yaml:"issuer" toml:"issuer"`
+	AccessTokenTTL  Duration `yaml:"access_token_ttl" toml:"access_token_ttl"`
+	RefreshTokenTTL Duration `yaml:"refresh_token_ttl" toml:"refresh_token_ttl"`
+	// AdminUsername и AdminPassword задают пользователя, создаваемого при первом запуске
+	AdminUsername string `yaml:"admin_username" toml:"admin_username"`
+	AdminPassword string `yaml:"admin_password" toml:"admin_password"`
+}
+
+// OIDCConfig представляет настройки входа через внешний провайдер OpenID Connect
+type OIDCConfig struct {
+	// IssuerURL задает адрес провайдера; настройки получаются через discovery.
+	// Пустое значение отключает вход через OIDC.
+	IssuerURL    string   `yaml:"issuer_url" toml:"issuer_url"`
+	ClientID     string   `yaml:"client_id" toml:"client_id"`
+	ClientSecret string   `yaml:"client_secret" toml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url" toml:"redirect_url"`
+	Scopes       []string `yaml:"scopes" toml:"scopes"`
+	// UsernameClaim задает claim с именем пользователя
+	UsernameClaim string `yaml:"username_claim" toml:"username_claim"`
+	// RoleClaim задает claim со списком групп или ролей пользователя
+	RoleClaim string `yaml:"role_claim" toml:"role_claim"`
+	// RoleMapping сопоставляет значения RoleClaim локальным ролям
+	RoleMapping map[string]string `yaml:"role_mapping" toml:"role_mapping"`
+	// DefaultRole назначается, если ни одно значение RoleClaim не сопоставлено роли
+	DefaultRole string `yaml:"default_role" toml:"default_role"`
+}
+
+// Enabled проверяет, настроен ли вход через OIDC
+func (c OIDCConfig) Enabled() bool {
+	return c.IssuerURL != ""
+}
+
+// RateLimitConfig представляет настройки ограничения частоты запросов
+type RateLimitConfig struct {
+	Enabled bool             `yaml:"enabled" toml:"enabled"`
+	Groups  []RateLimitGroup `yaml:"groups" toml:"groups"`
+	// FailedAuth ограничивает неудачные попытки аутентификации по заголовку
+	// Authorization с одного IP-адреса; проверяется до учетных данных
+	FailedAuth RateLimitBucket `yaml:"failed_auth" toml:"failed_auth"`
+}
+
+// RateLimitBucket задает скорость пополнения и емкость корзины
+type RateLimitBucket struct {
+	RequestsPerMinute int `yaml:"requests_per_minute" toml:"requests_per_minute"`
+	Burst             int `yaml:"burst" toml:"burst"`
+}
+
+// RateLimitGroup задает ограничение для маршрутов с общим префиксом пути
+type RateLimitGroup struct {
+	Name              string `yaml:"name" toml:"name"`
+	PathPrefix        string `yaml:"path_prefix" toml:"path_prefix"`
+	RequestsPerMinute int    `yaml:"requests_per_minute" toml:"requests_per_minute"`
+	Burst             int    `yaml:"burst" toml:"burst"`
+}
+
+// MetricsConfig представляет настройки экспорта метрик Prometheus
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// Path задает путь, по которому отдаются метрики. Путь не требует
+	// аутентификации, поэтому доступ к нему стоит ограничить на прокси.
+	Path string `yaml:"path" toml:"path"`
+}
+
+// Уровни журнала
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// Форматы журнала
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// LogConfig представляет настройки журнала
+type LogConfig struct {
+	// Level задает минимальный уровень записей: debug, info, warn или error.
+	// На уровне debug в журнал попадают все SQL-запросы.
+	Level  string `yaml:"level" toml:"level"`
+	Format string `yaml:"format" toml:"format"`
 }
 
-// GetConfig возвращает конфигурацию приложения
-func GetConfig() *Config {
+// Экспортеры трассировки
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+// TracingConfig представляет настройки трассировки OpenTelemetry
+type TracingConfig struct {
+	// Exporter выбирает, куда отправляются спаны: none, stdout (для локального
+	// запуска) или otlp. При none заголовок traceparent все равно передается дальше.
+	Exporter string `yaml:"exporter" toml:"exporter"`
+	// Endpoint задает адрес коллектора OTLP/HTTP в виде host:port
+	Endpoint string `yaml:"endpoint" toml:"endpoint"`
+	// Insecure отключает TLS при отправке в коллектор
+	Insecure    bool   `yaml:"insecure" toml:"insecure"`
+	ServiceName string `yaml:"service_name" toml:"service_name"`
+	// SampleRatio задает долю записываемых трасс от 0 до 1. Если вызывающий
+	// сервис уже принял решение о записи трассы, оно сохраняется.
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+// Enabled проверяет, экспортируются ли спаны
+func (c TracingConfig) Enabled() bool {
+	return c.Exporter != TracingExporterNone
+}
+
+// OpenAPIConfig представляет настройки проверки запросов и ответов по спецификации OpenAPI
+type OpenAPIConfig struct {
+	// ValidateRequests отклоняет с 400 запросы, параметры или тело которых
+	// не соответствуют спецификации
+	ValidateRequests bool `yaml:"validate_requests" toml:"validate_requests"`
+	// ValidateResponses записывает в журнал ответы, не соответствующие спецификации.
+	// Ответы при этом буферизуются, поэтому режим предназначен для разработки и тестовых стендов.
+	ValidateResponses bool `yaml:"validate_responses" toml:"validate_responses"`
+}
+
+// Enabled проверяет, включена ли проверка по спецификации
+func (c OpenAPIConfig) Enabled() bool {
+	return c.ValidateRequests || c.ValidateResponses
+}
+
+// CacheConfig представляет настройки кэша чтения каталога
+type CacheConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// Size ограничивает число записей: книг, страниц списка и результатов поиска
+	Size int `yaml:"size" toml:"size"`
+	// TTL задает срок жизни записи. Кэш хранится в памяти процесса, поэтому
+	// при нескольких экземплярах API изменение на одном из них становится
+	// видно на остальных не позже чем через TTL.
+	TTL Duration `yaml:"ttl" toml:"ttl"`
+}
+
+// Default возвращает конфигурацию по умолчанию
+func Default() *Config {
 	return &Config{
 		DB: DBConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "book_library"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Driver:     DriverPostgres,
+			SQLitePath: "book_library.db",
+
+			Host:    "localhost",
+			Port:    5432,
+			User:    "postgres",
+			DBName:  "book_library",
+			SSLMode: "disable",
+
+			ConnectTimeout:    Duration{5 * time.Second},
+			QueryTimeout:      Duration{30 * time.Second},
+			SlowQuery:         Duration{200 * time.Millisecond},
+			MaxOpenConns:      25,
+			MaxIdleConns:      10,
+			ConnMaxLifetime:   Duration{30 * time.Minute},
+			ConnMaxIdleTime:   Duration{5 * time.Minute},
+			ConnectAttempts:   10,
+			ConnectBackoff:    Duration{500 * time.Millisecond},
+			ConnectMaxBackoff: Duration{10 * time.Second},
+			MigrateOnStart:    true,
 		},
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
+			Port:           8080,
+			RequestTimeout: Duration{30 * time.Second},
+			Compression:    true,
+			RouteTimeouts: []RouteTimeout{
+				// Поиск без индекса не должен занимать соединения надолго
+				{PathPrefix: "/api/books/search", Timeout: Duration{10 * time.Second}},
+				// Выгрузка и импорт всего каталога идут дольше обычных запросов
+				{PathPrefix: "/api/books/export", Timeout: Duration{5 * time.Minute}},
+				{PathPrefix: "/api/books/import", Timeout: Duration{5 * time.Minute}},
+				{PathPrefix: "/api/books/bulk", Timeout: Duration{2 * time.Minute}},
+			},
+		},
+		OAI: OAIConfig{
+			RepositoryName:       "Book Library",
+			BaseURL:              "http://localhost:8080/oai",
+			AdminEmail:           "admin@example.com",
+			RepositoryIdentifier: "book-library.local",
+		},
+		Auth: AuthConfig{
+			Issuer:          "book-library-api",
+			AccessTokenTTL:  Duration{15 * time.Minute},
+			RefreshTokenTTL: Duration{7 * 24 * time.Hour},
+		},
+		OIDC: OIDCConfig{
+			RedirectURL:   "http://localhost:8080/api/auth/oidc/callback",
+			Scopes:        []string{"openid", "profile", "email"},
+			UsernameClaim: "preferred_username",
+			RoleClaim:     "groups",
+			DefaultRole:   "patron",
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Groups: []RateLimitGroup{
+				// Поиск выполняет ILIKE по всей таблице
+				{Name: "search", PathPrefix: "/api/books/search", RequestsPerMinute: 30, Burst: 10},
+				// Защита от подбора паролей
+				{Name: "auth", PathPrefix: "/api/auth", RequestsPerMinute: 10, Burst: 5},
+				{Name: "api", PathPrefix: "/api", RequestsPerMinute: 600, Burst: 100},
+			},
+			FailedAuth: RateLimitBucket{RequestsPerMinute: 10, Burst: 10},
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Log: LogConfig{
+			Level:  LogLevelInfo,
+			Format: LogFormatJSON,
+		},
+		Tracing: TracingConfig{
+			Exporter:    TracingExporterNone,
+			Endpoint:    "localhost:4318",
+			ServiceName: "book-library-api",
+			SampleRatio: 1,
+		},
+		Cache: CacheConfig{
+			Enabled: true,
+			Size:    10000,
+			TTL:     Duration{30 * time.Second},
 		},
 	}
 }
 
 // GetDSN возвращает строку подключения к базе данных
 func (c *DBConfig) GetDSN() string {
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		quoteDSN(c.Host), c.Port, quoteDSN(c.User), quoteDSN(c.Password), quoteDSN(c.DBName), quoteDSN(c.SSLMode))
+	if c.ConnectTimeout.Duration > 0 {
+		// connect_timeout задается в целых секундах
+		seconds := int((c.ConnectTimeout.Duration + time.Second - 1) / time.Second)
+		dsn += fmt.Sprintf(" connect_timeout=%d", seconds)
+	}
+	if c.QueryTimeout.Duration > 0 {
+		// Неизвестные драйверу параметры передаются серверу как параметры сеанса
+		dsn += fmt.Sprintf(" statement_timeout=%d", c.QueryTimeout.Milliseconds())
+	}
+	return dsn
 }
 
-// getEnv получает значение переменной окружения или возвращает значение по умолчанию
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
+// quoteDSN заключает значение в кавычки, если оно пустое или содержит пробелы.
+// Без кавычек пустой пароль поглотил бы следующий параметр строки подключения.
+func quoteDSN(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
 		return value
 	}
-	return defaultValue
-} 
\ No newline at end of file
+	value = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + value + "'"
+}
+
+// Duration представляет длительность, которая в файле конфигурации
+// и переменных окружения записывается строкой вида "15m" или "168h"
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText разбирает длительность из строки
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalText записывает длительность строкой
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
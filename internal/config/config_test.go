@@ -0,0 +1,285 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadLayers(t *testing.T) {
+	// Arrange: каждый слой переопределяет часть значений предыдущего
+	path := writeFile(t, "config.yaml", `
+db:
+  host: db.internal
+  port: 6432
+server:
+  port: 9000
+auth:
+  access_token_ttl: 5m
+rate_limit:
+  groups:
+    - name: search
+      path_prefix: /api/books/search
+      requests_per_minute: 5
+      burst: 1
+`)
+	t.Setenv("DB_PORT", "7432")
+	t.Setenv("JWT_REFRESH_TTL", "24h")
+	t.Setenv("RATE_LIMIT_SEARCH_BURST", "3")
+	t.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+	t.Setenv("OPENAPI_VALIDATE_REQUESTS", "true")
+	t.Setenv("SERVER_COMPRESSION", "false")
+
+	// Act
+	cfg, opts, err := Load("api", []string{"-config", path, "-port", "9100"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, path, opts.ConfigFile)
+	assert.Equal(t, "db.internal", cfg.DB.Host, "значение из файла")
+	assert.Equal(t, "book_library", cfg.DB.DBName, "значение по умолчанию")
+	assert.Equal(t, 7432, cfg.DB.Port, "переменная окружения важнее файла")
+	assert.Equal(t, 9100, cfg.Server.Port, "флаг важнее файла")
+	assert.Equal(t, 5*time.Minute, cfg.Auth.AccessTokenTTL.Duration)
+	assert.Equal(t, 24*time.Hour, cfg.Auth.RefreshTokenTTL.Duration)
+	require.Len(t, cfg.RateLimit.Groups, 1, "список из файла заменяет список по умолчанию")
+	assert.Equal(t, 3, cfg.RateLimit.Groups[0].Burst)
+	assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+	assert.True(t, cfg.OpenAPI.ValidateRequests)
+	assert.False(t, cfg.OpenAPI.ValidateResponses)
+	assert.False(t, cfg.Server.Compression)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoadFile(t *testing.T) {
+	testCases := []struct {
+		name          string
+		file          string
+		content       string
+		expectedError bool
+		check         func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "TOML",
+			file: "config.toml",
+			content: `
+[server]
+port = 8081
+
+[auth]
+refresh_token_ttl = "48h"
+
+[oidc.role_mapping]
+library-staff = "librarian"
+`,
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, 8081, cfg.Server.Port)
+				assert.Equal(t, 48*time.Hour, cfg.Auth.RefreshTokenTTL.Duration)
+				assert.Equal(t, "librarian", cfg.OIDC.RoleMapping["library-staff"])
+			},
+		},
+		{
+			name:    "Пустой YAML",
+			file:    "config.yml",
+			content: "",
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, Default(), cfg)
+			},
+		},
+		{
+			name:          "Неизвестный ключ YAML",
+			file:          "config.yaml",
+			content:       "server:\n  prot: 8081\n",
+			expectedError: true,
+		},
+		{
+			name:          "Неизвестный ключ TOML",
+			file:          "config.toml",
+			content:       "[server]\nprot = 8081\n",
+			expectedError: true,
+		},
+		{
+			name:          "Неверная длительность",
+			file:          "config.yaml",
+			content:       "auth:\n  access_token_ttl: 15\n",
+			expectedError: true,
+		},
+		{
+			name:          "Неподдерживаемый формат",
+			file:          "config.json",
+			content:       "{}",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			path := writeFile(t, tc.file, tc.content)
+
+			// Act
+			cfg, _, err := Load("api", []string{"-config", path})
+
+			// Assert
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadRejectsInvalidEnv(t *testing.T) {
+	// Arrange
+	t.Setenv("SERVER_PORT", "http")
+	t.Setenv("RATE_LIMIT_ENABLED", "sometimes")
+	t.Setenv("TRACING_SAMPLE_RATIO", "half")
+
+	// Act
+	_, _, err := Load("api", nil)
+
+	// Assert
+	assert.ErrorContains(t, err, "SERVER_PORT")
+	assert.ErrorContains(t, err, "RATE_LIMIT_ENABLED")
+	assert.ErrorContains(t, err, "TRACING_SAMPLE_RATIO")
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedKey string
+	}{
+		{name: "Порт сервера вне диапазона", modify: func(cfg *Config) { cfg.Server.Port = 70000 }, expectedKey: "server.port"},
+		{name: "Отрицательная пауза перед остановкой", modify: func(cfg *Config) { cfg.Server.ShutdownDelay.Duration = -time.Second }, expectedKey: "server.shutdown_delay"},
+		{name: "Ключ TLS без сертификата", modify: func(cfg *Config) { cfg.Server.TLSKeyFile = "/etc/library/tls.key" }, expectedKey: "server.tls_cert_file"},
+		{name: "h2c вместе с TLS", modify: func(cfg *Config) {
+			cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile, cfg.Server.H2C = "/etc/library/tls.crt", "/etc/library/tls.key", true
+		}, expectedKey: "server.h2c"},
+		{name: "Неизвестный sslmode", modify: func(cfg *Config) { cfg.DB.SSLMode = "on" }, expectedKey: "db.sslmode"},
+		{name: "Refresh-токен короче access-токена", modify: func(cfg *Config) { cfg.Auth.RefreshTokenTTL.Duration = time.Minute }, expectedKey: "auth.refresh_token_ttl"},
+		{name: "Короткий пароль администратора", modify: func(cfg *Config) {
+			cfg.Auth.AdminUsername, cfg.Auth.AdminPassword = "admin", "admin"
+		}, expectedKey: "auth.admin_password"},
+		{name: "OIDC без client_id", modify: func(cfg *Config) { cfg.OIDC.IssuerURL = "https://sso.example.edu" }, expectedKey: "oidc.client_id"},
+		{name: "Неизвестная роль OIDC", modify: func(cfg *Config) {
+			cfg.OIDC.IssuerURL, cfg.OIDC.ClientID = "https://sso.example.edu", "library"
+			cfg.OIDC.RoleMapping = map[string]string{"staff": "superuser"}
+		}, expectedKey: "oidc.role_mapping.staff"},
+		{name: "Простаивающих соединений больше открытых", modify: func(cfg *Config) { cfg.DB.MaxIdleConns = 50 }, expectedKey: "db.max_idle_conns"},
+		{name: "Нет попыток подключения", modify: func(cfg *Config) { cfg.DB.ConnectAttempts = 0 }, expectedKey: "db.connect_attempts"},
+		{name: "Предельная пауза меньше начальной", modify: func(cfg *Config) { cfg.DB.ConnectMaxBackoff.Duration = time.Millisecond }, expectedKey: "db.connect_max_backoff"},
+		{name: "Нулевой лимит", modify: func(cfg *Config) { cfg.RateLimit.Groups[0].Burst = 0 }, expectedKey: "rate_limit.groups[0].burst"},
+		{name: "Нулевой лимит неудачной аутентификации", modify: func(cfg *Config) { cfg.RateLimit.FailedAuth.Burst = 0 }, expectedKey: "rate_limit.failed_auth.burst"},
+		{name: "Неизвестный драйвер", modify: func(cfg *Config) { cfg.DB.Driver = "mysql" }, expectedKey: "db.driver"},
+		{name: "Адрес OTLP с протоколом", modify: func(cfg *Config) {
+			cfg.Tracing.Exporter, cfg.Tracing.Endpoint = TracingExporterOTLP, "http://collector:4318"
+		}, expectedKey: "tracing.endpoint"},
+		{name: "Доля трасс больше 1", modify: func(cfg *Config) { cfg.Tracing.Exporter, cfg.Tracing.SampleRatio = TracingExporterStdout, 2 }, expectedKey: "tracing.sample_ratio"},
+		{name: "Неизвестный уровень журнала", modify: func(cfg *Config) { cfg.Log.Level = "verbose" }, expectedKey: "log.level"},
+		{name: "Путь метрик без /", modify: func(cfg *Config) { cfg.Metrics.Path = "metrics" }, expectedKey: "metrics.path"},
+		{name: "Кэш без срока жизни", modify: func(cfg *Config) { cfg.Cache.TTL.Duration = 0 }, expectedKey: "cache.ttl"},
+		{name: "SQLite без файла", modify: func(cfg *Config) { cfg.DB.Driver, cfg.DB.SQLitePath = DriverSQLite, "" }, expectedKey: "db.sqlite_path"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			cfg := Default()
+			tc.modify(cfg)
+
+			// Act
+			err := cfg.Validate()
+
+			// Assert
+			assert.ErrorContains(t, err, tc.expectedKey)
+		})
+	}
+
+	assert.NoError(t, Default().Validate(), "значения по умолчанию корректны")
+
+	memory := Default()
+	memory.DB.Driver, memory.DB.Host = DriverMemory, ""
+	assert.NoError(t, memory.Validate(), "настройки PostgreSQL не проверяются для драйвера memory")
+}
+
+func TestGetDSN(t *testing.T) {
+	testCases := []struct {
+		name     string
+		modify   func(cfg *DBConfig)
+		expected string
+	}{
+		{
+			name:     "Таймауты по умолчанию",
+			modify:   func(cfg *DBConfig) {},
+			expected: "host=localhost port=5432 user=postgres password='' dbname=book_library sslmode=disable connect_timeout=5 statement_timeout=30000",
+		},
+		{
+			name: "Пароль с пробелом и кавычкой",
+			modify: func(cfg *DBConfig) {
+				cfg.Password = `it's secret`
+				cfg.QueryTimeout.Duration = 0
+			},
+			expected: `host=localhost port=5432 user=postgres password='it\'s secret' dbname=book_library sslmode=disable connect_timeout=5`,
+		},
+		{
+			name: "Таймаут подключения округляется вверх, ограничение запросов снято",
+			modify: func(cfg *DBConfig) {
+				cfg.ConnectTimeout.Duration = 1500 * time.Millisecond
+				cfg.QueryTimeout.Duration = 0
+			},
+			expected: "host=localhost port=5432 user=postgres password='' dbname=book_library sslmode=disable connect_timeout=2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			cfg := Default().DB
+			tc.modify(&cfg)
+
+			// Act
+			dsn := cfg.GetDSN()
+
+			// Assert
+			assert.Equal(t, tc.expected, dsn)
+		})
+	}
+}
+
+func TestWriteRedacted(t *testing.T) {
+	// Arrange
+	cfg := Default()
+	cfg.DB.Password = "db-secret"
+	cfg.Auth.JWTSecret = "jwt-secret"
+	cfg.OIDC.ClientSecret = "oidc-secret"
+	var buf bytes.Buffer
+
+	// Act
+	err := cfg.WriteRedacted(&buf)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), "secret\n")
+	assert.Contains(t, buf.String(), "password: '"+redactedValue+"'")
+	assert.Contains(t, buf.String(), "access_token_ttl: 15m0s")
+	assert.Equal(t, "db-secret", cfg.DB.Password, "исходная конфигурация не изменяется")
+
+	// Вывод можно загрузить обратно как файл конфигурации
+	path := writeFile(t, "printed.yaml", buf.String())
+	_, _, err = Load("api", []string{"-config", path})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,308 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Options представляет параметры запуска, не входящие в конфигурацию
+type Options struct {
+	// ConfigFile задает путь к файлу конфигурации (флаг -config или CONFIG_FILE)
+	ConfigFile string
+	// PrintConfig требует вывести итоговую конфигурацию и завершить работу
+	PrintConfig bool
+	// Args содержит аргументы, оставшиеся после флагов, например действие подкоманды
+	Args []string
+}
+
+// Load собирает конфигурацию по слоям, каждый следующий переопределяет предыдущий:
+// значения по умолчанию, файл YAML или TOML, переменные окружения, флаги командной строки.
+// args передаются без имени программы. Проверка значений выполняется отдельно через Validate.
+func Load(name string, args []string) (*Config, *Options, error) {
+	opts := &Options{}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&opts.ConfigFile, "config", os.Getenv("CONFIG_FILE"), "путь к файлу конфигурации (YAML или TOML)")
+	fs.BoolVar(&opts.PrintConfig, "print-config", false, "вывести итоговую конфигурацию без секретов и завершить работу")
+	flags := defineFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	opts.Args = fs.Args()
+
+	cfg := Default()
+	if opts.ConfigFile != "" {
+		if err := loadFile(cfg, opts.ConfigFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := applyEnv(cfg); err != nil {
+		return nil, nil, err
+	}
+
+	// Применяются только явно указанные флаги
+	fs.Visit(func(f *flag.Flag) {
+		if apply, ok := flags[f.Name]; ok {
+			apply(cfg)
+		}
+	})
+
+	return cfg, opts, nil
+}
+
+// defineFlags регистрирует флаги, переопределяющие конфигурацию. Секреты
+// флагами не передаются, так как аргументы процесса видны другим пользователям.
+func defineFlags(fs *flag.FlagSet) map[string]func(cfg *Config) {
+	port := fs.Int("port", 0, "порт HTTP-сервера")
+	requestTimeout := fs.Duration("request-timeout", 0, "ограничение времени обработки запроса")
+	tlsCertFile := fs.String("tls-cert-file", "", "путь к сертификату TLS")
+	tlsKeyFile := fs.String("tls-key-file", "", "путь к ключу TLS")
+	dbDriver := fs.String("db-driver", "", "хранилище данных: postgres, sqlite или memory")
+	dbHost := fs.String("db-host", "", "адрес PostgreSQL")
+	dbPort := fs.Int("db-port", 0, "порт PostgreSQL")
+	dbUser := fs.String("db-user", "", "пользователь PostgreSQL")
+	dbName := fs.String("db-name", "", "имя базы данных")
+	dbSSLMode := fs.String("db-sslmode", "", "режим SSL подключения к PostgreSQL")
+	dbMaxOpenConns := fs.Int("db-max-open-conns", 0, "максимум открытых соединений с PostgreSQL")
+	dbQueryTimeout := fs.Duration("db-query-timeout", 0, "ограничение времени выполнения запроса к PostgreSQL")
+	dbConnectAttempts := fs.Int("db-connect-attempts", 0, "число попыток подключения к PostgreSQL при запуске")
+	oaiBaseURL := fs.String("oai-base-url", "", "публичный адрес провайдера OAI-PMH")
+	rateLimit := fs.Bool("rate-limit", true, "ограничивать частоту запросов")
+	logLevel := fs.String("log-level", "", "уровень журнала: debug, info, warn или error")
+	logFormat := fs.String("log-format", "", "формат журнала: json или text")
+	tracingExporter := fs.String("tracing-exporter", "", "экспортер трассировки: none, stdout или otlp")
+
+	return map[string]func(cfg *Config){
+		"port":                func(cfg *Config) { cfg.Server.Port = *port },
+		"request-timeout":     func(cfg *Config) { cfg.Server.RequestTimeout.Duration = *requestTimeout },
+		"tls-cert-file":       func(cfg *Config) { cfg.Server.TLSCertFile = *tlsCertFile },
+		"tls-key-file":        func(cfg *Config) { cfg.Server.TLSKeyFile = *tlsKeyFile },
+		"db-driver":           func(cfg *Config) { cfg.DB.Driver = *dbDriver },
+		"db-host":             func(cfg *Config) { cfg.DB.Host = *dbHost },
+		"db-port":             func(cfg *Config) { cfg.DB.Port = *dbPort },
+		"db-user":             func(cfg *Config) { cfg.DB.User = *dbUser },
+		"db-name":             func(cfg *Config) { cfg.DB.DBName = *dbName },
+		"db-sslmode":          func(cfg *Config) { cfg.DB.SSLMode = *dbSSLMode },
+		"db-max-open-conns":   func(cfg *Config) { cfg.DB.MaxOpenConns = *dbMaxOpenConns },
+		"db-query-timeout":    func(cfg *Config) { cfg.DB.QueryTimeout.Duration = *dbQueryTimeout },
+		"db-connect-attempts": func(cfg *Config) { cfg.DB.ConnectAttempts = *dbConnectAttempts },
+		"oai-base-url":        func(cfg *Config) { cfg.OAI.BaseURL = *oaiBaseURL },
+		"rate-limit":          func(cfg *Config) { cfg.RateLimit.Enabled = *rateLimit },
+		"log-level":           func(cfg *Config) { cfg.Log.Level = *logLevel },
+		"log-format":          func(cfg *Config) { cfg.Log.Format = *logFormat },
+		"tracing-exporter":    func(cfg *Config) { cfg.Tracing.Exporter = *tracingExporter },
+	}
+}
+
+// loadFile читает файл конфигурации. Формат определяется по расширению;
+// неизвестные ключи считаются ошибкой, чтобы опечатки не проходили незамеченными.
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла конфигурации: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("ошибка разбора %s: %w", path, err)
+		}
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil {
+			return fmt.Errorf("ошибка разбора %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("неподдерживаемый формат файла конфигурации %s: ожидается .yaml, .yml или .toml", path)
+	}
+	return nil
+}
+
+// applyEnv переопределяет конфигурацию переменными окружения
+func applyEnv(cfg *Config) error {
+	env := &envLoader{}
+
+	env.string(&cfg.DB.Driver, "DB_DRIVER")
+	env.string(&cfg.DB.SQLitePath, "DB_SQLITE_PATH")
+	env.string(&cfg.DB.Host, "DB_HOST")
+	env.int(&cfg.DB.Port, "DB_PORT")
+	env.string(&cfg.DB.User, "DB_USER")
+	env.string(&cfg.DB.Password, "DB_PASSWORD")
+	env.string(&cfg.DB.DBName, "DB_NAME")
+	env.string(&cfg.DB.SSLMode, "DB_SSLMODE")
+	env.duration(&cfg.DB.ConnectTimeout, "DB_CONNECT_TIMEOUT")
+	env.duration(&cfg.DB.QueryTimeout, "DB_QUERY_TIMEOUT")
+	env.duration(&cfg.DB.SlowQuery, "DB_SLOW_QUERY")
+	env.int(&cfg.DB.MaxOpenConns, "DB_MAX_OPEN_CONNS")
+	env.int(&cfg.DB.MaxIdleConns, "DB_MAX_IDLE_CONNS")
+	env.duration(&cfg.DB.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME")
+	env.duration(&cfg.DB.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME")
+	env.int(&cfg.DB.ConnectAttempts, "DB_CONNECT_ATTEMPTS")
+	env.duration(&cfg.DB.ConnectBackoff, "DB_CONNECT_BACKOFF")
+	env.duration(&cfg.DB.ConnectMaxBackoff, "DB_CONNECT_MAX_BACKOFF")
+	env.bool(&cfg.DB.MigrateOnStart, "DB_MIGRATE_ON_START")
+
+	env.int(&cfg.Server.Port, "SERVER_PORT")
+	env.list(&cfg.Server.TrustedProxies, "TRUSTED_PROXIES")
+	env.duration(&cfg.Server.RequestTimeout, "SERVER_REQUEST_TIMEOUT")
+	env.duration(&cfg.Server.ShutdownDelay, "SERVER_SHUTDOWN_DELAY")
+	env.bool(&cfg.Server.Compression, "SERVER_COMPRESSION")
+	env.string(&cfg.Server.TLSCertFile, "SERVER_TLS_CERT_FILE")
+	env.string(&cfg.Server.TLSKeyFile, "SERVER_TLS_KEY_FILE")
+	env.bool(&cfg.Server.H2C, "SERVER_H2C")
+
+	env.string(&cfg.OAI.RepositoryName, "OAI_REPOSITORY_NAME")
+	env.string(&cfg.OAI.BaseURL, "OAI_BASE_URL")
+	env.string(&cfg.OAI.AdminEmail, "OAI_ADMIN_EMAIL")
+	env.string(&cfg.OAI.RepositoryIdentifier, "OAI_REPOSITORY_IDENTIFIER")
+
+	env.string(&cfg.Auth.JWTSecret, "JWT_SECRET")
+	env.string(&cfg.Auth.Issuer, "JWT_ISSUER")
+	env.duration(&cfg.Auth.AccessTokenTTL, "JWT_ACCESS_TTL")
+	env.duration(&cfg.Auth.RefreshTokenTTL, "JWT_REFRESH_TTL")
+	env.string(&cfg.Auth.AdminUsername, "ADMIN_USERNAME")
+	env.string(&cfg.Auth.AdminPassword, "ADMIN_PASSWORD")
+
+	env.string(&cfg.OIDC.IssuerURL, "OIDC_ISSUER_URL")
+	env.string(&cfg.OIDC.ClientID, "OIDC_CLIENT_ID")
+	env.string(&cfg.OIDC.ClientSecret, "OIDC_CLIENT_SECRET")
+	env.string(&cfg.OIDC.RedirectURL, "OIDC_REDIRECT_URL")
+	env.list(&cfg.OIDC.Scopes, "OIDC_SCOPES")
+	env.string(&cfg.OIDC.UsernameClaim, "OIDC_USERNAME_CLAIM")
+	env.string(&cfg.OIDC.RoleClaim, "OIDC_ROLE_CLAIM")
+	env.mapping(&cfg.OIDC.RoleMapping, "OIDC_ROLE_MAPPING")
+	env.string(&cfg.OIDC.DefaultRole, "OIDC_DEFAULT_ROLE")
+
+	env.bool(&cfg.RateLimit.Enabled, "RATE_LIMIT_ENABLED")
+	for i := range cfg.RateLimit.Groups {
+		group := &cfg.RateLimit.Groups[i]
+		prefix := "RATE_LIMIT_" + strings.ToUpper(strings.ReplaceAll(group.Name, "-", "_"))
+		env.int(&group.RequestsPerMinute, prefix+"_RPM")
+		env.int(&group.Burst, prefix+"_BURST")
+	}
+	env.int(&cfg.RateLimit.FailedAuth.RequestsPerMinute, "RATE_LIMIT_FAILED_AUTH_RPM")
+	env.int(&cfg.RateLimit.FailedAuth.Burst, "RATE_LIMIT_FAILED_AUTH_BURST")
+
+	env.bool(&cfg.Metrics.Enabled, "METRICS_ENABLED")
+	env.string(&cfg.Metrics.Path, "METRICS_PATH")
+
+	env.string(&cfg.Log.Level, "LOG_LEVEL")
+	env.string(&cfg.Log.Format, "LOG_FORMAT")
+
+	env.string(&cfg.Tracing.Exporter, "TRACING_EXPORTER")
+	env.string(&cfg.Tracing.Endpoint, "TRACING_ENDPOINT")
+	env.bool(&cfg.Tracing.Insecure, "TRACING_INSECURE")
+	env.string(&cfg.Tracing.ServiceName, "TRACING_SERVICE_NAME")
+	env.float(&cfg.Tracing.SampleRatio, "TRACING_SAMPLE_RATIO")
+
+	env.bool(&cfg.OpenAPI.ValidateRequests, "OPENAPI_VALIDATE_REQUESTS")
+	env.bool(&cfg.OpenAPI.ValidateResponses, "OPENAPI_VALIDATE_RESPONSES")
+
+	env.bool(&cfg.Cache.Enabled, "CACHE_ENABLED")
+	env.int(&cfg.Cache.Size, "CACHE_SIZE")
+	env.duration(&cfg.Cache.TTL, "CACHE_TTL")
+
+	return errors.Join(env.errs...)
+}
+
+// envLoader читает переменные окружения и накапливает ошибки разбора
+type envLoader struct {
+	errs []error
+}
+
+func (l *envLoader) fail(key, value string, err error) {
+	l.errs = append(l.errs, fmt.Errorf("переменная %s=%q: %w", key, value, err))
+}
+
+func (l *envLoader) string(dst *string, key string) {
+	if value, ok := os.LookupEnv(key); ok {
+		*dst = value
+	}
+}
+
+func (l *envLoader) int(dst *int, key string) {
+	if value, ok := os.LookupEnv(key); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			l.fail(key, value, errors.New("ожидается целое число"))
+			return
+		}
+		*dst = n
+	}
+}
+
+func (l *envLoader) bool(dst *bool, key string) {
+	if value, ok := os.LookupEnv(key); ok {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			l.fail(key, value, errors.New("ожидается true или false"))
+			return
+		}
+		*dst = b
+	}
+}
+
+func (l *envLoader) float(dst *float64, key string) {
+	if value, ok := os.LookupEnv(key); ok {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			l.fail(key, value, errors.New("ожидается число"))
+			return
+		}
+		*dst = f
+	}
+}
+
+func (l *envLoader) duration(dst *Duration, key string) {
+	if value, ok := os.LookupEnv(key); ok {
+		if err := dst.UnmarshalText([]byte(value)); err != nil {
+			l.fail(key, value, errors.New("ожидается длительность, например 15m или 168h"))
+		}
+	}
+}
+
+// list разбирает значения, разделенные запятыми
+func (l *envLoader) list(dst *[]string, key string) {
+	if value, ok := os.LookupEnv(key); ok {
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*dst = list
+	}
+}
+
+// mapping разбирает пары вида "ключ=значение", разделенные запятыми
+func (l *envLoader) mapping(dst *map[string]string, key string) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	result := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(item, "=")
+		if !ok {
+			l.fail(key, value, errors.New("ожидается список пар ключ=значение"))
+			return
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	*dst = result
+}
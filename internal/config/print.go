@@ -0,0 +1,36 @@
+package config
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue заменяет секреты при выводе конфигурации
+const redactedValue = "[скрыто]"
+
+// Redacted возвращает копию конфигурации, в которой непустые секреты заменены на заглушку
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redact := func(secret *string) {
+		if *secret != "" {
+			*secret = redactedValue
+		}
+	}
+	redact(&redacted.DB.Password)
+	redact(&redacted.Auth.JWTSecret)
+	redact(&redacted.Auth.AdminPassword)
+	redact(&redacted.OIDC.ClientSecret)
+	return &redacted
+}
+
+// WriteRedacted выводит конфигурацию в формате YAML без секретов.
+// Вывод можно использовать как файл конфигурации после подстановки секретов.
+func (c *Config) WriteRedacted(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// Допустимые драйверы хранилища данных
+var drivers = []string{DriverPostgres, DriverSQLite, DriverMemory}
+
+// Допустимые значения sslmode драйвера PostgreSQL
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Допустимые уровни и форматы журнала
+var (
+	logLevels  = []string{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError}
+	logFormats = []string{LogFormatJSON, LogFormatText}
+)
+
+// Допустимые экспортеры трассировки
+var tracingExporters = []string{TracingExporterNone, TracingExporterStdout, TracingExporterOTLP}
+
+// Роли пользователей
+var roles = model.Roles()
+
+// minAdminPasswordLength совпадает с минимальной длиной пароля пользователя
+const minAdminPasswordLength = 8
+
+// Validate проверяет конфигурацию и возвращает все найденные ошибки сразу.
+// Ключи в сообщениях совпадают с ключами файла конфигурации.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(contains(drivers, c.DB.Driver), "db.driver", "допустимые значения: %s", strings.Join(drivers, ", "))
+	switch c.DB.Driver {
+	case DriverPostgres:
+		check(c.DB.Host != "", "db.host", "не указан адрес базы данных")
+		check(validPort(c.DB.Port), "db.port", "порт должен быть от 1 до 65535, получено %d", c.DB.Port)
+		check(c.DB.User != "", "db.user", "не указан пользователь базы данных")
+		check(c.DB.DBName != "", "db.name", "не указано имя базы данных")
+		check(contains(sslModes, c.DB.SSLMode), "db.sslmode", "допустимые значения: %s", strings.Join(sslModes, ", "))
+	case DriverSQLite:
+		check(c.DB.SQLitePath != "", "db.sqlite_path", "не указан файл базы данных")
+	}
+	check(c.DB.ConnectTimeout.Duration > 0, "db.connect_timeout", "длительность должна быть положительной")
+	check(c.DB.QueryTimeout.Duration >= 0, "db.query_timeout", "длительность не может быть отрицательной")
+	check(c.DB.SlowQuery.Duration >= 0, "db.slow_query", "длительность не может быть отрицательной")
+	check(c.DB.MaxOpenConns >= 0, "db.max_open_conns", "не может быть отрицательным")
+	check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns", "не может быть отрицательным")
+	if c.DB.MaxOpenConns > 0 {
+		check(c.DB.MaxIdleConns <= c.DB.MaxOpenConns, "db.max_idle_conns", "не должно превышать max_open_conns")
+	}
+	check(c.DB.ConnMaxLifetime.Duration >= 0, "db.conn_max_lifetime", "длительность не может быть отрицательной")
+	check(c.DB.ConnMaxIdleTime.Duration >= 0, "db.conn_max_idle_time", "длительность не может быть отрицательной")
+	check(c.DB.ConnectAttempts > 0, "db.connect_attempts", "должно быть положительным")
+	check(c.DB.ConnectBackoff.Duration > 0, "db.connect_backoff", "длительность должна быть положительной")
+	check(c.DB.ConnectMaxBackoff.Duration >= c.DB.ConnectBackoff.Duration, "db.connect_max_backoff", "должна быть не меньше connect_backoff")
+
+	check(validPort(c.Server.Port), "server.port", "порт должен быть от 1 до 65535, получено %d", c.Server.Port)
+	check(c.Server.RequestTimeout.Duration >= 0, "server.request_timeout", "длительность не может быть отрицательной")
+	check(c.Server.ShutdownDelay.Duration >= 0, "server.shutdown_delay", "длительность не может быть отрицательной")
+	if c.Server.TLSEnabled() {
+		check(c.Server.TLSCertFile != "", "server.tls_cert_file", "не указан сертификат для tls_key_file")
+		check(c.Server.TLSKeyFile != "", "server.tls_key_file", "не указан ключ для tls_cert_file")
+		check(!c.Server.H2C, "server.h2c", "h2c не используется вместе с TLS: HTTP/2 включается автоматически")
+	}
+	for i, route := range c.Server.RouteTimeouts {
+		key := fmt.Sprintf("server.route_timeouts[%d]", i)
+		check(strings.HasPrefix(route.PathPrefix, "/"), key+".path_prefix", "префикс пути должен начинаться с /")
+		check(route.Timeout.Duration >= 0, key+".timeout", "длительность не может быть отрицательной")
+	}
+
+	check(validURL(c.OAI.BaseURL), "oai.base_url", "ожидается абсолютный URL")
+	check(c.OAI.RepositoryIdentifier != "", "oai.repository_identifier", "не указан идентификатор репозитория")
+
+	check(c.Auth.Issuer != "", "auth.issuer", "не указан издатель токенов")
+	check(c.Auth.AccessTokenTTL.Duration > 0, "auth.access_token_ttl", "длительность должна быть положительной")
+	check(c.Auth.RefreshTokenTTL.Duration > c.Auth.AccessTokenTTL.Duration, "auth.refresh_token_ttl", "должен быть больше access_token_ttl")
+	if c.Auth.AdminUsername != "" {
+		check(len(c.Auth.AdminPassword) >= minAdminPasswordLength, "auth.admin_password",
+			"пароль администратора должен содержать не менее %d символов", minAdminPasswordLength)
+	}
+
+	if c.OIDC.Enabled() {
+		check(validURL(c.OIDC.IssuerURL), "oidc.issuer_url", "ожидается абсолютный URL")
+		check(c.OIDC.ClientID != "", "oidc.client_id", "не указан идентификатор клиента")
+		check(validURL(c.OIDC.RedirectURL), "oidc.redirect_url", "ожидается абсолютный URL")
+		check(contains(c.OIDC.Scopes, "openid"), "oidc.scopes", "должен содержать openid")
+		check(contains(roles, c.OIDC.DefaultRole), "oidc.default_role", "допустимые роли: %s", strings.Join(roles, ", "))
+		for value, role := range c.OIDC.RoleMapping {
+			check(contains(roles, role), "oidc.role_mapping."+value, "неизвестная роль %q", role)
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, group := range c.RateLimit.Groups {
+		key := fmt.Sprintf("rate_limit.groups[%d]", i)
+		check(group.Name != "" && !names[group.Name], key+".name", "имя группы должно быть непустым и уникальным")
+		check(strings.HasPrefix(group.PathPrefix, "/"), key+".path_prefix", "префикс пути должен начинаться с /")
+		check(group.RequestsPerMinute > 0, key+".requests_per_minute", "должно быть положительным")
+		check(group.Burst > 0, key+".burst", "должно быть положительным")
+		names[group.Name] = true
+	}
+	check(c.RateLimit.FailedAuth.RequestsPerMinute > 0, "rate_limit.failed_auth.requests_per_minute", "должно быть положительным")
+	check(c.RateLimit.FailedAuth.Burst > 0, "rate_limit.failed_auth.burst", "должно быть положительным")
+
+	if c.Metrics.Enabled {
+		check(strings.HasPrefix(c.Metrics.Path, "/"), "metrics.path", "путь должен начинаться с /")
+		check(!strings.HasPrefix(c.Metrics.Path, "/api/"), "metrics.path", "путь не должен пересекаться с маршрутами /api")
+	}
+
+	check(contains(logLevels, c.Log.Level), "log.level", "допустимые значения: %s", strings.Join(logLevels, ", "))
+	check(contains(logFormats, c.Log.Format), "log.format", "допустимые значения: %s", strings.Join(logFormats, ", "))
+
+	check(contains(tracingExporters, c.Tracing.Exporter), "tracing.exporter", "допустимые значения: %s", strings.Join(tracingExporters, ", "))
+	if c.Tracing.Enabled() {
+		check(c.Tracing.ServiceName != "", "tracing.service_name", "не указано имя сервиса")
+		check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "должно быть от 0 до 1")
+	}
+	if c.Tracing.Exporter == TracingExporterOTLP {
+		check(c.Tracing.Endpoint != "" && !strings.Contains(c.Tracing.Endpoint, "://"), "tracing.endpoint", "ожидается адрес коллектора в виде host:port")
+	}
+
+	if c.Cache.Enabled {
+		check(c.Cache.Size > 0, "cache.size", "должно быть положительным")
+		check(c.Cache.TTL.Duration > 0, "cache.ttl", "должно быть положительным")
+	}
+
+	return errors.Join(errs...)
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+func validURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
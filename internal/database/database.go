@@ -0,0 +1,145 @@
+// Package database открывает подключение к PostgreSQL с настройками пула
+// соединений и повторными попытками при запуске, а также к SQLite для
+// разработки и тестов без сервера базы данных
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// pgQueryCanceled - код ошибки PostgreSQL для запроса, отмененного по statement_timeout
+const pgQueryCanceled = "57014"
+
+// Open подключается к базе данных, выбранной cfg.Driver. Если база еще не готова,
+// подключение повторяется cfg.ConnectAttempts раз с экспоненциально растущей паузой.
+// Для драйвера memory открывается временная база SQLite в памяти.
+func Open(ctx context.Context, cfg config.DBConfig) (*gorm.DB, error) {
+	// Проверка соединения выполняется ниже с повторами
+	db, err := gorm.Open(dialector(cfg), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               NewLogger(slog.Default(), cfg.SlowQuery.Duration),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(NewTracing()); err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Driver == config.DriverMemory {
+		// База в памяти существует, пока открыто ее единственное соединение
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	} else {
+		ConfigurePool(sqlDB, cfg)
+	}
+
+	backoff := Backoff{Initial: cfg.ConnectBackoff.Duration, Max: cfg.ConnectMaxBackoff.Duration}
+	err = Retry(ctx, cfg.ConnectAttempts, backoff, func(ctx context.Context) error {
+		pingCtx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout.Duration)
+		defer cancel()
+		return sqlDB.PingContext(pingCtx)
+	})
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// CreateSchema создает таблицы по моделям. SQL-миграции написаны для PostgreSQL,
+// поэтому схема SQLite создается так.
+func CreateSchema(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(&model.Book{}, &model.BookRevision{}, &model.User{}, &model.RefreshToken{}, &model.APIKey{})
+}
+
+func dialector(cfg config.DBConfig) gorm.Dialector {
+	switch cfg.Driver {
+	case config.DriverSQLite:
+		// Запись блокирует файл целиком: ожидаем освобождения вместо немедленной ошибки
+		return sqlite.Open(cfg.SQLitePath + "?_busy_timeout=5000&_journal_mode=WAL")
+	case config.DriverMemory:
+		return sqlite.Open(":memory:")
+	default:
+		return postgres.Open(cfg.GetDSN())
+	}
+}
+
+// ConfigurePool применяет к пулу ограничения из конфигурации
+func ConfigurePool(db *sql.DB, cfg config.DBConfig) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime.Duration)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime.Duration)
+}
+
+// Backoff задает паузы между попытками: Initial, затем вдвое больше, но не более Max
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// Delay возвращает паузу перед попыткой с номером attempt (начиная с 1)
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// Retry выполняет fn, пока она не завершится успешно, не кончатся попытки
+// или не будет отменен контекст. Возвращается последняя ошибка fn.
+func Retry(ctx context.Context, attempts int, backoff Backoff, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if attempt >= attempts {
+			return fmt.Errorf("база данных недоступна после %d попыток: %w", attempts, err)
+		}
+
+		delay := backoff.Delay(attempt)
+		slog.WarnContext(ctx, "база данных недоступна",
+			"attempt", attempt, "attempts", attempts, "retry_in", delay.String(), "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("подключение к базе данных прервано: %w", err)
+		case <-timer.C:
+		}
+	}
+}
+
+// IsTimeout проверяет, что запрос прерван по истечении срока: контекста
+// или statement_timeout на стороне PostgreSQL
+func IsTimeout(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgQueryCanceled
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := Backoff{Initial: 100 * time.Millisecond, Max: time.Second}
+
+	testCases := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 1, expected: 100 * time.Millisecond},
+		{attempt: 2, expected: 200 * time.Millisecond},
+		{attempt: 4, expected: 800 * time.Millisecond},
+		{attempt: 5, expected: time.Second},
+		{attempt: 50, expected: time.Second},
+	}
+
+	for _, tc := range testCases {
+		// Act
+		delay := backoff.Delay(tc.attempt)
+
+		// Assert
+		assert.Equal(t, tc.expected, delay, "попытка %d", tc.attempt)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	errUnavailable := errors.New("connection refused")
+	backoff := Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+
+	testCases := []struct {
+		name          string
+		failures      int
+		attempts      int
+		expectedCalls int
+		expectedError bool
+	}{
+		{name: "Успех с первой попытки", failures: 0, attempts: 3, expectedCalls: 1},
+		{name: "Успех после повторов", failures: 2, attempts: 3, expectedCalls: 3},
+		{name: "Попытки исчерпаны", failures: 5, attempts: 3, expectedCalls: 3, expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			calls := 0
+			fn := func(ctx context.Context) error {
+				calls++
+				if calls <= tc.failures {
+					return errUnavailable
+				}
+				return nil
+			}
+
+			// Act
+			err := Retry(context.Background(), tc.attempts, backoff, fn)
+
+			// Assert
+			assert.Equal(t, tc.expectedCalls, calls)
+			if tc.expectedError {
+				assert.ErrorIs(t, err, errUnavailable)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRetryStopsOnContextCancel(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	fn := func(ctx context.Context) error {
+		calls++
+		cancel()
+		return errors.New("connection refused")
+	}
+
+	// Act
+	err := Retry(ctx, 10, Backoff{Initial: time.Hour, Max: time.Hour}, fn)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIsTimeout(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "Истек срок контекста", err: fmt.Errorf("timeout: %w", context.DeadlineExceeded), expected: true},
+		{name: "Сработал statement_timeout", err: &pgconn.PgError{Code: "57014"}, expected: true},
+		{name: "Другая ошибка PostgreSQL", err: &pgconn.PgError{Code: "23505"}, expected: false},
+		{name: "Отмена клиентом", err: context.Canceled, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			result := IsTimeout(tc.err)
+
+			// Assert
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Logger передает журнал GORM в slog: ошибки запросов пишутся с уровнем error,
+// медленные запросы - с уровнем warn, остальные - с уровнем debug. Записи делаются
+// с контекстом запроса, поэтому получают его идентификатор.
+type Logger struct {
+	logger    *slog.Logger
+	slowQuery time.Duration
+	silent    bool
+}
+
+// NewLogger создает журнал GORM. slowQuery задает длительность медленного
+// запроса; 0 отключает их отдельную запись.
+func NewLogger(logger *slog.Logger, slowQuery time.Duration) *Logger {
+	return &Logger{logger: logger, slowQuery: slowQuery}
+}
+
+// LogMode возвращает копию журнала; уровень Silent отключает все записи
+func (l *Logger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.silent = level == gormlogger.Silent
+	return &copied
+}
+
+func (l *Logger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, slog.LevelInfo, fmt.Sprintf(msg, args...))
+}
+
+func (l *Logger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, slog.LevelWarn, fmt.Sprintf(msg, args...))
+}
+
+func (l *Logger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, slog.LevelError, fmt.Sprintf(msg, args...))
+}
+
+// Trace записывает выполненный SQL-запрос. Ненайденная запись ошибкой
+// не считается, а отмена запроса клиентом записывается как предупреждение.
+func (l *Logger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	level, msg := slog.LevelDebug, "SQL-запрос"
+	switch {
+	case err != nil && errors.Is(err, gorm.ErrRecordNotFound):
+	case err != nil && (errors.Is(err, context.Canceled) || IsTimeout(err)):
+		level, msg = slog.LevelWarn, "SQL-запрос прерван"
+	case err != nil:
+		level, msg = slog.LevelError, "ошибка SQL-запроса"
+	case l.slowQuery > 0 && elapsed > l.slowQuery:
+		level, msg = slog.LevelWarn, "медленный SQL-запрос"
+	}
+	if l.silent || !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if err != nil && level > slog.LevelDebug {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// ParamsFilter убирает значения параметров из записываемых запросов,
+// чтобы хеши паролей и токены не попадали в журнал
+func (l *Logger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+func (l *Logger) log(ctx context.Context, level slog.Level, msg string) {
+	if !l.silent {
+		l.logger.Log(ctx, level, msg)
+	}
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type loggedBook struct {
+	ID    uint
+	Title string
+}
+
+// openLogged открывает базу SQLite в памяти, журнал которой пишется в buf
+func openLogged(t *testing.T, level string, buf *bytes.Buffer) *gorm.DB {
+	logger := logging.New(config.LogConfig{Level: level, Format: config.LogFormatText}, buf)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: NewLogger(logger, 0)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&loggedBook{}))
+	return db
+}
+
+func TestLogger(t *testing.T) {
+	ctx := logging.WithRequestID(context.Background(), "req-7")
+
+	t.Run("Запросы на уровне debug без значений параметров", func(t *testing.T) {
+		// Arrange
+		var buf bytes.Buffer
+		db := openLogged(t, config.LogLevelDebug, &buf)
+
+		// Act
+		err := db.WithContext(ctx).Create(&loggedBook{Title: "секретное название"}).Error
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), `level=DEBUG msg=SQL-запрос sql="INSERT INTO`)
+		assert.Contains(t, buf.String(), "request_id=req-7")
+		assert.NotContains(t, buf.String(), "секретное название")
+	})
+
+	t.Run("Ненайденная запись не считается ошибкой", func(t *testing.T) {
+		// Arrange
+		var buf bytes.Buffer
+		db := openLogged(t, config.LogLevelInfo, &buf)
+
+		// Act
+		err := db.WithContext(ctx).First(&loggedBook{}, 42).Error
+
+		// Assert
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("Ошибка запроса", func(t *testing.T) {
+		// Arrange
+		var buf bytes.Buffer
+		db := openLogged(t, config.LogLevelInfo, &buf)
+
+		// Act
+		err := db.WithContext(ctx).Exec("SELECT * FROM missing_table").Error
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, buf.String(), `level=ERROR msg="ошибка SQL-запроса"`)
+		assert.Contains(t, buf.String(), "no such table")
+		assert.Contains(t, buf.String(), "request_id=req-7")
+	})
+
+	t.Run("Режим Silent", func(t *testing.T) {
+		// Arrange
+		var buf bytes.Buffer
+		db := openLogged(t, config.LogLevelDebug, &buf)
+		buf.Reset()
+
+		// Act
+		err := db.Session(&gorm.Session{Logger: db.Logger.LogMode(gormlogger.Silent)}).Exec("SELECT * FROM missing_table").Error
+
+		// Assert
+		assert.Error(t, err)
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,34 @@
+package database
+
+import "database/sql"
+
+// PoolStats представляет состояние пула соединений для мониторинга
+type PoolStats struct {
+	// MaxOpenConnections - ограничение числа соединений; 0 означает отсутствие ограничения
+	MaxOpenConnections int `json:"max_open_connections"`
+	OpenConnections    int `json:"open_connections"`
+	InUse              int `json:"in_use"`
+	Idle               int `json:"idle"`
+	// WaitCount и WaitDurationMs показывают, сколько раз и как долго запросы
+	// ждали свободного соединения. Рост значений говорит о нехватке пула.
+	WaitCount         int64 `json:"wait_count"`
+	WaitDurationMs    int64 `json:"wait_duration_ms"`
+	MaxIdleClosed     int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64 `json:"max_lifetime_closed"`
+}
+
+// NewPoolStats преобразует статистику database/sql
+func NewPoolStats(s sql.DBStats) PoolStats {
+	return PoolStats{
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDurationMs:     s.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      s.MaxIdleClosed,
+		MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  s.MaxLifetimeClosed,
+	}
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// tracingSpanKey - ключ, под которым спан запроса хранится в gorm.Statement
+const tracingSpanKey = "tracing:span"
+
+// Tracing - плагин GORM, создающий клиентский спан для каждого SQL-запроса.
+// Спан получает текст запроса только с плейсхолдерами: значения параметров,
+// например хеши паролей, в трассу не попадают.
+type Tracing struct {
+	tracer trace.Tracer
+}
+
+// NewTracing создает плагин трассировки с глобальным провайдером спанов
+func NewTracing() *Tracing {
+	return &Tracing{tracer: otel.Tracer("github.com/krawwwwy/book-library-api/internal/database")}
+}
+
+func (t *Tracing) Name() string {
+	return "tracing"
+}
+
+// Initialize регистрирует обработчики до и после каждой операции GORM
+func (t *Tracing) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", t.start("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", t.end),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", t.start("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", t.end),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", t.start("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", t.end),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", t.start("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", t.end),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", t.start("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", t.end),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", t.start("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", t.end),
+	)
+}
+
+func (t *Tracing) start(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		name := operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		_, span := t.tracer.Start(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				dbSystem(db.Dialector.Name()),
+				semconv.DBOperation(operation),
+			),
+		)
+		if db.Statement.Table != "" {
+			span.SetAttributes(semconv.DBSQLTable(db.Statement.Table))
+		}
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+func (t *Tracing) end(db *gorm.DB) {
+	value, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBStatement(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+func dbSystem(dialector string) attribute.KeyValue {
+	switch dialector {
+	case "postgres":
+		return semconv.DBSystemPostgreSQL
+	case "sqlite":
+		return semconv.DBSystemSqlite
+	default:
+		return semconv.DBSystemKey.String(dialector)
+	}
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// openTraced открывает базу SQLite в памяти с плагином трассировки,
+// спаны которого записываются в recorder
+func openTraced(t *testing.T) (*gorm.DB, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Discard})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&loggedBook{}))
+	require.NoError(t, db.Use(NewTracing()))
+	return db, recorder
+}
+
+func TestTracing(t *testing.T) {
+	t.Run("Спан запроса без значений параметров", func(t *testing.T) {
+		// Arrange
+		db, recorder := openTraced(t)
+		ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+
+		// Act
+		err := db.WithContext(ctx).Create(&loggedBook{Title: "секретное название"}).Error
+		parent.End()
+
+		// Assert
+		require.NoError(t, err)
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		span := spans[0]
+		assert.Equal(t, "create logged_books", span.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Contains(t, span.Attributes(), attribute.String("db.system", "sqlite"))
+		assert.Contains(t, span.Attributes(), attribute.String("db.sql.table", "logged_books"))
+		assert.Contains(t, span.Attributes(), attribute.Int64("db.rows_affected", 1))
+		var statement string
+		for _, attr := range span.Attributes() {
+			if attr.Key == "db.statement" {
+				statement = attr.Value.AsString()
+			}
+		}
+		assert.Contains(t, statement, "INSERT INTO `logged_books`")
+		assert.NotContains(t, statement, "секретное название")
+	})
+
+	t.Run("Ненайденная запись не считается ошибкой", func(t *testing.T) {
+		// Arrange
+		db, recorder := openTraced(t)
+
+		// Act
+		err := db.First(&loggedBook{}, 42).Error
+
+		// Assert
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "query logged_books", spans[0].Name())
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	})
+
+	t.Run("Ошибка запроса", func(t *testing.T) {
+		// Arrange
+		db, recorder := openTraced(t)
+
+		// Act
+		err := db.Exec("SELECT * FROM missing_table").Error
+
+		// Assert
+		assert.Error(t, err)
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "raw", spans[0].Name())
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Contains(t, spans[0].Status().Description, "no such table")
+	})
+}
@@ -0,0 +1,166 @@
+// Package health проверяет состояние сервиса и его зависимостей для
+// эндпоинтов /healthz, /readyz и /api/admin/status
+package health
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Состояния сервиса и зависимостей
+const (
+	StatusOK           = "ok"
+	StatusFailing      = "failing"
+	StatusShuttingDown = "shutting_down"
+)
+
+// checkTimeout ограничивает время одной проверки, чтобы зависшая зависимость
+// не задерживала ответ пробе дольше ее собственного таймаута
+const checkTimeout = 2 * time.Second
+
+// Check проверяет одну зависимость и возвращает ошибку, если она недоступна
+type Check func(ctx context.Context) error
+
+// DependencyStatus представляет результат проверки зависимости
+type DependencyStatus struct {
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// Report представляет результат проверки готовности
+type Report struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+// Ready проверяет, может ли сервис принимать запросы
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// BuildInfo представляет сведения о сборке
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// ReadBuildInfo возвращает сведения о сборке. Версия задается при сборке
+// через -ldflags, коммит и время берутся из данных системы контроля версий,
+// которые go build встраивает в исполняемый файл.
+func ReadBuildInfo(version string) BuildInfo {
+	info := BuildInfo{Version: version, GoVersion: runtime.Version()}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			info.BuildTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// Checker выполняет проверки зависимостей и хранит признак остановки сервиса
+type Checker struct {
+	build        BuildInfo
+	startedAt    time.Time
+	names        []string
+	checks       map[string]Check
+	shuttingDown atomic.Bool
+}
+
+// NewChecker создает новый экземпляр Checker
+func NewChecker(build BuildInfo) *Checker {
+	return &Checker{build: build, startedAt: time.Now(), checks: make(map[string]Check)}
+}
+
+// Add добавляет проверку зависимости с именем name
+func (c *Checker) Add(name string, check Check) {
+	c.names = append(c.names, name)
+	c.checks[name] = check
+}
+
+// Shutdown отмечает начало остановки: с этого момента сервис не готов
+// принимать запросы, хотя текущие еще обрабатываются
+func (c *Checker) Shutdown() {
+	c.shuttingDown.Store(true)
+}
+
+// ShuttingDown проверяет, началась ли остановка сервиса
+func (c *Checker) ShuttingDown() bool {
+	return c.shuttingDown.Load()
+}
+
+// Check выполняет все проверки параллельно. Сервис готов, если все
+// зависимости доступны и остановка не началась.
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Dependencies: make(map[string]DependencyStatus, len(c.names))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range c.names {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			status := run(ctx, check)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Dependencies[name] = status
+		}(name, c.checks[name])
+	}
+	wg.Wait()
+
+	for _, dep := range report.Dependencies {
+		if dep.Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+	if c.ShuttingDown() {
+		report.Status = StatusShuttingDown
+	}
+	return report
+}
+
+// Status представляет подробное состояние сервиса
+type Status struct {
+	Report
+	Build         BuildInfo `json:"build"`
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+}
+
+// Status возвращает подробное состояние сервиса со сведениями о сборке
+func (c *Checker) Status(ctx context.Context) Status {
+	return Status{
+		Report:        c.Check(ctx),
+		Build:         c.build,
+		StartedAt:     c.startedAt.UTC(),
+		UptimeSeconds: int64(time.Since(c.startedAt).Seconds()),
+	}
+}
+
+func run(ctx context.Context, check Check) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	start := time.Now()
+	err := check(ctx)
+	status := DependencyStatus{Status: StatusOK, DurationMS: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		status.Status = StatusFailing
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("соединение отклонено") }
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	testCases := []struct {
+		name           string
+		checks         map[string]Check
+		shutdown       bool
+		expectedStatus string
+		expectedDeps   map[string]string
+	}{
+		{
+			name:           "Все зависимости доступны",
+			checks:         map[string]Check{"database": ok, "migrations": ok},
+			expectedStatus: StatusOK,
+			expectedDeps:   map[string]string{"database": StatusOK, "migrations": StatusOK},
+		},
+		{
+			name:           "Зависимость недоступна",
+			checks:         map[string]Check{"database": failing, "migrations": ok},
+			expectedStatus: StatusFailing,
+			expectedDeps:   map[string]string{"database": StatusFailing, "migrations": StatusOK},
+		},
+		{
+			name:           "Остановка сервиса",
+			checks:         map[string]Check{"database": ok},
+			shutdown:       true,
+			expectedStatus: StatusShuttingDown,
+			expectedDeps:   map[string]string{"database": StatusOK},
+		},
+		{
+			name:           "Без проверок",
+			expectedStatus: StatusOK,
+			expectedDeps:   map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			checker := NewChecker(BuildInfo{Version: "test"})
+			for name, check := range tc.checks {
+				checker.Add(name, check)
+			}
+			if tc.shutdown {
+				checker.Shutdown()
+			}
+
+			// Act
+			report := checker.Check(context.Background())
+
+			// Assert
+			assert.Equal(t, tc.expectedStatus, report.Status)
+			assert.Equal(t, tc.expectedStatus == StatusOK, report.Ready())
+			deps := make(map[string]string, len(report.Dependencies))
+			for name, dep := range report.Dependencies {
+				deps[name] = dep.Status
+			}
+			assert.Equal(t, tc.expectedDeps, deps)
+		})
+	}
+
+	t.Run("Текст ошибки и таймаут проверки", func(t *testing.T) {
+		// Arrange
+		checker := NewChecker(BuildInfo{Version: "test"})
+		checker.Add("database", failing)
+		checker.Add("cache", hanging)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// Act
+		start := time.Now()
+		report := checker.Check(ctx)
+
+		// Assert
+		assert.Less(t, time.Since(start), checkTimeout)
+		assert.Equal(t, "соединение отклонено", report.Dependencies["database"].Error)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Dependencies["cache"].Error)
+	})
+}
+
+func TestStatus(t *testing.T) {
+	// Arrange
+	checker := NewChecker(ReadBuildInfo("1.4.0"))
+	checker.startedAt = time.Now().Add(-90 * time.Second)
+
+	// Act
+	status := checker.Status(context.Background())
+
+	// Assert
+	assert.Equal(t, StatusOK, status.Status)
+	assert.Equal(t, "1.4.0", status.Build.Version)
+	assert.NotEmpty(t, status.Build.GoVersion)
+	assert.EqualValues(t, 90, status.UptimeSeconds)
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cfg      config.LogConfig
+		ctx      context.Context
+		expected map[string]interface{}
+	}{
+		{
+			name:     "Идентификатор запроса из контекста",
+			cfg:      config.LogConfig{Level: config.LogLevelInfo, Format: config.LogFormatJSON},
+			ctx:      WithRequestID(context.Background(), "req-1"),
+			expected: map[string]interface{}{"level": "INFO", "msg": "книга создана", "book_id": float64(7), RequestIDKey: "req-1"},
+		},
+		{
+			name: "Идентификаторы трассы и спана",
+			cfg:  config.LogConfig{Level: config.LogLevelInfo, Format: config.LogFormatJSON},
+			ctx: trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    trace.TraceID{0x4b, 0xf9},
+				SpanID:     trace.SpanID{0x00, 0xf0},
+				TraceFlags: trace.FlagsSampled,
+			})),
+			expected: map[string]interface{}{
+				"level": "INFO", "msg": "книга создана", "book_id": float64(7),
+				TraceIDKey: "4bf90000000000000000000000000000", SpanIDKey: "00f0000000000000",
+			},
+		},
+		{
+			name:     "Контекст без идентификатора",
+			cfg:      config.LogConfig{Level: config.LogLevelInfo, Format: config.LogFormatJSON},
+			ctx:      context.Background(),
+			expected: map[string]interface{}{"level": "INFO", "msg": "книга создана", "book_id": float64(7)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			logger := New(tc.cfg, &buf).With("book_id", 7)
+
+			// Act
+			logger.InfoContext(tc.ctx, "книга создана")
+
+			// Assert
+			var record map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			delete(record, "time")
+			assert.Equal(t, tc.expected, record)
+		})
+	}
+}
+
+func TestNewLevelAndFormat(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := New(config.LogConfig{Level: config.LogLevelWarn, Format: config.LogFormatText}, &buf)
+	ctx := WithRequestID(context.Background(), "req-2")
+
+	// Act
+	logger.InfoContext(ctx, "пропускается")
+	logger.WarnContext(ctx, "записывается")
+
+	// Assert
+	assert.NotContains(t, buf.String(), "пропускается")
+	assert.Contains(t, buf.String(), "level=WARN msg=записывается request_id=req-2")
+}
+
+func TestParseLevel(t *testing.T) {
+	// Act & Assert
+	assert.Equal(t, slog.LevelDebug, ParseLevel("debug"))
+	assert.Equal(t, slog.LevelWarn, ParseLevel("WARN"))
+	assert.Equal(t, slog.LevelError, ParseLevel("error"))
+	assert.Equal(t, slog.LevelInfo, ParseLevel(""))
+}
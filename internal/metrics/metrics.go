@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace добавляется к именам всех метрик сервиса
+const namespace = "book_library"
+
+// Metrics хранит метрики сервиса в собственном реестре Prometheus.
+// Реализует middleware.RequestObserver и service.BookEvents.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	queryErrors     *prometheus.CounterVec
+
+	booksCreated prometheus.Counter
+	checkouts    prometheus.Counter
+	returns      prometheus.Counter
+	searches     *prometheus.CounterVec
+}
+
+// New создает метрики и регистрирует вместе с ними метрики среды выполнения Go и процесса
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Число обработанных HTTP-запросов.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Время обработки HTTP-запросов.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Время выполнения методов хранилища.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"repository", "method"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "db_query_errors_total",
+			Help:      "Число ошибок методов хранилища, кроме ненайденных записей.",
+		}, []string{"repository", "method"}),
+		booksCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "books_created_total",
+			Help:      "Число созданных книг.",
+		}),
+		checkouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "book_checkouts_total",
+			Help:      "Число выдач книг.",
+		}),
+		returns: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "book_returns_total",
+			Help:      "Число возвратов книг.",
+		}),
+		searches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "book_searches_total",
+			Help:      "Число поисков книг; result=empty для поисков без результатов.",
+		}, []string{"result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.requestDuration, m.queryDuration, m.queryErrors,
+		m.booksCreated, m.checkouts, m.returns, m.searches,
+	)
+	return m
+}
+
+// RegisterDB добавляет статистику пула соединений с базой данных (метрики go_sql_*)
+func (m *Metrics) RegisterDB(db *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// Handler возвращает обработчик, отдающий метрики в формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest учитывает обработанный HTTP-запрос
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(method, route, code).Inc()
+	m.requestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// observeQuery учитывает вызов метода хранилища
+func (m *Metrics) observeQuery(repository, method string, start time.Time, failed bool) {
+	m.queryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	if failed {
+		m.queryErrors.WithLabelValues(repository, method).Inc()
+	}
+}
+
+// BookCreated учитывает созданную книгу
+func (m *Metrics) BookCreated() {
+	m.booksCreated.Inc()
+}
+
+// BookCheckedOut учитывает выдачу книги
+func (m *Metrics) BookCheckedOut() {
+	m.checkouts.Inc()
+}
+
+// BookReturned учитывает возврат книги
+func (m *Metrics) BookReturned() {
+	m.returns.Inc()
+}
+
+// SearchCompleted учитывает поиск книг с указанным числом результатов
+func (m *Metrics) SearchCompleted(results int) {
+	result := "found"
+	if results == 0 {
+		result = "empty"
+	}
+	m.searches.WithLabelValues(result).Inc()
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scrape возвращает метрики в текстовом формате Prometheus
+func scrape(t *testing.T, m *Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	// Arrange
+	m := New()
+
+	// Act
+	m.ObserveRequest(http.MethodGet, "/api/books/:id", http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "/api/books/:id", http.StatusNotFound, time.Millisecond)
+	m.BookCreated()
+	m.BookCheckedOut()
+	m.BookReturned()
+	m.SearchCompleted(0)
+	m.SearchCompleted(3)
+	m.SearchCompleted(0)
+	output := scrape(t, m)
+
+	// Assert
+	expected := []string{
+		`book_library_http_requests_total{method="GET",route="/api/books/:id",status="200"} 1`,
+		`book_library_http_requests_total{method="GET",route="/api/books/:id",status="404"} 1`,
+		`book_library_http_request_duration_seconds_count{method="GET",route="/api/books/:id",status="200"} 1`,
+		`book_library_books_created_total 1`,
+		`book_library_book_checkouts_total 1`,
+		`book_library_book_returns_total 1`,
+		`book_library_book_searches_total{result="empty"} 2`,
+		`book_library_book_searches_total{result="found"} 1`,
+		`go_goroutines`,
+	}
+	for _, line := range expected {
+		assert.Contains(t, output, line)
+	}
+}
+
+func TestBookStore(t *testing.T) {
+	// Arrange
+	m := New()
+	memory := repository.NewMemoryBookStore()
+	store := m.BookStore(memory)
+	revisions := m.RevisionStore(memory)
+	ctx := context.Background()
+
+	// Act
+	err := store.Transaction(ctx, func(tx repository.BookStore) error {
+		return tx.Create(ctx, &model.Book{Title: "Книга", Author: "Автор", ISBN: "1111111111"})
+	})
+	require.NoError(t, err)
+	_, notFoundErr := store.GetByID(ctx, 999)
+	_, revisionErr := revisions.GetByBookID(ctx, 1)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, canceledErr := store.Search(canceled, "Книга")
+	output := scrape(t, m)
+
+	// Assert
+	assert.Error(t, notFoundErr)
+	assert.NoError(t, revisionErr)
+	assert.Error(t, canceledErr)
+	for _, line := range []string{
+		`book_library_db_query_duration_seconds_count{method="Transaction",repository="book"} 1`,
+		`book_library_db_query_duration_seconds_count{method="Create",repository="book"} 1`,
+		`book_library_db_query_duration_seconds_count{method="GetByID",repository="book"} 1`,
+		`book_library_db_query_duration_seconds_count{method="GetByBookID",repository="revision"} 1`,
+		`book_library_db_query_errors_total{method="Search",repository="book"} 1`,
+	} {
+		assert.Contains(t, output, line)
+	}
+	assert.NotContains(t, output, `book_library_db_query_errors_total{method="GetByID"`, "ненайденная запись не считается ошибкой")
+}
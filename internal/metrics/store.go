@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"gorm.io/gorm"
+)
+
+// BookStore оборачивает хранилище книг, измеряя время выполнения каждого метода
+func (m *Metrics) BookStore(store repository.BookStore) repository.BookStore {
+	return &bookStore{store: store, metrics: m}
+}
+
+// RevisionStore оборачивает хранилище ревизий, измеряя время выполнения каждого метода
+func (m *Metrics) RevisionStore(store repository.RevisionStore) repository.RevisionStore {
+	return &revisionStore{store: store, metrics: m}
+}
+
+// observe учитывает вызов метода; ненайденная запись ошибкой хранилища не считается
+func (m *Metrics) observe(repository, method string, start time.Time, err error) {
+	m.observeQuery(repository, method, start, err != nil && !errors.Is(err, gorm.ErrRecordNotFound))
+}
+
+type bookStore struct {
+	store   repository.BookStore
+	metrics *Metrics
+}
+
+func (s *bookStore) Create(ctx context.Context, book *model.Book) error {
+	start := time.Now()
+	err := s.store.Create(ctx, book)
+	s.metrics.observe("book", "Create", start, err)
+	return err
+}
+
+func (s *bookStore) GetByID(ctx context.Context, id uint) (*model.Book, error) {
+	start := time.Now()
+	book, err := s.store.GetByID(ctx, id)
+	s.metrics.observe("book", "GetByID", start, err)
+	return book, err
+}
+
+func (s *bookStore) GetAll(ctx context.Context, page, pageSize int) ([]model.Book, error) {
+	start := time.Now()
+	books, err := s.store.GetAll(ctx, page, pageSize)
+	s.metrics.observe("book", "GetAll", start, err)
+	return books, err
+}
+
+func (s *bookStore) Update(ctx context.Context, book *model.Book) error {
+	start := time.Now()
+	err := s.store.Update(ctx, book)
+	s.metrics.observe("book", "Update", start, err)
+	return err
+}
+
+func (s *bookStore) Delete(ctx context.Context, id uint) error {
+	start := time.Now()
+	err := s.store.Delete(ctx, id)
+	s.metrics.observe("book", "Delete", start, err)
+	return err
+}
+
+func (s *bookStore) GetByISBN(ctx context.Context, isbn string) (*model.Book, error) {
+	start := time.Now()
+	book, err := s.store.GetByISBN(ctx, isbn)
+	s.metrics.observe("book", "GetByISBN", start, err)
+	return book, err
+}
+
+func (s *bookStore) GetByISBNs(ctx context.Context, isbns []string) ([]model.Book, error) {
+	start := time.Now()
+	books, err := s.store.GetByISBNs(ctx, isbns)
+	s.metrics.observe("book", "GetByISBNs", start, err)
+	return books, err
+}
+
+func (s *bookStore) GetByIDs(ctx context.Context, ids []uint) ([]model.Book, error) {
+	start := time.Now()
+	books, err := s.store.GetByIDs(ctx, ids)
+	s.metrics.observe("book", "GetByIDs", start, err)
+	return books, err
+}
+
+// Transaction измеряет транзакцию целиком, а методы внутри нее - по отдельности
+func (s *bookStore) Transaction(ctx context.Context, fn func(store repository.BookStore) error) error {
+	start := time.Now()
+	err := s.store.Transaction(ctx, func(store repository.BookStore) error {
+		return fn(&bookStore{store: store, metrics: s.metrics})
+	})
+	s.metrics.observe("book", "Transaction", start, err)
+	return err
+}
+
+// FindInBatches измеряет выгрузку целиком, включая обработку порций в fn
+func (s *bookStore) FindInBatches(ctx context.Context, filter *model.BookFilter, batchSize int, fn func(books []model.Book) error) error {
+	start := time.Now()
+	err := s.store.FindInBatches(ctx, filter, batchSize, fn)
+	s.metrics.observe("book", "FindInBatches", start, err)
+	return err
+}
+
+func (s *bookStore) ListByUpdatedAt(ctx context.Context, filter *model.BookFilter, afterUpdatedAt time.Time, afterID uint, limit int) ([]model.Book, error) {
+	start := time.Now()
+	books, err := s.store.ListByUpdatedAt(ctx, filter, afterUpdatedAt, afterID, limit)
+	s.metrics.observe("book", "ListByUpdatedAt", start, err)
+	return books, err
+}
+
+func (s *bookStore) Count(ctx context.Context, filter *model.BookFilter) (int64, error) {
+	start := time.Now()
+	count, err := s.store.Count(ctx, filter)
+	s.metrics.observe("book", "Count", start, err)
+	return count, err
+}
+
+func (s *bookStore) EarliestUpdatedAt(ctx context.Context) (time.Time, error) {
+	start := time.Now()
+	earliest, err := s.store.EarliestUpdatedAt(ctx)
+	s.metrics.observe("book", "EarliestUpdatedAt", start, err)
+	return earliest, err
+}
+
+func (s *bookStore) Search(ctx context.Context, query string) ([]model.Book, error) {
+	start := time.Now()
+	books, err := s.store.Search(ctx, query)
+	s.metrics.observe("book", "Search", start, err)
+	return books, err
+}
+
+type revisionStore struct {
+	store   repository.RevisionStore
+	metrics *Metrics
+}
+
+func (s *revisionStore) GetByBookID(ctx context.Context, bookID uint) ([]model.BookRevision, error) {
+	start := time.Now()
+	revisions, err := s.store.GetByBookID(ctx, bookID)
+	s.metrics.observe("revision", "GetByBookID", start, err)
+	return revisions, err
+}
+
+func (s *revisionStore) GetByRevision(ctx context.Context, bookID uint, revision int) (*model.BookRevision, error) {
+	start := time.Now()
+	rev, err := s.store.GetByRevision(ctx, bookID, revision)
+	s.metrics.observe("revision", "GetByRevision", start, err)
+	return rev, err
+}
@@ -0,0 +1,108 @@
+// Package middleware содержит промежуточные обработчики Gin
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// principalKey задает ключ пользователя запроса в контексте Gin
+const principalKey = "principal"
+
+// TokenVerifier проверяет access-токен и возвращает его владельца
+type TokenVerifier interface {
+	VerifyAccessToken(ctx context.Context, token string) (*model.Principal, error)
+}
+
+// KeyVerifier проверяет API-ключ и возвращает его как пользователя запроса
+type KeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (*model.Principal, error)
+}
+
+// Authenticate проверяет заголовок Authorization и сохраняет пользователя в контексте
+// Gin и в контексте запроса, по которому права проверяет слой сервисов.
+// Поддерживаются схемы Bearer (access-токен) и ApiKey (API-ключ интеграции).
+// Запросы без заголовка пропускаются анонимными; недействительные учетные данные отклоняются с 401.
+func Authenticate(tokens TokenVerifier, keys KeyVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		scheme, credentials, _ := strings.Cut(header, " ")
+		credentials = strings.TrimSpace(credentials)
+		if credentials == "" {
+			unauthorized(c, "не указаны учетные данные")
+			return
+		}
+
+		var principal *model.Principal
+		var err error
+		switch {
+		case strings.EqualFold(scheme, "Bearer"):
+			principal, err = tokens.VerifyAccessToken(c.Request.Context(), credentials)
+		case strings.EqualFold(scheme, "ApiKey"):
+			principal, err = keys.VerifyAPIKey(c.Request.Context(), credentials)
+		default:
+			unauthorized(c, "неподдерживаемая схема авторизации")
+			return
+		}
+		if err != nil {
+			unauthorized(c, err.Error())
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Request = c.Request.WithContext(model.ContextWithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// RequireAuth отклоняет анонимные запросы с 401
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := CurrentPrincipal(c); !ok {
+			unauthorized(c, "требуется аутентификация")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission отклоняет анонимные запросы с 401, а запросы пользователей
+// без указанного права - с 403
+func RequirePermission(permission model.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			unauthorized(c, "требуется аутентификация")
+			return
+		}
+		if !principal.Can(permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrorBody(c, "недостаточно прав"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// CurrentPrincipal возвращает аутентифицированного пользователя запроса
+func CurrentPrincipal(c *gin.Context) (*model.Principal, bool) {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := value.(*model.Principal)
+	return principal, ok
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="book-library-api", ApiKey realm="book-library-api"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorBody(c, message))
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubVerifier принимает токен администратора "valid" и токен читателя "patron"
+type stubVerifier struct{}
+
+func (stubVerifier) VerifyAccessToken(ctx context.Context, token string) (*model.Principal, error) {
+	if token == "patron" {
+		return &model.Principal{UserID: 2, Username: "reader", Role: model.RolePatron}, nil
+	}
+	if token != "valid" {
+		return nil, errors.New("недействительный токен")
+	}
+	return &model.Principal{UserID: 1, Username: "admin", Role: model.RoleAdmin}, nil
+}
+
+// stubKeyVerifier принимает единственный ключ "blk_kiosk" с правом чтения каталога
+type stubKeyVerifier struct{}
+
+func (stubKeyVerifier) VerifyAPIKey(ctx context.Context, key string) (*model.Principal, error) {
+	if key != "blk_kiosk" {
+		return nil, errors.New("недействительный API-ключ")
+	}
+	return &model.Principal{Username: "apikey:kiosk", APIKeyID: 1, Scopes: []model.Permission{model.PermissionReadBooks}}, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name           string
+		path           string
+		header         string
+		expectedStatus int
+	}{
+		{name: "Анонимный доступ к открытому маршруту", path: "/public", expectedStatus: http.StatusOK},
+		{name: "Анонимный доступ к защищенному маршруту", path: "/private", expectedStatus: http.StatusUnauthorized},
+		{name: "Действительный токен", path: "/private", header: "Bearer valid", expectedStatus: http.StatusOK},
+		{name: "Схема без учета регистра", path: "/private", header: "bearer valid", expectedStatus: http.StatusOK},
+		{name: "Недействительный токен на открытом маршруте", path: "/public", header: "Bearer broken", expectedStatus: http.StatusUnauthorized},
+		{name: "Неподдерживаемая схема", path: "/private", header: "Basic YWRtaW46YWRtaW4=", expectedStatus: http.StatusUnauthorized},
+		{name: "Пустые учетные данные", path: "/public", header: "Bearer ", expectedStatus: http.StatusUnauthorized},
+		{name: "Действительный API-ключ", path: "/public", header: "ApiKey blk_kiosk", expectedStatus: http.StatusOK},
+		{name: "Недействительный API-ключ", path: "/public", header: "ApiKey blk_other", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			router := gin.New()
+			router.Use(Authenticate(stubVerifier{}, stubKeyVerifier{}))
+			router.GET("/public", func(c *gin.Context) { c.Status(http.StatusOK) })
+			router.GET("/private", RequireAuth(), func(c *gin.Context) {
+				principal, ok := CurrentPrincipal(c)
+				assert.True(t, ok)
+				assert.Equal(t, "admin", principal.Username)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name           string
+		header         string
+		expectedStatus int
+	}{
+		{name: "Анонимный пользователь", expectedStatus: http.StatusUnauthorized},
+		{name: "Недостаточно прав", header: "Bearer patron", expectedStatus: http.StatusForbidden},
+		{name: "Администратор", header: "Bearer valid", expectedStatus: http.StatusOK},
+		{name: "API-ключ без области", header: "ApiKey blk_kiosk", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			router := gin.New()
+			router.Use(Authenticate(stubVerifier{}, stubKeyVerifier{}))
+			router.DELETE("/books/1", RequirePermission(model.PermissionDeleteBooks), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodDelete, "/books/1", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
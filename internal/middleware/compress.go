@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// compressMinSize - размер ответа, начиная с которого он сжимается.
+// Сжатие коротких ответов не уменьшает их заметно, но тратит процессор.
+const compressMinSize = 1024
+
+// brotliLevel задает уровень сжатия brotli: на уровне 4 ответы получаются
+// меньше, чем у gzip, при сопоставимом времени сжатия
+const brotliLevel = 4
+
+// Кодировки сжатия
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// encoder сжимает тело ответа; реализуется gzip.Writer и brotli.Writer
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	encodingBrotli: {New: func() any { return brotli.NewWriterLevel(io.Discard, brotliLevel) }},
+	encodingGzip:   {New: func() any { return gzip.NewWriter(io.Discard) }},
+}
+
+// Compress сжимает ответы gzip или brotli в зависимости от заголовка Accept-Encoding;
+// при равном весе выбирается brotli. Сжимаются текстовые форматы (JSON, XML, CSV,
+// MARC, HTML, CSS, JS) размером от 1 КиБ; ответы, отданные частично (206) или уже
+// сжатые обработчиком, передаются как есть. Потоковые ответы, например выгрузка
+// каталога, сжимаются по мере записи. При панике обработчика накопленное тело
+// отбрасывается, чтобы Recovery мог ответить 500.
+func Compress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding}
+		c.Writer = writer
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			if recovered := recover(); recovered != nil {
+				writer.discard()
+				panic(recovered)
+			}
+			writer.close()
+		}()
+		c.Next()
+	}
+}
+
+// negotiateEncoding выбирает кодировку по заголовку Accept-Encoding с учетом весов q.
+// Пустая строка означает ответ без сжатия.
+func negotiateEncoding(header string) string {
+	weights := map[string]float64{}
+	wildcard, hasWildcard := 0.0, false
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if coding == "*" {
+			wildcard, hasWildcard = q, true
+			continue
+		}
+		weights[coding] = q
+	}
+
+	weight := func(coding string) float64 {
+		if q, ok := weights[coding]; ok {
+			return q
+		}
+		if hasWildcard {
+			return wildcard
+		}
+		return 0
+	}
+	br, gz := weight(encodingBrotli), weight(encodingGzip)
+	switch {
+	case br > 0 && br >= gz:
+		return encodingBrotli
+	case gz > 0:
+		return encodingGzip
+	default:
+		return ""
+	}
+}
+
+// compressible проверяет, имеет ли смысл сжимать содержимое такого типа
+func compressible(contentType string) bool {
+	mt := mediaType(contentType)
+	switch {
+	case strings.HasPrefix(mt, "text/"):
+		return true
+	case strings.HasSuffix(mt, "/json"), strings.HasSuffix(mt, "+json"),
+		strings.HasSuffix(mt, "/xml"), strings.HasSuffix(mt, "+xml"):
+		return true
+	}
+	switch mt {
+	case "application/javascript", "application/marc":
+		return true
+	}
+	return false
+}
+
+// compressWriter накапливает начало тела ответа, пока не станет ясно, нужно ли
+// его сжимать, а затем пишет тело через encoder или без изменений
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	buf      []byte
+	decided  bool
+	encoder  encoder
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.write(data)
+	}
+	w.buf = append(w.buf, data...)
+	if len(w.buf) >= compressMinSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow отправляет заголовки сразу, поэтому тело после этого не сжимается
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush отправляет накопленные данные; потоковый ответ сжимается независимо от размера
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(true)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// decide выбирает, сжимать ли ответ, и пишет накопленное начало тела
+func (w *compressWriter) decide(large bool) error {
+	w.decided = true
+	header := w.Header()
+	status := w.Status()
+	if large && header.Get("Content-Encoding") == "" && header.Get("Content-Range") == "" &&
+		status != http.StatusPartialContent && status != http.StatusNoContent && status != http.StatusNotModified &&
+		compressible(header.Get("Content-Type")) {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", w.encoding)
+		w.encoder = encoderPools[w.encoding].Get().(encoder)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.write(buf)
+	return err
+}
+
+func (w *compressWriter) write(data []byte) (int, error) {
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// close дописывает тело ответа и возвращает encoder в пул
+func (w *compressWriter) close() {
+	if !w.decided {
+		_ = w.decide(len(w.buf) >= compressMinSize)
+	}
+	if w.encoder == nil {
+		return
+	}
+	_ = w.encoder.Close()
+	w.release()
+}
+
+// discard отбрасывает незаписанное тело ответа. Поток encoder не закрывается,
+// чтобы уже начатый сжатый ответ не выглядел у клиента завершенным.
+func (w *compressWriter) discard() {
+	w.buf = nil
+	w.decided = true
+	if w.encoder != nil {
+		w.release()
+	}
+}
+
+// release возвращает encoder в пул
+func (w *compressWriter) release() {
+	w.encoder.Reset(io.Discard)
+	encoderPools[w.encoding].Put(w.encoder)
+	w.encoder = nil
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{name: "Без заголовка", header: "", expected: ""},
+		{name: "Только gzip", header: "gzip", expected: encodingGzip},
+		{name: "brotli при равном весе", header: "gzip, deflate, br", expected: encodingBrotli},
+		{name: "Вес в пользу gzip", header: "br;q=0.5, gzip;q=0.8", expected: encodingGzip},
+		{name: "Запрет brotli", header: "br;q=0, gzip", expected: encodingGzip},
+		{name: "Любая кодировка", header: "*", expected: encodingBrotli},
+		{name: "Любая кодировка, кроме brotli", header: "*;q=0.5, br;q=0", expected: encodingGzip},
+		{name: "Только identity", header: "identity", expected: ""},
+		{name: "Неизвестная кодировка", header: "deflate", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			encoding := negotiateEncoding(tc.header)
+
+			// Assert
+			assert.Equal(t, tc.expected, encoding)
+		})
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"title":"Война и мир","author":"Лев Толстой"}`, 100)
+	testCases := []struct {
+		name             string
+		method           string
+		acceptEncoding   string
+		handler          gin.HandlerFunc
+		expectedEncoding string
+		expectedBody     string
+	}{
+		{name: "gzip для JSON", acceptEncoding: "gzip", handler: func(c *gin.Context) {
+			c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(large))
+		}, expectedEncoding: encodingGzip, expectedBody: large},
+		{name: "brotli для JSON", acceptEncoding: "gzip, br", handler: func(c *gin.Context) {
+			c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(large))
+		}, expectedEncoding: encodingBrotli, expectedBody: large},
+		{name: "Потоковый ответ по частям", acceptEncoding: "gzip", handler: func(c *gin.Context) {
+			c.Header("Content-Type", "text/csv")
+			for i := 0; i < 100; i++ {
+				_, _ = c.Writer.WriteString("Война и мир;Лев Толстой\n")
+				if i%50 == 49 {
+					c.Writer.Flush()
+				}
+			}
+		}, expectedEncoding: encodingGzip, expectedBody: strings.Repeat("Война и мир;Лев Толстой\n", 100)},
+		{name: "Короткий ответ", acceptEncoding: "gzip", handler: func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		}, expectedBody: `{"status":"ok"}`},
+		{name: "Клиент не поддерживает сжатие", handler: func(c *gin.Context) {
+			c.Data(http.StatusOK, "application/json", []byte(large))
+		}, expectedBody: large},
+		{name: "Несжимаемый тип", acceptEncoding: "gzip", handler: func(c *gin.Context) {
+			c.Data(http.StatusOK, "image/png", []byte(large))
+		}, expectedBody: large},
+		{name: "Частичный ответ", acceptEncoding: "gzip", handler: func(c *gin.Context) {
+			c.Header("Content-Range", "bytes 0-4599/10000")
+			c.Data(http.StatusPartialContent, "text/plain", []byte(large))
+		}, expectedBody: large},
+		{name: "Запрос HEAD", method: http.MethodHead, acceptEncoding: "gzip", handler: func(c *gin.Context) {
+			c.Header("Content-Type", "application/json")
+			c.Status(http.StatusOK)
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(Compress())
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			router.Handle(method, "/", tc.handler)
+			req := httptest.NewRequest(method, "/", nil)
+			if tc.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedEncoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			assert.Equal(t, tc.expectedBody, decompress(t, tc.expectedEncoding, w.Body.Bytes()))
+			if tc.expectedEncoding != "" {
+				assert.Less(t, w.Body.Len(), len(tc.expectedBody))
+			}
+		})
+	}
+}
+
+func TestCompressPanic(t *testing.T) {
+	// Arrange: обработчик успел записать начало ответа и упал
+	var buf bytes.Buffer
+	logger := logging.New(config.LogConfig{Level: config.LogLevelInfo, Format: config.LogFormatJSON}, &buf)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Recovery(logger), Compress())
+	router.GET("/", func(c *gin.Context) {
+		c.Header("Content-Type", "application/json")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString(`{"title":"Война и мир"`)
+		panic("сбой")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.JSONEq(t, `{"error":"внутренняя ошибка сервера"}`, w.Body.String())
+	assert.Contains(t, buf.String(), `"msg":"паника при обработке запроса"`)
+}
+
+func TestCompressReusesEncoders(t *testing.T) {
+	// Arrange: ответы, сжатые одним и тем же encoder из пула, не смешиваются
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Compress())
+	router.GET("/:word", func(c *gin.Context) {
+		c.String(http.StatusOK, strings.Repeat(c.Param("word"), 1000))
+	})
+
+	for _, word := range []string{"книга", "автор", "книга"} {
+		req := httptest.NewRequest(http.MethodGet, "/"+word, nil)
+		req.Header.Set("Accept-Encoding", "br")
+
+		// Act
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		require.Equal(t, encodingBrotli, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, strings.Repeat(word, 1000), decompress(t, encodingBrotli, w.Body.Bytes()))
+	}
+}
+
+// decompress распаковывает тело ответа в указанной кодировке
+func decompress(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	var reader io.Reader = bytes.NewReader(body)
+	switch encoding {
+	case encodingGzip:
+		gz, err := gzip.NewReader(reader)
+		require.NoError(t, err)
+		reader = gz
+	case encodingBrotli:
+		reader = brotli.NewReader(reader)
+	}
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(data)
+}
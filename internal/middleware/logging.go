@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/logging"
+)
+
+// RequestIDHeader - заголовок с идентификатором запроса
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength ограничивает длину идентификатора, принятого от клиента
+const maxRequestIDLength = 128
+
+// RequestID берет идентификатор запроса из заголовка X-Request-ID или создает новый,
+// возвращает его в ответе и сохраняет в контексте запроса. Идентификатор попадает
+// во все записи журнала, сделанные с этим контекстом, и в ответы с ошибками.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// GetRequestID возвращает идентификатор текущего запроса
+func GetRequestID(c *gin.Context) string {
+	return logging.RequestID(c.Request.Context())
+}
+
+// validRequestID допускает идентификаторы из печатных ASCII-символов без пробелов,
+// чтобы значение от клиента нельзя было использовать для подделки записей журнала
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// Logger записывает в журнал каждый обработанный запрос: метод, путь, шаблон
+// маршрута, статус, время обработки и пользователя. Ответы 5xx пишутся с уровнем
+// error. Строка запроса не записывается, так как может содержать коды авторизации.
+func Logger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if principal, ok := CurrentPrincipal(c); ok {
+			attrs = append(attrs, slog.String("user", principal.Username))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+		logger.LogAttrs(c.Request.Context(), level, "запрос обработан", attrs...)
+	}
+}
+
+// Recovery перехватывает панику обработчика, записывает ее в журнал вместе со
+// стеком вызовов и отвечает 500 с идентификатором запроса
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		logger.ErrorContext(c.Request.Context(), "паника при обработке запроса",
+			"panic", recovered, "stack", string(debug.Stack()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrorBody(c, "внутренняя ошибка сервера"))
+	})
+}
+
+// ErrorBody возвращает тело ответа с ошибкой и идентификатором запроса
+func ErrorBody(c *gin.Context, message string) gin.H {
+	body := gin.H{"error": message}
+	if id := GetRequestID(c); id != "" {
+		body[logging.RequestIDKey] = id
+	}
+	return body
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLoggingRouter создает роутер с middleware журнала, пишущими в buf
+func newLoggingRouter(buf *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := logging.New(config.LogConfig{Level: config.LogLevelInfo, Format: config.LogFormatJSON}, buf)
+	router := gin.New()
+	router.Use(RequestID(), Logger(logger), Recovery(logger))
+	router.GET("/api/books/:id", func(c *gin.Context) {
+		logger.InfoContext(c.Request.Context(), "запись обработчика")
+		c.JSON(http.StatusNotFound, ErrorBody(c, "книга не найдена"))
+	})
+	router.GET("/panic", func(c *gin.Context) { panic("сбой") })
+	return router
+}
+
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		name       string
+		header     string
+		expectSame bool
+	}{
+		{name: "Идентификатор клиента сохраняется", header: "trace-42", expectSame: true},
+		{name: "Идентификатор создается, если заголовка нет", header: ""},
+		{name: "Идентификатор с переводом строки заменяется", header: "x\ny"},
+		{name: "Слишком длинный идентификатор заменяется", header: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			router := newLoggingRouter(&buf)
+			req := httptest.NewRequest(http.MethodGet, "/api/books/1", nil)
+			if tc.header != "" {
+				req.Header.Set(RequestIDHeader, tc.header)
+			}
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			id := w.Header().Get(RequestIDHeader)
+			if tc.expectSame {
+				assert.Equal(t, tc.header, id)
+			} else {
+				assert.Len(t, id, 32)
+			}
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, map[string]string{"error": "книга не найдена", "request_id": id}, body)
+			assert.Equal(t, 2, strings.Count(buf.String(), `"request_id":"`+id+`"`), "идентификатор есть в записи обработчика и в записи о запросе")
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	router := newLoggingRouter(&buf)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/books/7?token=secret", nil))
+
+	// Assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "запрос обработан", record["msg"])
+	assert.Equal(t, "GET", record["method"])
+	assert.Equal(t, "/api/books/7", record["path"])
+	assert.Equal(t, "/api/books/:id", record["route"])
+	assert.Equal(t, float64(http.StatusNotFound), record["status"])
+	assert.Contains(t, record, "duration_ms")
+	assert.NotContains(t, buf.String(), "secret", "строка запроса не записывается")
+}
+
+func TestRecovery(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	router := newLoggingRouter(&buf)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), `"request_id":"`+w.Header().Get(RequestIDHeader)+`"`)
+	assert.Contains(t, buf.String(), `"msg":"паника при обработке запроса"`)
+	assert.Contains(t, buf.String(), `"level":"ERROR"`)
+}
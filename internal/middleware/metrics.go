@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute заменяет путь запросов, не попавших ни в один маршрут,
+// чтобы произвольные адреса не порождали новые серии метрик
+const unmatchedRoute = "unmatched"
+
+// RequestObserver получает сведения об обработанных запросах
+type RequestObserver interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// Metrics передает observer метод, шаблон маршрута (например, /api/books/:id),
+// статус ответа и время обработки каждого запроса
+func Metrics(observer RequestObserver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		observer.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
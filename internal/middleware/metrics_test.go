@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// observedRequest представляет запрос, переданный RequestObserver
+type observedRequest struct {
+	method string
+	route  string
+	status int
+}
+
+type fakeObserver struct {
+	requests []observedRequest
+}
+
+func (o *fakeObserver) ObserveRequest(method, route string, status int, duration time.Duration) {
+	o.requests = append(o.requests, observedRequest{method: method, route: route, status: status})
+}
+
+func TestMetrics(t *testing.T) {
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		expected observedRequest
+	}{
+		{
+			name:     "Шаблон маршрута вместо пути",
+			method:   http.MethodGet,
+			path:     "/api/books/42",
+			expected: observedRequest{method: http.MethodGet, route: "/api/books/:id", status: http.StatusOK},
+		},
+		{
+			name:     "Статус ответа обработчика",
+			method:   http.MethodDelete,
+			path:     "/api/books/42",
+			expected: observedRequest{method: http.MethodDelete, route: "/api/books/:id", status: http.StatusForbidden},
+		},
+		{
+			name:     "Неизвестный маршрут",
+			method:   http.MethodGet,
+			path:     "/wp-login.php",
+			expected: observedRequest{method: http.MethodGet, route: unmatchedRoute, status: http.StatusNotFound},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			observer := &fakeObserver{}
+			router := gin.New()
+			router.Use(Metrics(observer))
+			router.GET("/api/books/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+			router.DELETE("/api/books/:id", func(c *gin.Context) { c.AbortWithStatus(http.StatusForbidden) })
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			// Assert
+			require.Len(t, observer.requests, 1)
+			assert.Equal(t, tc.expected, observer.requests[0])
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+// maxValidatedResponseSize ограничивает размер ответа, который буферизуется для
+// проверки; ответы большего размера, например выгрузка каталога, не проверяются
+const maxValidatedResponseSize = 1 << 20
+
+// validatedBodyTypes перечисляет типы содержимого, тела которых проверяются по схеме.
+// Формы OAI-PMH, файлы CSV, MARC и XML по схеме не проверяются: их разбирают
+// обработчики, а декодеры kin-openapi для форм считают пропущенные поля равными null.
+var validatedBodyTypes = map[string]bool{
+	"application/json": true,
+}
+
+// OpenAPIValidatorOptions задает, что проверяется по спецификации
+type OpenAPIValidatorOptions struct {
+	// Requests отклоняет с 400 запросы, не соответствующие спецификации
+	Requests bool
+	// Responses записывает в журнал ответы, не соответствующие спецификации
+	Responses bool
+}
+
+// OpenAPIValidator проверяет параметры пути и запроса, тело запроса и, если
+// включено, ответ по спецификации doc. Операция определяется по шаблону маршрута
+// Gin, поэтому маршруты, которых нет в спецификации, не проверяются. Ответы
+// клиенту не изменяются: несоответствие записывается в logger с уровнем error.
+// Права доступа проверяются отдельно, поэтому требования безопасности из
+// спецификации пропускаются.
+func OpenAPIValidator(doc *openapi3.T, opts OpenAPIValidatorOptions, logger *slog.Logger) gin.HandlerFunc {
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		// Значения по умолчанию подставляют обработчики, запрос не изменяется
+		SkipSettingDefaults: true,
+	}
+	options.WithCustomSchemaErrorFunc(schemaErrorMessage)
+
+	return func(c *gin.Context) {
+		route := findOperation(doc, c.Request.Method, c.FullPath())
+		if route == nil {
+			c.Next()
+			return
+		}
+
+		params := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = strings.TrimPrefix(param.Value, "/")
+		}
+		requestOptions := *options
+		requestOptions.ExcludeRequestBody = !validatedBodyTypes[mediaType(c.ContentType())]
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: params,
+			Route:      route,
+			Options:    &requestOptions,
+		}
+
+		if opts.Requests {
+			if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest,
+					ErrorBody(c, "запрос не соответствует спецификации: "+err.Error()))
+				return
+			}
+		}
+		if !opts.Responses {
+			c.Next()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+
+		validateResponse(c.Request.Context(), logger, input, options, recorder)
+	}
+}
+
+// validateResponse сверяет записанный ответ со спецификацией
+func validateResponse(ctx context.Context, logger *slog.Logger, input *openapi3filter.RequestValidationInput, options *openapi3filter.Options, recorder *responseRecorder) {
+	if recorder.overflow {
+		return
+	}
+	responseOptions := *options
+	responseOptions.ExcludeResponseBody = !validatedBodyTypes[mediaType(recorder.Header().Get("Content-Type"))]
+	response := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 recorder.Status(),
+		Header:                 recorder.Header(),
+		Options:                &responseOptions,
+	}
+	response.SetBodyBytes(recorder.body.Bytes())
+
+	if err := openapi3filter.ValidateResponse(ctx, response); err != nil {
+		logger.ErrorContext(ctx, "ответ не соответствует спецификации",
+			slog.String("method", input.Request.Method),
+			slog.String("route", input.Route.Path),
+			slog.Int("status", recorder.Status()),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// findOperation находит операцию спецификации по методу и шаблону маршрута Gin
+func findOperation(doc *openapi3.T, method, fullPath string) *routers.Route {
+	if fullPath == "" {
+		return nil
+	}
+	path := openAPIPath(fullPath)
+	pathItem := doc.Paths.Find(path)
+	if pathItem == nil {
+		return nil
+	}
+	operation := pathItem.GetOperation(method)
+	if operation == nil {
+		return nil
+	}
+	return &routers.Route{
+		Spec:      doc,
+		Path:      path,
+		PathItem:  pathItem,
+		Method:    method,
+		Operation: operation,
+	}
+}
+
+// openAPIPath переводит шаблон маршрута Gin вида /books/:id в /books/{id}
+func openAPIPath(fullPath string) string {
+	segments := strings.Split(fullPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func mediaType(contentType string) string {
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return parsed
+}
+
+// schemaErrorMessage сокращает ошибку схемы до пути к полю и причины,
+// не включая в ответ саму схему и присланное значение
+func schemaErrorMessage(err *openapi3.SchemaError) string {
+	pointer := err.JSONPointer()
+	if len(pointer) == 0 {
+		return err.Reason
+	}
+	return "/" + strings.Join(pointer, "/") + ": " + err.Reason
+}
+
+// responseRecorder передает ответ клиенту и сохраняет копию тела для проверки.
+// Тела, которые не проверяются по схеме, не сохраняются.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	started  bool
+	skip     bool
+	overflow bool
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseRecorder) record(data []byte) {
+	if !w.started {
+		w.started = true
+		w.skip = !validatedBodyTypes[mediaType(w.Header().Get("Content-Type"))]
+	}
+	if w.skip || w.overflow {
+		return
+	}
+	if w.body.Len()+len(data) > maxValidatedResponseSize {
+		w.overflow = true
+		w.body = bytes.Buffer{}
+		return
+	}
+	w.body.Write(data)
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSpec описывает получение и создание книги
+const testSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "test", "version": "1.0"},
+  "paths": {
+    "/api/books": {
+      "post": {
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Book"}}}
+        },
+        "responses": {"201": {"description": "", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Book"}}}}}
+      }
+    },
+    "/api/books/{id}": {
+      "get": {
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}},
+          {"name": "format", "in": "query", "schema": {"type": "string", "enum": ["json", "csv"]}}
+        ],
+        "responses": {"200": {"description": "", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Book"}}}}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Book": {
+        "type": "object",
+        "required": ["title", "year"],
+        "properties": {"title": {"type": "string"}, "year": {"type": "integer"}}
+      }
+    }
+  }
+}`
+
+// newValidatedRouter создает роутер с проверкой по testSpec. Обработчик
+// GET /api/books/:id возвращает тело из заголовка X-Body.
+func newValidatedRouter(t *testing.T, opts OpenAPIValidatorOptions, buf *bytes.Buffer) *gin.Engine {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(testSpec))
+	require.NoError(t, err)
+	gin.SetMode(gin.TestMode)
+	logger := logging.New(config.LogConfig{Level: config.LogLevelInfo, Format: config.LogFormatJSON}, buf)
+	router := gin.New()
+	router.Use(OpenAPIValidator(doc, opts, logger))
+	router.GET("/api/books/:id", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(c.GetHeader("X-Body")))
+	})
+	router.POST("/api/books", func(c *gin.Context) {
+		var book map[string]any
+		if err := c.ShouldBindJSON(&book); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorBody(c, err.Error()))
+			return
+		}
+		c.JSON(http.StatusCreated, book)
+	})
+	router.GET("/internal", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	return router
+}
+
+func TestOpenAPIValidatorRequests(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "Корректный запрос", method: http.MethodGet, path: "/api/books/1?format=csv", expectedStatus: http.StatusOK},
+		{name: "Неверный параметр пути", method: http.MethodGet, path: "/api/books/abc", expectedStatus: http.StatusBadRequest, expectedError: `parameter \"id\" in path`},
+		{name: "Неверный параметр запроса", method: http.MethodGet, path: "/api/books/1?format=xml", expectedStatus: http.StatusBadRequest, expectedError: `parameter \"format\" in query`},
+		{name: "Корректное тело", method: http.MethodPost, path: "/api/books", body: `{"title":"Мы","year":1920}`, expectedStatus: http.StatusCreated},
+		{name: "Пропущено обязательное поле", method: http.MethodPost, path: "/api/books", body: `{"title":"Мы"}`, expectedStatus: http.StatusBadRequest, expectedError: `/year: property \"year\" is missing`},
+		{name: "Поле неверного типа", method: http.MethodPost, path: "/api/books", body: `{"title":"Мы","year":"1920"}`, expectedStatus: http.StatusBadRequest, expectedError: "/year: value must be an integer"},
+		{name: "Маршрут вне спецификации не проверяется", method: http.MethodGet, path: "/internal?format=xml", expectedStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			router := newValidatedRouter(t, OpenAPIValidatorOptions{Requests: true}, &buf)
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			req.Header.Set("X-Body", `{"title":"Мы","year":1920}`)
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedError != "" {
+				assert.Contains(t, w.Body.String(), "запрос не соответствует спецификации")
+				assert.Contains(t, w.Body.String(), tc.expectedError)
+				assert.NotContains(t, w.Body.String(), "Schema:", "схема не раскрывается клиенту")
+			}
+			if tc.expectedStatus == http.StatusCreated {
+				assert.JSONEq(t, tc.body, w.Body.String(), "тело запроса доступно обработчику")
+			}
+		})
+	}
+}
+
+func TestOpenAPIValidatorResponses(t *testing.T) {
+	testCases := []struct {
+		name        string
+		body        string
+		expectedLog string
+	}{
+		{name: "Ответ соответствует спецификации", body: `{"title":"Мы","year":1920}`},
+		{name: "Поле ответа неверного типа", body: `{"title":"Мы","year":"1920"}`, expectedLog: "/year: value must be an integer"},
+		{name: "В ответе нет обязательного поля", body: `{"year":1920}`, expectedLog: `/title: property \"title\" is missing`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			router := newValidatedRouter(t, OpenAPIValidatorOptions{Responses: true}, &buf)
+			req := httptest.NewRequest(http.MethodGet, "/api/books/1", nil)
+			req.Header.Set("X-Body", tc.body)
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tc.body, w.Body.String(), "ответ передается клиенту без изменений")
+			if tc.expectedLog == "" {
+				assert.Empty(t, buf.String())
+				return
+			}
+			assert.Contains(t, buf.String(), "ответ не соответствует спецификации")
+			assert.Contains(t, buf.String(), `"route":"/api/books/{id}"`)
+			assert.Contains(t, buf.String(), tc.expectedLog)
+		})
+	}
+}
+
+func TestOpenAPIValidatorSkipsLargeResponses(t *testing.T) {
+	// Arrange: тело больше предела не буферизуется и не проверяется
+	var buf bytes.Buffer
+	router := newValidatedRouter(t, OpenAPIValidatorOptions{Responses: true}, &buf)
+	body := `{"title":"` + strings.Repeat("a", maxValidatedResponseSize) + `"}`
+	req := httptest.NewRequest(http.MethodGet, "/api/books/1", nil)
+	req.Header.Set("X-Body", body)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, len(body), w.Body.Len())
+	assert.Empty(t, buf.String())
+}
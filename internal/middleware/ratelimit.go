@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/ratelimit"
+)
+
+// RateLimit ограничивает частоту запросов к группам маршрутов. Для запроса выбирается
+// правило с самым длинным подходящим префиксом пути; у каждого правила свои корзины.
+// Клиент определяется по API-ключу, затем по пользователю, иначе по IP-адресу,
+// поэтому RateLimit подключается после Authenticate.
+func RateLimit(store ratelimit.Store, rules []ratelimit.Rule) gin.HandlerFunc {
+	sorted := append([]ratelimit.Rule(nil), rules...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].PathPrefix) > len(sorted[j].PathPrefix)
+	})
+
+	return func(c *gin.Context) {
+		rule, ok := matchRule(sorted, c.Request.URL.Path)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		result, err := store.Take(rule.Name+":"+clientKey(c), rule.Limit)
+		if err != nil {
+			// Недоступность хранилища не должна останавливать API
+			slog.WarnContext(c.Request.Context(), "ошибка ограничения частоты запросов", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(result.ResetAfter))
+		if !result.Allowed {
+			c.Header("Retry-After", ceilSeconds(result.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, ErrorBody(c, "слишком много запросов"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// LimitFailedAuth ограничивает неудачные попытки аутентификации с одного IP-адреса.
+// Запрос с заголовком Authorization, получивший 401, забирает токен из корзины IP;
+// пока корзина пуста, такие запросы отклоняются с 429 до проверки учетных данных.
+// Подключается перед Authenticate, чтобы подбор токенов и API-ключей ограничивался
+// и не нагружал базу данных.
+func LimitFailedAuth(store ratelimit.Store, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+
+		key := "auth-failures:ip:" + c.ClientIP()
+		result, err := store.Peek(key, limit)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "ошибка ограничения частоты запросов", "error", err)
+		} else if !result.Allowed {
+			c.Header("Retry-After", ceilSeconds(result.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, ErrorBody(c, "слишком много неудачных попыток аутентификации"))
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() == http.StatusUnauthorized {
+			if _, err := store.Take(key, limit); err != nil {
+				slog.WarnContext(c.Request.Context(), "ошибка ограничения частоты запросов", "error", err)
+			}
+		}
+	}
+}
+
+func matchRule(rules []ratelimit.Rule, path string) (ratelimit.Rule, bool) {
+	for _, rule := range rules {
+		if strings.HasPrefix(path, rule.PathPrefix) {
+			return rule, true
+		}
+	}
+	return ratelimit.Rule{}, false
+}
+
+// clientKey определяет клиента запроса
+func clientKey(c *gin.Context) string {
+	if principal, ok := CurrentPrincipal(c); ok {
+		if principal.APIKeyID != 0 {
+			return "key:" + strconv.FormatUint(uint64(principal.APIKeyID), 10)
+		}
+		return "user:" + strconv.FormatUint(uint64(principal.UserID), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStore имитирует недоступное общее хранилище
+type failingStore struct{}
+
+func (failingStore) Take(string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("хранилище недоступно")
+}
+
+func (failingStore) Peek(string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("хранилище недоступно")
+}
+
+// countingKeyVerifier подсчитывает проверки API-ключей
+type countingKeyVerifier struct {
+	stubKeyVerifier
+	calls int
+}
+
+func (v *countingKeyVerifier) VerifyAPIKey(ctx context.Context, key string) (*model.Principal, error) {
+	v.calls++
+	return v.stubKeyVerifier.VerifyAPIKey(ctx, key)
+}
+
+func newRateLimitedRouter(store ratelimit.Store) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Authenticate(stubVerifier{}, stubKeyVerifier{}))
+	router.Use(RateLimit(store, []ratelimit.Rule{
+		{Name: "api", PathPrefix: "/api", Limit: ratelimit.PerMinute(600, 100)},
+		{Name: "search", PathPrefix: "/api/books/search", Limit: ratelimit.PerMinute(60, 1)},
+	}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/books", ok)
+	router.GET("/api/books/search", ok)
+	router.GET("/", ok)
+	return router
+}
+
+func TestRateLimit(t *testing.T) {
+	testCases := []struct {
+		name           string
+		first          string
+		second         string
+		secondHeader   string
+		expectedStatus int
+	}{
+		{name: "Повторный поиск с того же IP", first: "/api/books/search", second: "/api/books/search", expectedStatus: http.StatusTooManyRequests},
+		{name: "Группы маршрутов ограничиваются раздельно", first: "/api/books/search", second: "/api/books", expectedStatus: http.StatusOK},
+		{name: "Аутентифицированный пользователь имеет свою корзину", first: "/api/books/search", second: "/api/books/search", secondHeader: "Bearer valid", expectedStatus: http.StatusOK},
+		{name: "API-ключ имеет свою корзину", first: "/api/books/search", second: "/api/books/search", secondHeader: "ApiKey blk_kiosk", expectedStatus: http.StatusOK},
+		{name: "Маршруты вне правил не ограничиваются", first: "/", second: "/", expectedStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			router := newRateLimitedRouter(ratelimit.NewMemoryStore())
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.first, nil))
+
+			req := httptest.NewRequest(http.MethodGet, tc.second, nil)
+			if tc.secondHeader != "" {
+				req.Header.Set("Authorization", tc.secondHeader)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	// Arrange
+	router := newRateLimitedRouter(ratelimit.NewMemoryStore())
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/api/books/search", nil))
+
+	// Act
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/api/books/search", nil))
+
+	// Assert
+	assert.Equal(t, "1", first.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", first.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", first.Header().Get("RateLimit-Reset"))
+	assert.Empty(t, first.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusTooManyRequests, second.Code)
+	assert.Equal(t, "1", second.Header().Get("Retry-After"))
+}
+
+func TestRateLimitFailsOpen(t *testing.T) {
+	// Arrange
+	router := newRateLimitedRouter(failingStore{})
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/books/search", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestLimitFailedAuth(t *testing.T) {
+	// Arrange: не больше 3 неудачных попыток подряд с одного IP
+	gin.SetMode(gin.TestMode)
+	keys := &countingKeyVerifier{}
+	router := gin.New()
+	router.Use(LimitFailedAuth(ratelimit.NewMemoryStore(), ratelimit.PerMinute(1, 3)))
+	router.Use(Authenticate(stubVerifier{}, keys))
+	router.GET("/api/books", func(c *gin.Context) { c.Status(http.StatusOK) })
+	request := func(header, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/books", nil)
+		req.Header.Set("Authorization", header)
+		req.RemoteAddr = ip + ":12345"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act & Assert: успешные запросы не расходуют попытки
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusOK, request("ApiKey blk_kiosk", "10.0.0.1").Code)
+	}
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusUnauthorized, request("ApiKey blk_guess", "10.0.0.1").Code)
+	}
+	calls := keys.calls
+
+	// Следующие попытки отклоняются без проверки ключа, в том числе с верным ключом
+	blocked := request("ApiKey blk_guess", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, blocked.Code)
+	assert.NotEmpty(t, blocked.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusTooManyRequests, request("Bearer invalid", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("ApiKey blk_kiosk", "10.0.0.1").Code)
+	assert.Equal(t, calls, keys.calls)
+
+	// Другой IP-адрес ограничивается отдельно
+	assert.Equal(t, http.StatusOK, request("ApiKey blk_kiosk", "10.0.0.2").Code)
+}
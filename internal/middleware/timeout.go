@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutRule задает время обработки запросов к маршрутам с общим префиксом пути
+type TimeoutRule struct {
+	PathPrefix string
+	Timeout    time.Duration
+}
+
+// Timeout ограничивает время обработки запроса: по истечении срока контекст запроса
+// отменяется, и выполняющиеся запросы к базе данных прерываются. Для запроса выбирается
+// правило с самым длинным подходящим префиксом пути, иначе действует defaultTimeout.
+// Нулевая длительность снимает ограничение.
+func Timeout(defaultTimeout time.Duration, rules []TimeoutRule) gin.HandlerFunc {
+	sorted := append([]TimeoutRule(nil), rules...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].PathPrefix) > len(sorted[j].PathPrefix)
+	})
+
+	return func(c *gin.Context) {
+		timeout := defaultTimeout
+		for _, rule := range sorted {
+			if strings.HasPrefix(c.Request.URL.Path, rule.PathPrefix) {
+				timeout = rule.Timeout
+				break
+			}
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
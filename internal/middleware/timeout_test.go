@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	testCases := []struct {
+		name            string
+		defaultTimeout  time.Duration
+		path            string
+		expectedTimeout time.Duration
+	}{
+		{name: "Срок по умолчанию", defaultTimeout: 30 * time.Second, path: "/books.html", expectedTimeout: 30 * time.Second},
+		{name: "Правило по префиксу", defaultTimeout: 30 * time.Second, path: "/api/books", expectedTimeout: time.Minute},
+		{name: "Правило с самым длинным префиксом", defaultTimeout: 30 * time.Second, path: "/api/books/export", expectedTimeout: 5 * time.Minute},
+		{name: "Правило без ограничения", defaultTimeout: 30 * time.Second, path: "/oai", expectedTimeout: 0},
+		{name: "Без ограничения по умолчанию", defaultTimeout: 0, path: "/books.html", expectedTimeout: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(Timeout(tc.defaultTimeout, []TimeoutRule{
+				{PathPrefix: "/api", Timeout: time.Minute},
+				{PathPrefix: "/api/books/export", Timeout: 5 * time.Minute},
+				{PathPrefix: "/oai", Timeout: 0},
+			}))
+			var deadline time.Time
+			var hasDeadline bool
+			router.NoRoute(func(c *gin.Context) {
+				deadline, hasDeadline = c.Request.Context().Deadline()
+				c.Status(http.StatusOK)
+			})
+			started := time.Now()
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			if tc.expectedTimeout == 0 {
+				assert.False(t, hasDeadline)
+				return
+			}
+			assert.True(t, hasDeadline)
+			assert.WithinDuration(t, started.Add(tc.expectedTimeout), deadline, time.Second)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// setupSpanRecorder делает глобальным провайдер, записывающий спаны в память
+func setupSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	_, err := tracing.Setup(context.Background(), config.TracingConfig{Exporter: config.TracingExporterNone}, nil)
+	require.NoError(t, err)
+	provider := tracing.NewProvider(config.TracingConfig{ServiceName: "test", SampleRatio: 1}, sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestTracing(t *testing.T) {
+	const parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	testCases := []struct {
+		name           string
+		path           string
+		traceparent    string
+		expectedName   string
+		expectedStatus int
+		expectError    bool
+	}{
+		{name: "Новая трасса", path: "/api/books/1", expectedName: "GET /api/books/:id", expectedStatus: http.StatusOK},
+		{name: "Продолжение трассы из traceparent", path: "/api/books/1", traceparent: "00-" + parentTraceID + "-00f067aa0ba902b7-01", expectedName: "GET /api/books/:id", expectedStatus: http.StatusOK},
+		{name: "Ошибка сервера", path: "/fail", expectedName: "GET /fail", expectedStatus: http.StatusInternalServerError, expectError: true},
+		{name: "Неизвестный маршрут", path: "/missing", expectedName: "GET", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			recorder := setupSpanRecorder(t)
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(RequestID(), Tracing())
+			var handlerSpan trace.SpanContext
+			router.GET("/api/books/:id", func(c *gin.Context) {
+				handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+			router.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set(RequestIDHeader, "req-9")
+			if tc.traceparent != "" {
+				req.Header.Set("traceparent", tc.traceparent)
+			}
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			assert.Equal(t, tc.expectedName, span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", tc.expectedStatus))
+			assert.Contains(t, span.Attributes(), attribute.String("request_id", "req-9"))
+			if tc.expectError {
+				assert.Equal(t, codes.Error, span.Status().Code)
+			} else {
+				assert.Equal(t, codes.Unset, span.Status().Code)
+			}
+			if tc.traceparent != "" {
+				assert.Equal(t, parentTraceID, span.SpanContext().TraceID().String())
+				assert.True(t, span.Parent().IsRemote())
+			}
+			if handlerSpan.IsValid() {
+				assert.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID(), "спан доступен обработчику через контекст")
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Create создает в каталоге dir пару пустых файлов миграции со следующей
+// по порядку версией и возвращает их пути. Пробелы и дефисы в названии
+// заменяются подчеркиваниями.
+func Create(dir, name string) (string, string, error) {
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(name)))
+	if !namePattern.MatchString(name) {
+		return "", "", fmt.Errorf("неверное название миграции %q: допустимы латинские буквы, цифры и подчеркивания", name)
+	}
+
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+	var version int64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%04d_%s", version, name))
+	up, down := base+".up.sql", base+".down.sql"
+	if err := os.WriteFile(up, []byte("-- Изменения схемы\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(down, []byte("-- Отмена изменений из "+filepath.Base(up)+"\n"), 0o644); err != nil {
+		os.Remove(up)
+		return "", "", err
+	}
+	return up, down, nil
+}
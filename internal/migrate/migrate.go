@@ -0,0 +1,374 @@
+// Package migrate применяет версионированные SQL-миграции схемы базы данных.
+// Миграции хранятся парами файлов <версия>_<название>.up.sql и .down.sql,
+// а примененные версии записываются в таблицу schema_migrations.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dir задает каталог миграций в исходном коде; используется командой create
+const Dir = "internal/migrate/migrations"
+
+// noTransactionDirective в первой строке файла отключает транзакцию,
+// например для CREATE INDEX CONCURRENTLY
+const noTransactionDirective = "-- migrate:no-transaction"
+
+// lockKey задает ключ advisory-блокировки PostgreSQL, под которой выполняются миграции,
+// чтобы несколько запущенных экземпляров не применяли их одновременно
+const lockKey int64 = 7_402_117_003
+
+//go:embed migrations/*.sql
+var embedded embed.FS
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// ErrNoMigrations возвращается, если откатывать нечего
+var ErrNoMigrations = errors.New("нет примененных миграций")
+
+// ErrPending возвращается Check, если есть непримененные миграции
+var ErrPending = errors.New("есть непримененные миграции")
+
+// Migration представляет одну версию схемы
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	// NoTransaction отключает транзакцию при применении и откате
+	NoTransaction bool
+}
+
+// appliedRecord представляет запись таблицы schema_migrations
+type appliedRecord struct {
+	Name      string
+	AppliedAt time.Time
+}
+
+// Status представляет состояние миграции в базе данных
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// Missing означает, что версия применена, но файла миграции нет в сборке
+	Missing bool
+}
+
+// Embedded возвращает миграции, встроенные в исполняемый файл
+func Embedded() ([]Migration, error) {
+	sub, err := fs.Sub(embedded, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return Load(sub)
+}
+
+// Load читает миграции из корня fsys и проверяет, что у каждой версии
+// есть файлы up и down и версии не повторяются
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("неверное имя файла миграции %s: ожидается <версия>_<название>.up.sql или .down.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("неверная версия миграции %s", entry.Name())
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("версия %d используется миграциями %s и %s", version, m.Name, match[2])
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		body := string(data)
+		if strings.HasPrefix(body, noTransactionDirective) {
+			m.NoTransaction = true
+		}
+		if match[3] == "up" {
+			m.Up = body
+		} else {
+			m.Down = body
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("у миграции %04d_%s должны быть непустые файлы up и down", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator применяет и откатывает миграции
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New создает новый экземпляр Migrator
+func New(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// Up применяет все непримененные миграции по возрастанию версии
+// и возвращает примененные
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]appliedRecord) error {
+		for _, migration := range pending(m.migrations, applied) {
+			if err := apply(ctx, conn, migration, true); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down откатывает steps последних примененных миграций
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]appliedRecord) error {
+		targets, err := toRevert(m.migrations, applied, steps)
+		if err != nil {
+			return err
+		}
+		for _, migration := range targets {
+			if err := apply(ctx, conn, migration, false); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status возвращает состояние всех известных и примененных миграций по возрастанию версии
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]appliedRecord) error {
+		statuses = status(m.migrations, applied)
+		return nil
+	})
+	return statuses, err
+}
+
+// Pending возвращает миграции, которые еще не применены
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	var result []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]appliedRecord) error {
+		result = pending(m.migrations, applied)
+		return nil
+	})
+	return result, err
+}
+
+// Check проверяет, что все миграции применены. В отличие от Pending, не берет
+// блокировку и не создает schema_migrations, поэтому подходит для частых
+// проверок готовности.
+func (m *Migrator) Check(ctx context.Context) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if n := len(pending(m.migrations, applied)); n > 0 {
+		return fmt.Errorf("%w: %d", ErrPending, n)
+	}
+	return nil
+}
+
+// locked выполняет fn на отдельном соединении под advisory-блокировкой.
+// Блокировка уровня сеанса привязана к соединению, поэтому все запросы
+// выполняются через conn.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, applied map[int64]appliedRecord) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("ошибка блокировки миграций: %w", err)
+	}
+	defer func() {
+		// Контекст мог быть отменен, а блокировку нужно снять в любом случае
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+	}()
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return fmt.Errorf("ошибка создания schema_migrations: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return fn(conn, applied)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]appliedRecord, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64]appliedRecord)
+	for rows.Next() {
+		var version int64
+		var record appliedRecord
+		if err := rows.Scan(&version, &record.Name, &record.AppliedAt); err != nil {
+			return nil, err
+		}
+		result[version] = record
+	}
+	return result, rows.Err()
+}
+
+// apply применяет (up) или откатывает миграцию вместе с записью в schema_migrations
+func apply(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	body, record, args := migration.Down, "DELETE FROM schema_migrations WHERE version = $1", []interface{}{migration.Version}
+	if up {
+		body, record, args = migration.Up, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", []interface{}{migration.Version, migration.Name}
+	}
+
+	wrap := func(err error) error {
+		direction := "отката"
+		if up {
+			direction = "применения"
+		}
+		return fmt.Errorf("ошибка %s миграции %04d_%s: %w", direction, migration.Version, migration.Name, err)
+	}
+
+	if migration.NoTransaction {
+		if _, err := conn.ExecContext(ctx, body); err != nil {
+			return wrap(err)
+		}
+		if _, err := conn.ExecContext(ctx, record, args...); err != nil {
+			return wrap(err)
+		}
+		return nil
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return wrap(err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return wrap(err)
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return wrap(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return wrap(err)
+	}
+	return nil
+}
+
+// pending возвращает непримененные миграции по возрастанию версии
+func pending(migrations []Migration, applied map[int64]appliedRecord) []Migration {
+	var result []Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; !ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// toRevert возвращает steps последних примененных миграций в порядке отката
+func toRevert(migrations []Migration, applied map[int64]appliedRecord, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, errors.New("число откатываемых миграций должно быть положительным")
+	}
+
+	var result []Migration
+	for i := len(migrations) - 1; i >= 0 && len(result) < steps; i-- {
+		if _, ok := applied[migrations[i].Version]; ok {
+			result = append(result, migrations[i])
+		}
+	}
+	if len(result) == 0 {
+		return nil, ErrNoMigrations
+	}
+
+	// Откатить версию, файла которой нет в сборке, нельзя
+	known := make(map[int64]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = true
+	}
+	for version := range applied {
+		if !known[version] && version > result[0].Version {
+			return nil, fmt.Errorf("примененная миграция %04d отсутствует в сборке", version)
+		}
+	}
+	return result, nil
+}
+
+// status объединяет известные и примененные миграции
+func status(migrations []Migration, applied map[int64]appliedRecord) []Status {
+	statuses := make([]Status, 0, len(migrations))
+	known := make(map[int64]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = true
+		s := Status{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			appliedAt := record.AppliedAt
+			s.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, s)
+	}
+	for version, record := range applied {
+		if !known[version] {
+			appliedAt := record.AppliedAt
+			statuses = append(statuses, Status{Version: version, Name: record.Name, AppliedAt: &appliedAt, Missing: true})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
@@ -0,0 +1,245 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func file(body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(body)}
+}
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name          string
+		files         fstest.MapFS
+		expected      []Migration
+		expectedError bool
+	}{
+		{
+			name: "Миграции сортируются по версии",
+			files: fstest.MapFS{
+				"0002_add_index.up.sql":      file("CREATE INDEX i ON t (c);"),
+				"0002_add_index.down.sql":    file("DROP INDEX i;"),
+				"0001_create_table.up.sql":   file("CREATE TABLE t (c INT);"),
+				"0001_create_table.down.sql": file("DROP TABLE t;"),
+				"README.md":                  file("не миграция"),
+			},
+			expected: []Migration{
+				{Version: 1, Name: "create_table", Up: "CREATE TABLE t (c INT);", Down: "DROP TABLE t;"},
+				{Version: 2, Name: "add_index", Up: "CREATE INDEX i ON t (c);", Down: "DROP INDEX i;"},
+			},
+		},
+		{
+			name: "Миграция без транзакции",
+			files: fstest.MapFS{
+				"0001_concurrent_index.up.sql":   file(noTransactionDirective + "\nCREATE INDEX CONCURRENTLY i ON t (c);"),
+				"0001_concurrent_index.down.sql": file("DROP INDEX i;"),
+			},
+			expected: []Migration{{
+				Version:       1,
+				Name:          "concurrent_index",
+				Up:            noTransactionDirective + "\nCREATE INDEX CONCURRENTLY i ON t (c);",
+				Down:          "DROP INDEX i;",
+				NoTransaction: true,
+			}},
+		},
+		{
+			name: "Нет файла down",
+			files: fstest.MapFS{
+				"0001_create_table.up.sql": file("CREATE TABLE t (c INT);"),
+			},
+			expectedError: true,
+		},
+		{
+			name: "Пустой файл",
+			files: fstest.MapFS{
+				"0001_create_table.up.sql":   file("CREATE TABLE t (c INT);"),
+				"0001_create_table.down.sql": file("  \n"),
+			},
+			expectedError: true,
+		},
+		{
+			name: "Неверное имя файла",
+			files: fstest.MapFS{
+				"create_table.up.sql": file("CREATE TABLE t (c INT);"),
+			},
+			expectedError: true,
+		},
+		{
+			name: "Повтор версии",
+			files: fstest.MapFS{
+				"0001_create_table.up.sql":   file("CREATE TABLE t (c INT);"),
+				"0001_create_table.down.sql": file("DROP TABLE t;"),
+				"0001_add_index.up.sql":      file("CREATE INDEX i ON t (c);"),
+				"0001_add_index.down.sql":    file("DROP INDEX i;"),
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			migrations, err := Load(tc.files)
+
+			// Assert
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, migrations)
+		})
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	// Act
+	migrations, err := Embedded()
+
+	// Assert
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		assert.Equal(t, int64(i+1), m.Version, "версии идут без пропусков")
+	}
+	assert.Equal(t, "create_books", migrations[0].Name)
+}
+
+func TestPending(t *testing.T) {
+	// Arrange
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+	applied := map[int64]appliedRecord{1: {}, 3: {}}
+
+	// Act
+	result := pending(migrations, applied)
+
+	// Assert
+	assert.Equal(t, []Migration{{Version: 2}}, result)
+}
+
+func TestToRevert(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+
+	testCases := []struct {
+		name          string
+		applied       map[int64]appliedRecord
+		steps         int
+		expected      []int64
+		expectedError error
+	}{
+		{name: "Последняя миграция", applied: map[int64]appliedRecord{1: {}, 2: {}, 3: {}}, steps: 1, expected: []int64{3}},
+		{name: "Несколько в обратном порядке", applied: map[int64]appliedRecord{1: {}, 2: {}}, steps: 5, expected: []int64{2, 1}},
+		{name: "Нет примененных", applied: map[int64]appliedRecord{}, steps: 1, expectedError: ErrNoMigrations},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			result, err := toRevert(migrations, tc.applied, tc.steps)
+
+			// Assert
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			versions := make([]int64, 0, len(result))
+			for _, m := range result {
+				versions = append(versions, m.Version)
+			}
+			assert.Equal(t, tc.expected, versions)
+		})
+	}
+
+	t.Run("Неположительное число шагов", func(t *testing.T) {
+		// Act
+		_, err := toRevert(migrations, map[int64]appliedRecord{1: {}}, 0)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Более новая версия отсутствует в сборке", func(t *testing.T) {
+		// Arrange
+		applied := map[int64]appliedRecord{1: {}, 2: {}, 3: {}, 4: {Name: "from_future"}}
+
+		// Act
+		_, err := toRevert(migrations, applied, 1)
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestStatus(t *testing.T) {
+	// Arrange
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	migrations := []Migration{{Version: 1, Name: "create_books"}, {Version: 2, Name: "add_index"}}
+	applied := map[int64]appliedRecord{
+		1: {Name: "create_books", AppliedAt: appliedAt},
+		7: {Name: "removed", AppliedAt: appliedAt},
+	}
+
+	// Act
+	statuses := status(migrations, applied)
+
+	// Assert
+	assert.Equal(t, []Status{
+		{Version: 1, Name: "create_books", AppliedAt: &appliedAt},
+		{Version: 2, Name: "add_index"},
+		{Version: 7, Name: "removed", AppliedAt: &appliedAt, Missing: true},
+	}, statuses)
+}
+
+func TestCreate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		existing      []string
+		migration     string
+		expectedBase  string
+		expectedError bool
+	}{
+		{name: "Первая миграция", migration: "create_books", expectedBase: "0001_create_books"},
+		{
+			name:         "Следующая версия",
+			existing:     []string{"0001_create_books.up.sql", "0001_create_books.down.sql"},
+			migration:    "Add Books-Index",
+			expectedBase: "0002_add_books_index",
+		},
+		{name: "Недопустимые символы", migration: "книги", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			for _, name := range tc.existing {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0o644))
+			}
+
+			// Act
+			up, down, err := Create(dir, tc.migration)
+
+			// Assert
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, tc.expectedBase+".up.sql"), up)
+			assert.Equal(t, filepath.Join(dir, tc.expectedBase+".down.sql"), down)
+
+			migrations, err := Load(os.DirFS(dir))
+			require.NoError(t, err, "созданные файлы должны загружаться")
+			assert.Len(t, migrations, len(tc.existing)/2+1)
+		})
+	}
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_books_updated_at_id;
+DROP INDEX IF EXISTS idx_books_author_trgm;
+DROP INDEX IF EXISTS idx_books_title_trgm;
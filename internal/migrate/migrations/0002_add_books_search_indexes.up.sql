@@ -0,0 +1,8 @@
+-- Триграммные индексы ускоряют поиск ILIKE по названию и автору
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_books_title_trgm ON books USING gin (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_books_author_trgm ON books USING gin (author gin_trgm_ops);
+
+-- Выборки OAI-PMH идут в порядке изменения
+CREATE INDEX IF NOT EXISTS idx_books_updated_at_id ON books (updated_at, id);
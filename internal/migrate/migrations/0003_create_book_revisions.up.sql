@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS book_revisions (
+    id SERIAL PRIMARY KEY,
+    book_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    title VARCHAR(255),
+    author VARCHAR(255),
+    isbn VARCHAR(13),
+    description TEXT,
+    year INTEGER,
+    publisher VARCHAR(255),
+    available BOOLEAN,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_book_revisions_book_revision ON book_revisions (book_id, revision);
+
+-- Книги, созданные до появления истории, получают исходную ревизию с текущими данными
+INSERT INTO book_revisions (book_id, revision, title, author, isbn, description, year, publisher, available, created_at)
+SELECT id, 1, title, author, isbn, description, year, publisher, available, updated_at FROM books
+ON CONFLICT (book_id, revision) DO NOTHING;
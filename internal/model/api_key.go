@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// APIKey представляет ключ доступа для интеграций без интерактивного входа
+// (киоски, станции самообслуживания). Хранится только хеш ключа; префикс
+// позволяет найти ключ и узнать его в журналах, не раскрывая секрет.
+type APIKey struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	Name       string       `json:"name" gorm:"not null"`
+	Prefix     string       `json:"prefix" gorm:"not null;uniqueIndex"`
+	KeyHash    string       `json:"-" gorm:"not null"`
+	Scopes     []Permission `json:"scopes" gorm:"type:text;serializer:json;not null"`
+	CreatedBy  uint         `json:"created_by"`
+	ExpiresAt  *time.Time   `json:"expires_at" extensions:"x-nullable"`
+	LastUsedAt *time.Time   `json:"last_used_at" extensions:"x-nullable"`
+	RevokedAt  *time.Time   `json:"revoked_at" extensions:"x-nullable"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// HasScope проверяет, разрешено ли ключу действие
+func (k *APIKey) HasScope(permission Permission) bool {
+	for _, scope := range k.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Active проверяет, что ключ не отозван и не истек
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// APIKeyCreate представляет данные для создания API-ключа
+type APIKeyCreate struct {
+	Name      string       `json:"name" binding:"required"`
+	Scopes    []Permission `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time   `json:"expires_at" extensions:"x-nullable"`
+}
+
+// APIKeyCreated представляет созданный API-ключ. Значение ключа возвращается только один раз.
+type APIKeyCreated struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyActive(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	testCases := []struct {
+		name     string
+		key      APIKey
+		expected bool
+	}{
+		{name: "Бессрочный ключ", key: APIKey{}, expected: true},
+		{name: "Срок не истек", key: APIKey{ExpiresAt: &future}, expected: true},
+		{name: "Срок истек", key: APIKey{ExpiresAt: &past}, expected: false},
+		{name: "Отозванный ключ", key: APIKey{RevokedAt: &past}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act & Assert
+			assert.Equal(t, tc.expected, tc.key.Active(now))
+		})
+	}
+}
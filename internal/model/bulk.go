@@ -0,0 +1,56 @@
+package model
+
+// Операции пакетной обработки книг
+const (
+	BulkOpCreate = "create"
+	BulkOpUpdate = "update"
+	BulkOpDelete = "delete"
+)
+
+// Режимы выполнения пакетной обработки
+const (
+	// BulkModeTransaction выполняет все операции в одной транзакции: любая ошибка откатывает весь пакет
+	BulkModeTransaction = "transaction"
+	// BulkModeBestEffort выполняет операции независимо друг от друга
+	BulkModeBestEffort = "best_effort"
+)
+
+// Статусы результата отдельной операции пакета
+const (
+	BulkStatusCreated    = "created"
+	BulkStatusUpdated    = "updated"
+	BulkStatusDeleted    = "deleted"
+	BulkStatusFailed     = "failed"
+	BulkStatusRolledBack = "rolled_back"
+)
+
+// BulkOperation представляет одну операцию в пакетном запросе
+type BulkOperation struct {
+	Op   string      `json:"op"`
+	ID   uint        `json:"id,omitempty"`
+	Book *BookCreate `json:"book,omitempty"`
+}
+
+// BulkRequest представляет пакетный запрос на создание, обновление и удаление книг
+type BulkRequest struct {
+	Mode       string          `json:"mode"`
+	Operations []BulkOperation `json:"operations" binding:"required,min=1"`
+}
+
+// BulkItemResult представляет результат выполнения одной операции пакета
+type BulkItemResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	Status string `json:"status"`
+	ID     uint   `json:"id,omitempty"`
+	Book   *Book  `json:"book,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkResult представляет итог выполнения пакетного запроса
+type BulkResult struct {
+	Mode      string           `json:"mode"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// BookFilter представляет условия отбора книг для выгрузки каталога
+type BookFilter struct {
+	// UpdatedFrom и UpdatedUntil ограничивают дату изменения книги:
+	// UpdatedFrom включается в интервал, UpdatedUntil - нет
+	UpdatedFrom  *time.Time
+	UpdatedUntil *time.Time
+
+	IDs       []uint
+	Query     string
+	Author    string
+	Publisher string
+	YearFrom  int
+	YearTo    int
+	Available *bool
+}
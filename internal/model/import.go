@@ -0,0 +1,30 @@
+package model
+
+// Действия, выполненные над строкой импорта
+const (
+	ImportActionCreated     = "created"
+	ImportActionUpdated     = "updated"
+	ImportActionWouldCreate = "would_create"
+	ImportActionWouldUpdate = "would_update"
+	ImportActionFailed      = "failed"
+)
+
+// ImportRowResult представляет результат импорта одной записи
+type ImportRowResult struct {
+	Line   int    `json:"line"`
+	ISBN   string `json:"isbn,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Action string `json:"action"`
+	ID     uint   `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportReport представляет отчет об импорте каталога
+type ImportReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// BookRevision представляет сохраненный снимок состояния книги
+type BookRevision struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	BookID      uint      `json:"book_id" gorm:"not null;uniqueIndex:idx_book_revisions_book_revision"`
+	Revision    int       `json:"revision" gorm:"not null;uniqueIndex:idx_book_revisions_book_revision"`
+	Title       string    `json:"title"`
+	Author      string    `json:"author"`
+	ISBN        string    `json:"isbn"`
+	Description string    `json:"description"`
+	Year        int       `json:"year"`
+	Publisher   string    `json:"publisher"`
+	Available   bool      `json:"available"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// FieldChange представляет изменение одного поля между двумя ревизиями
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// RevisionDiff представляет разницу между двумя ревизиями книги
+type RevisionDiff struct {
+	BookID  uint          `json:"book_id"`
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// NewBookRevision создает снимок текущего состояния книги
+func NewBookRevision(book *Book) *BookRevision {
+	return &BookRevision{
+		BookID:      book.ID,
+		Title:       book.Title,
+		Author:      book.Author,
+		ISBN:        book.ISBN,
+		Description: book.Description,
+		Year:        book.Year,
+		Publisher:   book.Publisher,
+		Available:   book.Available,
+	}
+}
+
+// ToBookCreate возвращает данные ревизии в виде, пригодном для обновления книги
+func (r *BookRevision) ToBookCreate() *BookCreate {
+	return &BookCreate{
+		Title:       r.Title,
+		Author:      r.Author,
+		ISBN:        r.ISBN,
+		Description: r.Description,
+		Year:        r.Year,
+		Publisher:   r.Publisher,
+	}
+}
+
+// Diff возвращает список полей, отличающихся в ревизии other относительно r
+func (r *BookRevision) Diff(other *BookRevision) []FieldChange {
+	changes := []FieldChange{}
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+
+	add("title", r.Title, other.Title)
+	add("author", r.Author, other.Author)
+	add("isbn", r.ISBN, other.ISBN)
+	add("description", r.Description, other.Description)
+	add("year", strconv.Itoa(r.Year), strconv.Itoa(other.Year))
+	add("publisher", r.Publisher, other.Publisher)
+	add("available", strconv.FormatBool(r.Available), strconv.FormatBool(other.Available))
+
+	return changes
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookRevisionDiff(t *testing.T) {
+	base := BookRevision{
+		Title:     "Война и мир",
+		Author:    "Лев Толстой",
+		ISBN:      "1234567890",
+		Year:      1869,
+		Publisher: "Русский вестник",
+		Available: true,
+	}
+
+	testCases := []struct {
+		name     string
+		modify   func(r *BookRevision)
+		expected []FieldChange
+	}{
+		{
+			name:     "Одинаковые ревизии",
+			modify:   func(r *BookRevision) {},
+			expected: []FieldChange{},
+		},
+		{
+			name: "Изменены название и год",
+			modify: func(r *BookRevision) {
+				r.Title = "Война и мир. Том 1"
+				r.Year = 1873
+			},
+			expected: []FieldChange{
+				{Field: "title", From: "Война и мир", To: "Война и мир. Том 1"},
+				{Field: "year", From: "1869", To: "1873"},
+			},
+		},
+		{
+			name: "Изменена доступность",
+			modify: func(r *BookRevision) {
+				r.Available = false
+			},
+			expected: []FieldChange{
+				{Field: "available", From: "true", To: "false"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			other := base
+			tc.modify(&other)
+
+			// Act
+			changes := base.Diff(&other)
+
+			// Assert
+			assert.Equal(t, tc.expected, changes)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package model
+
+// Роли пользователей
+const (
+	// RolePatron - читатель: просматривает каталог и свою учетную запись
+	RolePatron = "patron"
+	// RoleLibrarian - библиотекарь: добавляет, изменяет и импортирует книги
+	RoleLibrarian = "librarian"
+	// RoleAdmin - администратор: удаляет книги и управляет пользователями
+	RoleAdmin = "admin"
+)
+
+// Permission представляет право на действие с ресурсом
+type Permission string
+
+// Права доступа
+const (
+	PermissionReadBooks   Permission = "books:read"
+	PermissionWriteBooks  Permission = "books:write"
+	PermissionDeleteBooks Permission = "books:delete"
+	PermissionManageUsers Permission = "users:manage"
+	// PermissionManageAPIKeys разрешает выпуск и отзыв API-ключей
+	PermissionManageAPIKeys Permission = "api-keys:manage"
+	// PermissionViewSystem разрешает просмотр состояния сервиса для мониторинга
+	PermissionViewSystem Permission = "system:read"
+)
+
+// rolePermissions задает права каждой роли. Каждая следующая роль включает права предыдущей.
+var rolePermissions = map[string][]Permission{
+	RolePatron:    {PermissionReadBooks},
+	RoleLibrarian: {PermissionReadBooks, PermissionWriteBooks},
+	RoleAdmin:     {PermissionReadBooks, PermissionWriteBooks, PermissionDeleteBooks, PermissionManageUsers, PermissionManageAPIKeys, PermissionViewSystem},
+}
+
+// roleOrder перечисляет роли по возрастанию прав
+var roleOrder = []string{RolePatron, RoleLibrarian, RoleAdmin}
+
+// Roles возвращает все роли по возрастанию прав
+func Roles() []string {
+	return append([]string(nil), roleOrder...)
+}
+
+// HighestRole возвращает роль с наибольшими правами. Неизвестные роли пропускаются.
+func HighestRole(roles ...string) string {
+	highest, rank := "", -1
+	for _, role := range roles {
+		for i, r := range roleOrder {
+			if r == role && i > rank {
+				highest, rank = role, i
+			}
+		}
+	}
+	return highest
+}
+
+// ValidPermission проверяет, что право существует
+func ValidPermission(permission Permission) bool {
+	return HasPermission(RoleAdmin, permission)
+}
+
+// ValidRole проверяет, что роль существует
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission проверяет, есть ли у роли право
+func HasPermission(role string, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleUpdate представляет запрос на изменение роли пользователя
+type RoleUpdate struct {
+	Role string `json:"role" binding:"required"`
+}
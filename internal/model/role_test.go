@@ -0,0 +1,48 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasPermission(t *testing.T) {
+	testCases := []struct {
+		name       string
+		role       string
+		permission Permission
+		expected   bool
+	}{
+		{name: "Читатель просматривает каталог", role: RolePatron, permission: PermissionReadBooks, expected: true},
+		{name: "Читатель не изменяет книги", role: RolePatron, permission: PermissionWriteBooks, expected: false},
+		{name: "Библиотекарь изменяет книги", role: RoleLibrarian, permission: PermissionWriteBooks, expected: true},
+		{name: "Библиотекарь не удаляет книги", role: RoleLibrarian, permission: PermissionDeleteBooks, expected: false},
+		{name: "Администратор удаляет книги", role: RoleAdmin, permission: PermissionDeleteBooks, expected: true},
+		{name: "Администратор управляет пользователями", role: RoleAdmin, permission: PermissionManageUsers, expected: true},
+		{name: "Неизвестная роль", role: "guest", permission: PermissionReadBooks, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			result := HasPermission(tc.role, tc.permission)
+
+			// Assert
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestPrincipalCan(t *testing.T) {
+	// Arrange
+	var anonymous *Principal
+	admin := &Principal{UserID: 1, Role: RoleAdmin}
+
+	apiKey := &Principal{APIKeyID: 3, Role: RoleAdmin, Scopes: []Permission{PermissionReadBooks, PermissionWriteBooks}}
+
+	// Act & Assert
+	assert.False(t, anonymous.Can(PermissionReadBooks))
+	assert.True(t, admin.Can(PermissionManageUsers))
+	assert.True(t, apiKey.Can(PermissionWriteBooks))
+	assert.False(t, apiKey.Can(PermissionDeleteBooks), "права API-ключа ограничены его областями")
+}
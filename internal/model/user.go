@@ -0,0 +1,104 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// User представляет пользователя API. Пользователи, вошедшие через внешний
+// провайдер, связаны с ним через ExternalID и не имеют пароля.
+type User struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Username     string    `json:"username" gorm:"not null;unique"`
+	PasswordHash string    `json:"-" gorm:"not null"`
+	Role         string    `json:"role" gorm:"not null;default:patron"`
+	ExternalID   *string   `json:"external_id,omitempty" gorm:"uniqueIndex"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// RefreshToken представляет выданный refresh-токен. Хранится только его идентификатор (jti),
+// что позволяет отозвать токен при выходе или повторном использовании.
+type RefreshToken struct {
+	ID        string     `json:"-" gorm:"primaryKey"`
+	UserID    uint       `json:"-" gorm:"not null;index"`
+	ExpiresAt time.Time  `json:"-" gorm:"not null"`
+	RevokedAt *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+}
+
+// Principal представляет аутентифицированного пользователя запроса.
+// Для запросов с API-ключом заполняется APIKeyID, а права определяются областями ключа.
+type Principal struct {
+	UserID   uint   `json:"user_id,omitempty"`
+	Username string `json:"username"`
+	Role     string `json:"role,omitempty"`
+	APIKeyID uint   `json:"api_key_id,omitempty"`
+	// Scopes задает права API-ключа
+	Scopes []Permission `json:"scopes,omitempty"`
+}
+
+// Can проверяет, есть ли у пользователя право
+func (p *Principal) Can(permission Permission) bool {
+	if p == nil {
+		return false
+	}
+	if p.APIKeyID != 0 {
+		for _, scope := range p.Scopes {
+			if scope == permission {
+				return true
+			}
+		}
+		return false
+	}
+	return HasPermission(p.Role, permission)
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal возвращает контекст с пользователем запроса
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
+
+// PrincipalFromContext возвращает пользователя запроса или nil для анонимного запроса
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return p
+}
+
+// ExternalIdentity представляет пользователя, подтвержденного внешним провайдером
+type ExternalIdentity struct {
+	Issuer   string
+	Subject  string
+	Username string
+	Email    string
+	Role     string
+}
+
+// UserCreate представляет данные для создания пользователя
+type UserCreate struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role"`
+}
+
+// LoginRequest представляет данные для входа
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest представляет запрос на обновление или отзыв refresh-токена
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenPair представляет выданные access- и refresh-токены
+type TokenPair struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresIn    int       `json:"expires_in"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
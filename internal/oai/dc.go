@@ -0,0 +1,72 @@
+package oai
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// Identifier возвращает идентификатор OAI для книги, например oai:book-library.local:book/42
+func Identifier(repositoryID string, bookID uint) string {
+	return fmt.Sprintf("oai:%s:book/%d", repositoryID, bookID)
+}
+
+// ParseIdentifier извлекает ID книги из идентификатора OAI
+func ParseIdentifier(repositoryID, identifier string) (uint, bool) {
+	rest, ok := strings.CutPrefix(identifier, "oai:"+repositoryID+":book/")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(rest, 10, 32)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// NewHeader создает заголовок записи для книги
+func NewHeader(repositoryID string, book *model.Book) Header {
+	return Header{
+		Identifier: Identifier(repositoryID, book.ID),
+		Datestamp:  FormatDatestamp(book.UpdatedAt),
+	}
+}
+
+// NewRecord создает запись oai_dc для книги
+func NewRecord(repositoryID string, book *model.Book) Record {
+	return Record{
+		Header:   NewHeader(repositoryID, book),
+		Metadata: Metadata{DC: NewDublinCore(book)},
+	}
+}
+
+// NewDublinCore представляет книгу в формате простого Dublin Core
+func NewDublinCore(book *model.Book) *DublinCore {
+	dc := &DublinCore{
+		XmlnsOAIDC:     OAIDCNamespace,
+		XmlnsDC:        DCNamespace,
+		XmlnsXSI:       XSINamespace,
+		SchemaLocation: OAIDCSchemaLocation,
+		Type:           []string{"Text"},
+	}
+
+	appendNotEmpty := func(values *[]string, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			*values = append(*values, value)
+		}
+	}
+	appendNotEmpty(&dc.Title, book.Title)
+	appendNotEmpty(&dc.Creator, book.Author)
+	appendNotEmpty(&dc.Publisher, book.Publisher)
+	appendNotEmpty(&dc.Description, book.Description)
+	if book.Year > 0 {
+		dc.Date = append(dc.Date, strconv.Itoa(book.Year))
+	}
+	if book.ISBN != "" {
+		dc.Identifier = append(dc.Identifier, "urn:isbn:"+book.ISBN)
+	}
+
+	return dc
+}
@@ -0,0 +1,163 @@
+// Package oai содержит типы протокола OAI-PMH 2.0 и представление книг в формате oai_dc
+package oai
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Пространства имен и схемы OAI-PMH и Dublin Core
+const (
+	Namespace           = "http://www.openarchives.org/OAI/2.0/"
+	SchemaLocation      = "http://www.openarchives.org/OAI/2.0/ http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd"
+	XSINamespace        = "http://www.w3.org/2001/XMLSchema-instance"
+	OAIDCNamespace      = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	OAIDCSchema         = "http://www.openarchives.org/OAI/2.0/oai_dc.xsd"
+	OAIDCSchemaLocation = OAIDCNamespace + " " + OAIDCSchema
+	DCNamespace         = "http://purl.org/dc/elements/1.1/"
+)
+
+// MetadataPrefixDC задает единственный поддерживаемый формат метаданных
+const MetadataPrefixDC = "oai_dc"
+
+// Коды ошибок OAI-PMH
+const (
+	ErrBadArgument             = "badArgument"
+	ErrBadResumptionToken      = "badResumptionToken"
+	ErrBadVerb                 = "badVerb"
+	ErrCannotDisseminateFormat = "cannotDisseminateFormat"
+	ErrIDDoesNotExist          = "idDoesNotExist"
+	ErrNoRecordsMatch          = "noRecordsMatch"
+	ErrNoSetHierarchy          = "noSetHierarchy"
+)
+
+// Response представляет корневой элемент ответа OAI-PMH
+type Response struct {
+	XMLName             xml.Name `xml:"OAI-PMH"`
+	Xmlns               string   `xml:"xmlns,attr"`
+	XmlnsXSI            string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation      string   `xml:"xsi:schemaLocation,attr"`
+	ResponseDate        string   `xml:"responseDate"`
+	Request             Request  `xml:"request"`
+	Errors              []Error  `xml:"error,omitempty"`
+	Identify            *Identify
+	ListMetadataFormats *ListMetadataFormats
+	GetRecord           *GetRecord
+	ListIdentifiers     *ListIdentifiers
+	ListRecords         *ListRecords
+}
+
+// NewResponse создает ответ с заполненными пространствами имен и датой ответа
+func NewResponse(request Request, now time.Time) *Response {
+	return &Response{
+		Xmlns:          Namespace,
+		XmlnsXSI:       XSINamespace,
+		SchemaLocation: SchemaLocation,
+		ResponseDate:   FormatDatestamp(now),
+		Request:        request,
+	}
+}
+
+// Request повторяет параметры запроса в ответе
+type Request struct {
+	BaseURL         string `xml:",chardata"`
+	Verb            string `xml:"verb,attr,omitempty"`
+	Identifier      string `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string `xml:"metadataPrefix,attr,omitempty"`
+	From            string `xml:"from,attr,omitempty"`
+	Until           string `xml:"until,attr,omitempty"`
+	Set             string `xml:"set,attr,omitempty"`
+	ResumptionToken string `xml:"resumptionToken,attr,omitempty"`
+}
+
+// Error представляет ошибку OAI-PMH
+type Error struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+// Identify описывает репозиторий
+type Identify struct {
+	XMLName           xml.Name `xml:"Identify"`
+	RepositoryName    string   `xml:"repositoryName"`
+	BaseURL           string   `xml:"baseURL"`
+	ProtocolVersion   string   `xml:"protocolVersion"`
+	AdminEmail        string   `xml:"adminEmail"`
+	EarliestDatestamp string   `xml:"earliestDatestamp"`
+	DeletedRecord     string   `xml:"deletedRecord"`
+	Granularity       string   `xml:"granularity"`
+}
+
+// ListMetadataFormats перечисляет поддерживаемые форматы метаданных
+type ListMetadataFormats struct {
+	XMLName xml.Name         `xml:"ListMetadataFormats"`
+	Formats []MetadataFormat `xml:"metadataFormat"`
+}
+
+// MetadataFormat описывает формат метаданных
+type MetadataFormat struct {
+	Prefix    string `xml:"metadataPrefix"`
+	Schema    string `xml:"schema"`
+	Namespace string `xml:"metadataNamespace"`
+}
+
+// GetRecord содержит одну запись
+type GetRecord struct {
+	XMLName xml.Name `xml:"GetRecord"`
+	Record  Record   `xml:"record"`
+}
+
+// ListIdentifiers содержит страницу заголовков записей
+type ListIdentifiers struct {
+	XMLName         xml.Name         `xml:"ListIdentifiers"`
+	Headers         []Header         `xml:"header"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken"`
+}
+
+// ListRecords содержит страницу записей
+type ListRecords struct {
+	XMLName         xml.Name         `xml:"ListRecords"`
+	Records         []Record         `xml:"record"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken"`
+}
+
+// Record представляет запись OAI-PMH
+type Record struct {
+	Header   Header   `xml:"header"`
+	Metadata Metadata `xml:"metadata"`
+}
+
+// Header представляет заголовок записи
+type Header struct {
+	Identifier string `xml:"identifier"`
+	Datestamp  string `xml:"datestamp"`
+}
+
+// Metadata содержит метаданные записи
+type Metadata struct {
+	DC *DublinCore
+}
+
+// ResumptionToken представляет маркер продолжения выборки.
+// Пустое значение маркера означает последнюю страницу.
+type ResumptionToken struct {
+	Value            string `xml:",chardata"`
+	CompleteListSize int64  `xml:"completeListSize,attr"`
+	Cursor           int    `xml:"cursor,attr"`
+}
+
+// DublinCore представляет метаданные книги в формате oai_dc
+type DublinCore struct {
+	XMLName        xml.Name `xml:"oai_dc:dc"`
+	XmlnsOAIDC     string   `xml:"xmlns:oai_dc,attr"`
+	XmlnsDC        string   `xml:"xmlns:dc,attr"`
+	XmlnsXSI       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Title          []string `xml:"dc:title"`
+	Creator        []string `xml:"dc:creator"`
+	Publisher      []string `xml:"dc:publisher"`
+	Date           []string `xml:"dc:date"`
+	Description    []string `xml:"dc:description"`
+	Identifier     []string `xml:"dc:identifier"`
+	Type           []string `xml:"dc:type"`
+}
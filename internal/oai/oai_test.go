@@ -0,0 +1,136 @@
+package oai
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRange(t *testing.T) {
+	testCases := []struct {
+		name          string
+		from          string
+		until         string
+		expectedFrom  string
+		expectedUntil string
+		expectedError bool
+	}{
+		{
+			name:          "Точность до дня включает весь день until",
+			from:          "2024-01-01",
+			until:         "2024-01-31",
+			expectedFrom:  "2024-01-01T00:00:00Z",
+			expectedUntil: "2024-02-01T00:00:00Z",
+		},
+		{
+			name:          "Точность до секунды",
+			from:          "2024-01-01T10:00:00Z",
+			until:         "2024-01-01T12:30:00Z",
+			expectedFrom:  "2024-01-01T10:00:00Z",
+			expectedUntil: "2024-01-01T12:30:01Z",
+		},
+		{
+			name:          "Разная точность",
+			from:          "2024-01-01",
+			until:         "2024-01-01T12:30:00Z",
+			expectedError: true,
+		},
+		{
+			name:          "Неверная дата",
+			from:          "01.01.2024",
+			expectedError: true,
+		},
+		{
+			name:          "from позже until",
+			from:          "2024-02-01",
+			until:         "2024-01-01",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			r, err := ParseRange(tc.from, tc.until)
+
+			// Assert
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedFrom, FormatDatestamp(*r.From))
+			assert.Equal(t, tc.expectedUntil, FormatDatestamp(*r.Until))
+		})
+	}
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+	// Arrange
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	token := &Token{
+		MetadataPrefix: MetadataPrefixDC,
+		From:           &from,
+		Cursor:         Cursor{UpdatedAt: time.Date(2024, 3, 1, 10, 0, 0, 123456000, time.UTC), ID: 42, Offset: 100},
+	}
+
+	// Act
+	decoded, err := DecodeToken(token.Encode())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, token.Cursor.ID, decoded.Cursor.ID)
+	assert.Equal(t, token.Cursor.Offset, decoded.Cursor.Offset)
+	assert.True(t, token.Cursor.UpdatedAt.Equal(decoded.Cursor.UpdatedAt))
+	assert.True(t, from.Equal(*decoded.From))
+	assert.Nil(t, decoded.Until)
+
+	_, err = DecodeToken("не маркер")
+	assert.Error(t, err)
+}
+
+func TestParseIdentifier(t *testing.T) {
+	id, ok := ParseIdentifier("library.example", Identifier("library.example", 42))
+	assert.True(t, ok)
+	assert.Equal(t, uint(42), id)
+
+	_, ok = ParseIdentifier("library.example", "oai:other.example:book/42")
+	assert.False(t, ok)
+
+	_, ok = ParseIdentifier("library.example", "oai:library.example:book/abc")
+	assert.False(t, ok)
+}
+
+func TestRecordXML(t *testing.T) {
+	// Arrange
+	book := &model.Book{
+		ID:        1,
+		Title:     "Война и мир",
+		Author:    "Лев Толстой",
+		ISBN:      "9785171147440",
+		Year:      1869,
+		Publisher: "АСТ",
+		UpdatedAt: time.Date(2024, 5, 15, 21, 0, 0, 0, time.UTC),
+	}
+	resp := NewResponse(Request{BaseURL: "http://localhost/oai", Verb: "GetRecord"}, time.Now())
+	resp.GetRecord = &GetRecord{Record: NewRecord("library.example", book)}
+
+	// Act
+	data, err := xml.Marshal(resp)
+
+	// Assert
+	require.NoError(t, err)
+	body := string(data)
+	assert.Contains(t, body, `<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/"`)
+	assert.Contains(t, body, `<identifier>oai:library.example:book/1</identifier>`)
+	assert.Contains(t, body, `<datestamp>2024-05-15T21:00:00Z</datestamp>`)
+	assert.Contains(t, body, `<oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/"`)
+	assert.Contains(t, body, `<dc:title>Война и мир</dc:title>`)
+	assert.Contains(t, body, `<dc:identifier>urn:isbn:9785171147440</dc:identifier>`)
+	assert.NotContains(t, body, `<dc:description>`)
+	assert.NotContains(t, body, `<error`)
+}
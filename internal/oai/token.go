@@ -0,0 +1,120 @@
+package oai
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Форматы дат OAI-PMH
+const (
+	dayGranularity    = "2006-01-02"
+	secondGranularity = "2006-01-02T15:04:05Z"
+
+	// Granularity объявляется в ответе Identify
+	Granularity = "YYYY-MM-DDThh:mm:ssZ"
+)
+
+var errBadDatestamp = errors.New("неверный формат даты")
+
+// FormatDatestamp форматирует время с точностью до секунды в UTC
+func FormatDatestamp(t time.Time) string {
+	return t.UTC().Format(secondGranularity)
+}
+
+// Range задает интервал выборки по дате изменения записи.
+// From включается в интервал, Until - нет.
+type Range struct {
+	From  *time.Time
+	Until *time.Time
+}
+
+// ParseRange разбирает аргументы from и until. Обе даты должны иметь
+// одинаковую точность; until включает всю указанную секунду или день.
+func ParseRange(from, until string) (Range, error) {
+	var r Range
+	var fromLayout, untilLayout string
+	var err error
+
+	if from != "" {
+		var t time.Time
+		if t, fromLayout, err = parseDatestamp(from); err != nil {
+			return r, err
+		}
+		r.From = &t
+	}
+	if until != "" {
+		var t time.Time
+		if t, untilLayout, err = parseDatestamp(until); err != nil {
+			return r, err
+		}
+		if untilLayout == dayGranularity {
+			t = t.AddDate(0, 0, 1)
+		} else {
+			t = t.Add(time.Second)
+		}
+		r.Until = &t
+	}
+
+	if fromLayout != "" && untilLayout != "" && fromLayout != untilLayout {
+		return r, errors.New("аргументы from и until имеют разную точность")
+	}
+	if r.From != nil && r.Until != nil && !r.From.Before(*r.Until) {
+		return r, errors.New("аргумент from позже until")
+	}
+	return r, nil
+}
+
+func parseDatestamp(value string) (time.Time, string, error) {
+	for _, layout := range []string{secondGranularity, dayGranularity} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", errBadDatestamp
+}
+
+// Cursor указывает позицию выборки: последнюю выданную книгу
+// и количество уже выданных записей
+type Cursor struct {
+	UpdatedAt time.Time `json:"u"`
+	ID        uint      `json:"i"`
+	Offset    int       `json:"o"`
+}
+
+// Token содержит состояние выборки между запросами. Маркер не хранится
+// на сервере, поэтому продолжение не зависит от перезапусков и реплик.
+type Token struct {
+	MetadataPrefix string     `json:"m,omitempty"`
+	From           *time.Time `json:"f,omitempty"`
+	Until          *time.Time `json:"t,omitempty"`
+	Cursor         Cursor     `json:"c"`
+}
+
+// Encode кодирует маркер в строку для resumptionToken
+func (t *Token) Encode() string {
+	data, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeToken восстанавливает маркер из строки resumptionToken
+func DecodeToken(value string) (*Token, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, err
+	}
+	if token.Cursor.ID == 0 {
+		return nil, errors.New("пустой маркер")
+	}
+	return &token, nil
+}
+
+// Range возвращает интервал выборки маркера
+func (t *Token) Range() Range {
+	return Range{From: t.From, Until: t.Until}
+}
@@ -0,0 +1,87 @@
+// Package gen собирает спецификацию OpenAPI 3 по аннотациям swag в обработчиках
+package gen
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+
+	"path/filepath"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/swaggo/swag"
+)
+
+// MainFile содержит общие аннотации API: название, версию и схемы авторизации
+const MainFile = "cmd/api/main.go"
+
+// packages ограничивает разбор зависимостей пакетами модуля и encoding/xml
+// (xml.Name в ответах OAI-PMH). Остальные зависимости не содержат типов API,
+// а их разбор занимает много времени; кроме того, в них есть пакеты с теми же
+// именами (golang.org/x/net/webdav/internal/xml), из-за которых swag не может
+// однозначно найти тип.
+const packages = "github.com/krawwwwy/book-library-api,encoding/xml"
+
+// searchDirs перечисляет каталоги с аннотированными обработчиками и моделями
+var searchDirs = []string{"cmd/api", "internal/api"}
+
+// Generate разбирает аннотации и возвращает спецификацию OpenAPI 3 в JSON.
+// swag собирает документ Swagger 2.0, который затем преобразуется в OpenAPI 3.
+// Типы из зависимостей swag находит относительно текущего каталога, поэтому
+// Generate вызывается из корня модуля.
+func Generate() ([]byte, error) {
+	parser := swag.New(
+		swag.SetParseDependency(1),
+		swag.ParseUsingGoList(true),
+		swag.SetPackagePrefix(packages),
+		swag.SetDebugger(log.New(io.Discard, "", 0)),
+	)
+	if err := parser.ParseAPIMultiSearchDir(searchDirs, filepath.Base(MainFile), 0); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(parser.GetSwagger())
+	if err != nil {
+		return nil, err
+	}
+	var doc2 openapi2.T
+	if err := json.Unmarshal(data, &doc2); err != nil {
+		return nil, err
+	}
+	doc3, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return nil, err
+	}
+	fixFileResponses(doc3)
+	if err := doc3.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	out, err := json.MarshalIndent(doc3, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// fixFileResponses заменяет тип file из Swagger 2.0, которого нет в OpenAPI 3,
+// на строку в формате binary
+func fixFileResponses(doc *openapi3.T) {
+	for _, item := range doc.Paths {
+		for _, op := range item.Operations() {
+			for _, response := range op.Responses {
+				if response.Value == nil {
+					continue
+				}
+				for _, media := range response.Value.Content {
+					if media.Schema != nil && media.Schema.Value != nil && media.Schema.Value.Type == "file" {
+						media.Schema.Value.Type = openapi3.TypeString
+						media.Schema.Value.Format = "binary"
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecUpToDate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("разбор аннотаций занимает несколько секунд")
+	}
+
+	// Arrange: Generate вызывается из корня модуля
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	root := filepath.Join(wd, "..", "..", "..")
+	require.NoError(t, os.Chdir(root))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	committed, err := os.ReadFile(filepath.Join(root, "internal", "openapi", "openapi.json"))
+	require.NoError(t, err)
+
+	// Act
+	generated, err := Generate()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, string(committed), string(generated), "спецификация устарела: выполните go generate ./internal/openapi")
+}
@@ -0,0 +1,46 @@
+// Package openapi содержит спецификацию OpenAPI 3, собранную по аннотациям
+// обработчиков, и Swagger UI для ее просмотра
+package openapi
+
+//go:generate go run ../../cmd/openapi -root ../..
+
+import (
+	_ "embed"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	swaggerFiles "github.com/swaggo/files"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed index.html
+var index []byte
+
+// Spec возвращает спецификацию в JSON
+func Spec() []byte {
+	return spec
+}
+
+// Load разбирает спецификацию
+func Load() (*openapi3.T, error) {
+	return openapi3.NewLoader().LoadFromData(spec)
+}
+
+// UI возвращает обработчик Swagger UI для путей относительно prefix. Главная
+// страница загружает спецификацию с /openapi.json, остальные файлы берутся
+// из сборки Swagger UI, встроенной в исполняемый файл.
+func UI(prefix string) http.Handler {
+	files := http.StripPrefix(prefix, http.FileServer(swaggerFiles.HTTP))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, prefix) {
+		case "", "/", "/index.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write(index)
+		default:
+			files.ServeHTTP(w, r)
+		}
+	})
+}
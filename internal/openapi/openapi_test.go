@@ -0,0 +1,21 @@
+package openapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	// Act
+	doc, err := Load()
+
+	// Assert
+	require.NoError(t, err)
+	assert.NoError(t, doc.Validate(context.Background()))
+	assert.Equal(t, "Book Library API", doc.Info.Title)
+	assert.NotNil(t, doc.Paths.Find("/api/books/{id}"))
+	assert.Contains(t, doc.Components.SecuritySchemes, "BearerAuth")
+}
@@ -0,0 +1,133 @@
+// Package ratelimit реализует ограничение частоты запросов по алгоритму token bucket
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit задает параметры корзины: скорость пополнения и емкость.
+// Емкость определяет, сколько запросов можно выполнить подряд.
+type Limit struct {
+	Rate  float64 // токенов в секунду
+	Burst int
+}
+
+// PerMinute создает ограничение в n запросов в минуту с емкостью burst
+func PerMinute(n, burst int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: burst}
+}
+
+// Result описывает решение по запросу
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter - время до полного восстановления корзины
+	ResetAfter time.Duration
+	// RetryAfter - время до появления следующего токена, если запрос отклонен
+	RetryAfter time.Duration
+}
+
+// Store хранит состояние корзин. MemoryStore подходит для одного экземпляра API;
+// для нескольких экземпляров нужна реализация поверх общего хранилища (например, Redis).
+type Store interface {
+	Take(key string, limit Limit) (Result, error)
+	// Peek возвращает решение, которое принял бы Take, не забирая токен
+	Peek(key string, limit Limit) (Result, error)
+}
+
+// bucket представляет состояние корзины клиента
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// idleTimeout задает время, после которого полная неиспользуемая корзина удаляется
+const idleTimeout = 10 * time.Minute
+
+// MemoryStore хранит корзины в памяти процесса
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryStore создает новый экземпляр MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Take забирает токен из корзины ключа
+func (s *MemoryStore) Take(key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.decide(key, limit, true), nil
+}
+
+// Peek проверяет корзину ключа, не забирая токен
+func (s *MemoryStore) Peek(key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.decide(key, limit, false), nil
+}
+
+// decide пополняет корзину ключа и принимает решение по запросу; при take
+// разрешенный запрос забирает токен
+func (s *MemoryStore) decide(key string, limit Limit, take bool) Result {
+	now := s.now()
+	s.sweep(now)
+
+	burst := float64(limit.Burst)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, updated: now}
+		s.buckets[key] = b
+	} else {
+		b.tokens = math.Min(burst, b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+		b.updated = now
+	}
+
+	result := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		if take {
+			b.tokens--
+		}
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.ResetAfter = secondsToDuration((burst - b.tokens) / limit.Rate)
+	return result
+}
+
+// sweep удаляет давно не использованные корзины не чаще раза в idleTimeout.
+// Через idleTimeout без запросов корзина восстанавливается полностью при любом разумном лимите.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < idleTimeout {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= idleTimeout {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	if math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// Rule задает ограничение для группы маршрутов с общим префиксом пути
+type Rule struct {
+	Name       string
+	PathPrefix string
+	Limit      Limit
+}
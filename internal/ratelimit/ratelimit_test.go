@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	// Arrange: 60 запросов в минуту, не больше 3 подряд
+	limit := PerMinute(60, 3)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	// Act & Assert: емкость корзины расходуется полностью
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take("ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+		assert.Equal(t, 3, result.Limit)
+	}
+
+	// Следующий запрос отклоняется до появления токена
+	result, _ := store.Take("ip:10.0.0.1", limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.ResetAfter)
+
+	// Другой клиент имеет собственную корзину
+	result, _ = store.Take("ip:10.0.0.2", limit)
+	assert.True(t, result.Allowed)
+
+	// Через секунду появляется один токен
+	now = now.Add(time.Second)
+	result, _ = store.Take("ip:10.0.0.1", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// Корзина не наполняется сверх емкости
+	now = now.Add(time.Hour)
+	result, _ = store.Take("ip:10.0.0.1", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestMemoryStorePeek(t *testing.T) {
+	// Arrange
+	limit := PerMinute(60, 2)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	// Act & Assert: проверка не расходует токены
+	for i := 0; i < 3; i++ {
+		result, err := store.Peek("ip:10.0.0.1", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Remaining)
+	}
+
+	_, _ = store.Take("ip:10.0.0.1", limit)
+	_, _ = store.Take("ip:10.0.0.1", limit)
+	result, _ := store.Peek("ip:10.0.0.1", limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+}
+
+func TestMemoryStoreSweepsIdleBuckets(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	_, _ = store.Take("ip:10.0.0.1", PerMinute(60, 3))
+
+	// Act
+	now = now.Add(idleTimeout)
+	_, _ = store.Take("ip:10.0.0.2", PerMinute(60, 3))
+
+	// Assert
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "ip:10.0.0.2")
+}
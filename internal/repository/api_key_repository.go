@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository представляет репозиторий для работы с API-ключами
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository создает новый экземпляр APIKeyRepository
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create сохраняет новый API-ключ
+func (r *APIKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// GetAll получает все API-ключи
+func (r *APIKeyRepository) GetAll(ctx context.Context) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.db.WithContext(ctx).Order("id").Find(&keys).Error
+	return keys, err
+}
+
+// GetByID получает API-ключ по ID
+func (r *APIKeyRepository) GetByID(ctx context.Context, id uint) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.WithContext(ctx).First(&key, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetByPrefix получает API-ключ по префиксу
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.WithContext(ctx).Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Revoke отзывает API-ключ
+func (r *APIKeyRepository) Revoke(ctx context.Context, id uint, revokedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt).Error
+}
+
+// TouchLastUsed обновляет время последнего использования ключа
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/krawwwwy/book-library-api/internal/model"
 	"gorm.io/gorm"
 )
@@ -8,22 +11,38 @@ import (
 // BookRepository представляет репозиторий для работы с книгами
 type BookRepository struct {
 	db *gorm.DB
+	// like задает оператор поиска подстроки без учета регистра
+	like string
 }
 
 // NewBookRepository создает новый экземпляр BookRepository
 func NewBookRepository(db *gorm.DB) *BookRepository {
-	return &BookRepository{db: db}
+	return &BookRepository{db: db, like: likeOperator(db)}
+}
+
+// likeOperator возвращает оператор поиска без учета регистра. В SQLite нет ILIKE,
+// а LIKE не различает регистр только для латиницы.
+func likeOperator(db *gorm.DB) string {
+	if db.Dialector.Name() == "sqlite" {
+		return "LIKE"
+	}
+	return "ILIKE"
 }
 
-// Create создает новую книгу
-func (r *BookRepository) Create(book *model.Book) error {
-	return r.db.Create(book).Error
+// Create создает новую книгу и сохраняет ее первую ревизию
+func (r *BookRepository) Create(ctx context.Context, book *model.Book) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(book).Error; err != nil {
+			return err
+		}
+		return createRevision(tx, book)
+	})
 }
 
 // GetByID получает книгу по ID
-func (r *BookRepository) GetByID(id uint) (*model.Book, error) {
+func (r *BookRepository) GetByID(ctx context.Context, id uint) (*model.Book, error) {
 	var book model.Book
-	err := r.db.First(&book, id).Error
+	err := r.db.WithContext(ctx).First(&book, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -31,36 +50,150 @@ func (r *BookRepository) GetByID(id uint) (*model.Book, error) {
 }
 
 // GetAll получает все книги с пагинацией
-func (r *BookRepository) GetAll(page, pageSize int) ([]model.Book, error) {
+func (r *BookRepository) GetAll(ctx context.Context, page, pageSize int) ([]model.Book, error) {
 	var books []model.Book
 	offset := (page - 1) * pageSize
-	err := r.db.Offset(offset).Limit(pageSize).Find(&books).Error
+	err := r.db.WithContext(ctx).Offset(offset).Limit(pageSize).Find(&books).Error
 	return books, err
 }
 
-// Update обновляет информацию о книге
-func (r *BookRepository) Update(book *model.Book) error {
-	return r.db.Save(book).Error
+// Update обновляет информацию о книге и сохраняет новую ревизию
+func (r *BookRepository) Update(ctx context.Context, book *model.Book) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(book).Error; err != nil {
+			return err
+		}
+		return createRevision(tx, book)
+	})
 }
 
 // Delete удаляет книгу по ID
-func (r *BookRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Book{}, id).Error
+func (r *BookRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.Book{}, id).Error
 }
 
 // GetByISBN получает книгу по ISBN
-func (r *BookRepository) GetByISBN(isbn string) (*model.Book, error) {
+func (r *BookRepository) GetByISBN(ctx context.Context, isbn string) (*model.Book, error) {
 	var book model.Book
-	err := r.db.Where("isbn = ?", isbn).First(&book).Error
+	err := r.db.WithContext(ctx).Where("isbn = ?", isbn).First(&book).Error
 	if err != nil {
 		return nil, err
 	}
 	return &book, nil
 }
 
+// GetByISBNs получает книги с указанными ISBN одним запросом
+func (r *BookRepository) GetByISBNs(ctx context.Context, isbns []string) ([]model.Book, error) {
+	var books []model.Book
+	if len(isbns) == 0 {
+		return books, nil
+	}
+	err := r.db.WithContext(ctx).Where("isbn IN ?", isbns).Find(&books).Error
+	return books, err
+}
+
+// GetByIDs получает книги с указанными ID одним запросом
+func (r *BookRepository) GetByIDs(ctx context.Context, ids []uint) ([]model.Book, error) {
+	var books []model.Book
+	if len(ids) == 0 {
+		return books, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&books).Error
+	return books, err
+}
+
+// Transaction выполняет fn в транзакции, передавая ей репозиторий, привязанный к этой транзакции.
+// Если fn возвращает ошибку, транзакция откатывается.
+func (r *BookRepository) Transaction(ctx context.Context, fn func(store BookStore) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewBookRepository(tx))
+	})
+}
+
+// FindInBatches последовательно передает в fn книги, удовлетворяющие фильтру,
+// порциями по batchSize в порядке возрастания ID
+func (r *BookRepository) FindInBatches(ctx context.Context, filter *model.BookFilter, batchSize int, fn func(books []model.Book) error) error {
+	var books []model.Book
+	return r.applyFilter(r.db.WithContext(ctx), filter).FindInBatches(&books, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(books)
+	}).Error
+}
+
+// ListByUpdatedAt получает до limit книг, удовлетворяющих фильтру, в порядке
+// изменения. Выборка продолжается после книги afterID, измененной в afterUpdatedAt;
+// нулевой afterID означает начало выборки.
+func (r *BookRepository) ListByUpdatedAt(ctx context.Context, filter *model.BookFilter, afterUpdatedAt time.Time, afterID uint, limit int) ([]model.Book, error) {
+	var books []model.Book
+	query := r.applyFilter(r.db.WithContext(ctx), filter)
+	if afterID != 0 {
+		query = query.Where("updated_at > ? OR (updated_at = ? AND id > ?)", afterUpdatedAt, afterUpdatedAt, afterID)
+	}
+	err := query.Order("updated_at, id").Limit(limit).Find(&books).Error
+	return books, err
+}
+
+// Count возвращает количество книг, удовлетворяющих фильтру
+func (r *BookRepository) Count(ctx context.Context, filter *model.BookFilter) (int64, error) {
+	var count int64
+	err := r.applyFilter(r.db.WithContext(ctx).Model(&model.Book{}), filter).Count(&count).Error
+	return count, err
+}
+
+// EarliestUpdatedAt возвращает самую раннюю дату изменения книги.
+// Для пустого каталога возвращается нулевое время.
+func (r *BookRepository) EarliestUpdatedAt(ctx context.Context) (time.Time, error) {
+	// Первая запись по индексу вместо MIN: результат агрегата SQLite возвращает строкой
+	var books []model.Book
+	err := r.db.WithContext(ctx).Select("updated_at").Order("updated_at").Limit(1).Find(&books).Error
+	if err != nil || len(books) == 0 {
+		return time.Time{}, err
+	}
+	return books[0].UpdatedAt, nil
+}
+
 // Search ищет книги по названию или автору
-func (r *BookRepository) Search(query string) ([]model.Book, error) {
+func (r *BookRepository) Search(ctx context.Context, query string) ([]model.Book, error) {
 	var books []model.Book
-	err := r.db.Where("title ILIKE ? OR author ILIKE ?", "%"+query+"%", "%"+query+"%").Find(&books).Error
+	err := r.db.WithContext(ctx).Where(r.matchTitleOrAuthor(), "%"+query+"%", "%"+query+"%").Find(&books).Error
 	return books, err
-} 
\ No newline at end of file
+}
+
+// matchTitleOrAuthor возвращает условие поиска по названию или автору
+func (r *BookRepository) matchTitleOrAuthor() string {
+	return "title " + r.like + " ? OR author " + r.like + " ?"
+}
+
+// applyFilter добавляет к запросу условия фильтра
+func (r *BookRepository) applyFilter(db *gorm.DB, filter *model.BookFilter) *gorm.DB {
+	if filter == nil {
+		return db
+	}
+	if len(filter.IDs) > 0 {
+		db = db.Where("id IN ?", filter.IDs)
+	}
+	if filter.Query != "" {
+		db = db.Where(r.matchTitleOrAuthor(), "%"+filter.Query+"%", "%"+filter.Query+"%")
+	}
+	if filter.Author != "" {
+		db = db.Where("author "+r.like+" ?", "%"+filter.Author+"%")
+	}
+	if filter.Publisher != "" {
+		db = db.Where("publisher "+r.like+" ?", "%"+filter.Publisher+"%")
+	}
+	if filter.YearFrom != 0 {
+		db = db.Where("year >= ?", filter.YearFrom)
+	}
+	if filter.YearTo != 0 {
+		db = db.Where("year <= ?", filter.YearTo)
+	}
+	if filter.Available != nil {
+		db = db.Where("available = ?", *filter.Available)
+	}
+	if filter.UpdatedFrom != nil {
+		db = db.Where("updated_at >= ?", *filter.UpdatedFrom)
+	}
+	if filter.UpdatedUntil != nil {
+		db = db.Where("updated_at < ?", *filter.UpdatedUntil)
+	}
+	return db
+}
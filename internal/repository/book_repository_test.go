@@ -1,43 +1,42 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/krawwwwy/book-library-api/internal/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
+// BookRepositoryTestSuite проверяет одинаковое поведение всех реализаций BookStore
 type BookRepositoryTestSuite struct {
 	suite.Suite
-	db   *gorm.DB
-	repo *BookRepository
+	newStores func(t *testing.T) (BookStore, RevisionStore)
+	store     BookStore
+	revisions RevisionStore
 }
 
-func (s *BookRepositoryTestSuite) SetupSuite() {
-	// Подключение к тестовой базе данных
-	dsn := "host=localhost user=postgres password=postgres dbname=book_library_test port=5432 sslmode=disable"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		s.T().Fatal(err)
-	}
-	s.db = db
-
-	// Миграция схемы
-	err = s.db.AutoMigrate(&model.Book{})
-	if err != nil {
-		s.T().Fatal(err)
-	}
-
-	s.repo = NewBookRepository(s.db)
+func (s *BookRepositoryTestSuite) SetupTest() {
+	// Каждый тест получает пустое хранилище
+	s.store, s.revisions = s.newStores(s.T())
 }
 
-func (s *BookRepositoryTestSuite) TearDownTest() {
-	// Очистка таблицы после каждого теста
-	s.db.Exec("TRUNCATE TABLE books")
+func (s *BookRepositoryTestSuite) createBooks(books ...model.Book) []model.Book {
+	for i := range books {
+		books[i].Available = true
+		require.NoError(s.T(), s.store.Create(context.Background(), &books[i]))
+	}
+	return books
 }
 
 func (s *BookRepositoryTestSuite) TestCreateBook() {
@@ -54,15 +53,14 @@ func (s *BookRepositoryTestSuite) TestCreateBook() {
 	}
 
 	// Act
-	err := s.repo.Create(book)
+	err := s.store.Create(context.Background(), book)
 
 	// Assert
 	assert.NoError(s.T(), err)
 	assert.NotZero(s.T(), book.ID)
 
-	// Проверяем, что книга действительно создана в базе
-	var found model.Book
-	err = s.db.First(&found, book.ID).Error
+	// Проверяем, что книга действительно сохранена
+	found, err := s.store.GetByID(context.Background(), book.ID)
 	assert.NoError(s.T(), err)
 	assert.Equal(s.T(), book.Title, found.Title)
 	assert.Equal(s.T(), book.Author, found.Author)
@@ -71,16 +69,10 @@ func (s *BookRepositoryTestSuite) TestCreateBook() {
 
 func (s *BookRepositoryTestSuite) TestGetByID() {
 	// Arrange
-	book := &model.Book{
-		Title:     "Тестовая книга",
-		Author:    "Тестовый автор",
-		ISBN:      "1234567890",
-		Available: true,
-	}
-	s.db.Create(book)
+	book := s.createBooks(model.Book{Title: "Тестовая книга", Author: "Тестовый автор", ISBN: "1234567890"})[0]
 
 	// Act
-	found, err := s.repo.GetByID(book.ID)
+	found, err := s.store.GetByID(context.Background(), book.ID)
 
 	// Assert
 	assert.NoError(s.T(), err)
@@ -91,51 +83,293 @@ func (s *BookRepositoryTestSuite) TestGetByID() {
 
 func (s *BookRepositoryTestSuite) TestGetByIDNotFound() {
 	// Act
-	found, err := s.repo.GetByID(999)
+	found, err := s.store.GetByID(context.Background(), 999)
 
 	// Assert
-	assert.Error(s.T(), err)
+	assert.ErrorIs(s.T(), err, gorm.ErrRecordNotFound)
 	assert.Nil(s.T(), found)
 }
 
 func (s *BookRepositoryTestSuite) TestGetAll() {
 	// Arrange
-	books := []model.Book{
-		{Title: "Книга 1", Author: "Автор 1", ISBN: "1111111111"},
-		{Title: "Книга 2", Author: "Автор 2", ISBN: "2222222222"},
-		{Title: "Книга 3", Author: "Автор 3", ISBN: "3333333333"},
-	}
-	for _, book := range books {
-		s.db.Create(&book)
-	}
+	s.createBooks(
+		model.Book{Title: "Книга 1", Author: "Автор 1", ISBN: "1111111111"},
+		model.Book{Title: "Книга 2", Author: "Автор 2", ISBN: "2222222222"},
+		model.Book{Title: "Книга 3", Author: "Автор 3", ISBN: "3333333333"},
+	)
 
 	// Act
-	found, err := s.repo.GetAll(1, 2)
+	found, err := s.store.GetAll(context.Background(), 1, 2)
+	rest, restErr := s.store.GetAll(context.Background(), 2, 2)
 
 	// Assert
 	assert.NoError(s.T(), err)
 	assert.Len(s.T(), found, 2)
+	assert.NoError(s.T(), restErr)
+	assert.Len(s.T(), rest, 1)
 }
 
 func (s *BookRepositoryTestSuite) TestSearch() {
 	// Arrange
-	books := []model.Book{
-		{Title: "Война и мир", Author: "Лев Толстой", ISBN: "1111111111"},
-		{Title: "Анна Каренина", Author: "Лев Толстой", ISBN: "2222222222"},
-		{Title: "Преступление и наказание", Author: "Федор Достоевский", ISBN: "3333333333"},
+	s.createBooks(
+		model.Book{Title: "Война и мир", Author: "Лев Толстой", ISBN: "1111111111"},
+		model.Book{Title: "Анна Каренина", Author: "Лев Толстой", ISBN: "2222222222"},
+		model.Book{Title: "Преступление и наказание", Author: "Федор Достоевский", ISBN: "3333333333"},
+	)
+
+	// Act
+	found, err := s.store.Search(context.Background(), "Толстой")
+
+	// Assert
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), found, 2)
+}
+
+func (s *BookRepositoryTestSuite) TestCountWithFilter() {
+	// Arrange
+	s.createBooks(
+		model.Book{Title: "Dune", Author: "Frank Herbert", ISBN: "1111111111", Year: 1965, Publisher: "Chilton"},
+		model.Book{Title: "Children of Dune", Author: "Frank Herbert", ISBN: "2222222222", Year: 1976, Publisher: "Putnam"},
+		model.Book{Title: "Solaris", Author: "Stanislaw Lem", ISBN: "3333333333", Year: 1961, Publisher: "MON"},
+	)
+
+	testCases := []struct {
+		name     string
+		filter   *model.BookFilter
+		expected int64
+	}{
+		{name: "Без фильтра", filter: nil, expected: 3},
+		{name: "Запрос без учета регистра", filter: &model.BookFilter{Query: "dune"}, expected: 2},
+		{name: "Автор и годы", filter: &model.BookFilter{Author: "herbert", YearFrom: 1970}, expected: 1},
+		{name: "Издательство", filter: &model.BookFilter{Publisher: "mon"}, expected: 1},
 	}
-	for _, book := range books {
-		s.db.Create(&book)
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			// Act
+			count, err := s.store.Count(context.Background(), tc.filter)
+
+			// Assert
+			assert.NoError(s.T(), err)
+			assert.Equal(s.T(), tc.expected, count)
+		})
 	}
+}
+
+func (s *BookRepositoryTestSuite) TestGetByISBNs() {
+	// Arrange
+	s.createBooks(
+		model.Book{Title: "Книга 1", Author: "Автор 1", ISBN: "1111111111"},
+		model.Book{Title: "Книга 2", Author: "Автор 2", ISBN: "2222222222"},
+		model.Book{Title: "Книга 3", Author: "Автор 3", ISBN: "3333333333"},
+	)
 
 	// Act
-	found, err := s.repo.Search("Толстой")
+	found, err := s.store.GetByISBNs(context.Background(), []string{"1111111111", "3333333333", "9999999999"})
 
 	// Assert
 	assert.NoError(s.T(), err)
 	assert.Len(s.T(), found, 2)
 }
 
+func (s *BookRepositoryTestSuite) TestTransactionRollback() {
+	// Act
+	err := s.store.Transaction(context.Background(), func(store BookStore) error {
+		if err := store.Create(context.Background(), &model.Book{Title: "Книга", Author: "Автор", ISBN: "1111111111"}); err != nil {
+			return err
+		}
+		return store.Create(context.Background(), &model.Book{Title: "Дубликат", Author: "Автор", ISBN: "1111111111"})
+	})
+
+	// Assert
+	assert.Error(s.T(), err)
+	count, err := s.store.Count(context.Background(), nil)
+	assert.NoError(s.T(), err)
+	assert.Zero(s.T(), count)
+}
+
+func (s *BookRepositoryTestSuite) TestTransactionCommit() {
+	// Act
+	err := s.store.Transaction(context.Background(), func(store BookStore) error {
+		return store.Create(context.Background(), &model.Book{Title: "Книга", Author: "Автор", ISBN: "1111111111"})
+	})
+
+	// Assert
+	assert.NoError(s.T(), err)
+	found, err := s.store.GetByISBN(context.Background(), "1111111111")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "Книга", found.Title)
+}
+
+func (s *BookRepositoryTestSuite) TestCreateAndUpdateRecordRevisions() {
+	// Arrange
+	book := &model.Book{Title: "Черновик", Author: "Автор", ISBN: "1111111111", Available: true}
+
+	// Act
+	err := s.store.Create(context.Background(), book)
+	assert.NoError(s.T(), err)
+	book.Title = "Финальное название"
+	err = s.store.Update(context.Background(), book)
+	assert.NoError(s.T(), err)
+
+	// Assert
+	found, err := s.revisions.GetByBookID(context.Background(), book.ID)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), found, 2)
+	assert.Equal(s.T(), 1, found[0].Revision)
+	assert.Equal(s.T(), "Черновик", found[0].Title)
+	assert.Equal(s.T(), 2, found[1].Revision)
+	assert.Equal(s.T(), "Финальное название", found[1].Title)
+
+	rev, err := s.revisions.GetByRevision(context.Background(), book.ID, 1)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "Черновик", rev.Title)
+}
+
+func (s *BookRepositoryTestSuite) TestConcurrentUpdatesGetDistinctRevisions() {
+	// Arrange
+	const updates = 8
+	book := s.createBooks(model.Book{Title: "Черновик", Author: "Автор", ISBN: "1111111111"})[0]
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make([]error, updates)
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			update := book
+			update.Title = fmt.Sprintf("Редакция %d", i)
+			errs[i] = s.store.Update(context.Background(), &update)
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert
+	for _, err := range errs {
+		assert.NoError(s.T(), err)
+	}
+	found, err := s.revisions.GetByBookID(context.Background(), book.ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), found, updates+1)
+	for i, rev := range found {
+		assert.Equal(s.T(), i+1, rev.Revision)
+	}
+}
+
+func (s *BookRepositoryTestSuite) TestListByUpdatedAtAndEarliest() {
+	// Arrange
+	earliest, err := s.store.EarliestUpdatedAt(context.Background())
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), earliest.IsZero(), "для пустого каталога возвращается нулевое время")
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.createBooks(
+		model.Book{Title: "Третья", Author: "Автор", ISBN: "3333333333", UpdatedAt: base.Add(2 * time.Hour)},
+		model.Book{Title: "Первая", Author: "Автор", ISBN: "1111111111", UpdatedAt: base},
+		model.Book{Title: "Вторая", Author: "Автор", ISBN: "2222222222", UpdatedAt: base.Add(time.Hour)},
+	)
+
+	// Act
+	first, err := s.store.ListByUpdatedAt(context.Background(), nil, time.Time{}, 0, 2)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), first, 2)
+	last := first[len(first)-1]
+	next, err := s.store.ListByUpdatedAt(context.Background(), nil, last.UpdatedAt, last.ID, 2)
+	require.NoError(s.T(), err)
+	earliest, err = s.store.EarliestUpdatedAt(context.Background())
+
+	// Assert
+	assert.Equal(s.T(), []string{"Первая", "Вторая"}, []string{first[0].Title, first[1].Title})
+	require.Len(s.T(), next, 1)
+	assert.Equal(s.T(), "Третья", next[0].Title)
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), base.Equal(earliest), "ожидалось %s, получено %s", base, earliest)
+}
+
+func (s *BookRepositoryTestSuite) TestFindInBatches() {
+	// Arrange
+	s.createBooks(
+		model.Book{Title: "Книга 1", Author: "Автор", ISBN: "1111111111"},
+		model.Book{Title: "Книга 2", Author: "Автор", ISBN: "2222222222"},
+		model.Book{Title: "Книга 3", Author: "Автор", ISBN: "3333333333"},
+	)
+	var sizes []int
+	var titles []string
+
+	// Act
+	err := s.store.FindInBatches(context.Background(), nil, 2, func(books []model.Book) error {
+		sizes = append(sizes, len(books))
+		for _, book := range books {
+			titles = append(titles, book.Title)
+		}
+		return nil
+	})
+
+	// Assert
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []int{2, 1}, sizes)
+	assert.Equal(s.T(), []string{"Книга 1", "Книга 2", "Книга 3"}, titles)
+}
+
+func (s *BookRepositoryTestSuite) TestCanceledContext() {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	_, err := s.store.GetAll(ctx, 1, 10)
+
+	// Assert
+	assert.ErrorIs(s.T(), err, context.Canceled)
+}
+
 func TestBookRepositoryTestSuite(t *testing.T) {
-	suite.Run(t, new(BookRepositoryTestSuite))
-} 
\ No newline at end of file
+	backends := []struct {
+		name      string
+		newStores func(t *testing.T) (BookStore, RevisionStore)
+	}{
+		{name: "memory", newStores: func(t *testing.T) (BookStore, RevisionStore) {
+			store := NewMemoryBookStore()
+			return store, store
+		}},
+		{name: "sqlite", newStores: newSQLiteStores},
+		{name: "postgres", newStores: newPostgresStores},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			if backend.name == "postgres" && os.Getenv("TEST_DATABASE_DSN") == "" {
+				t.Skip("TEST_DATABASE_DSN не задан")
+			}
+			suite.Run(t, &BookRepositoryTestSuite{newStores: backend.newStores})
+		})
+	}
+}
+
+// newSQLiteStores открывает отдельную базу SQLite в памяти для каждого теста
+func newSQLiteStores(t *testing.T) (BookStore, RevisionStore) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// База в памяти принадлежит одному соединению
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(&model.Book{}, &model.BookRevision{}))
+	return NewBookRepository(db), NewRevisionRepository(db)
+}
+
+// newPostgresStores подключается к базе из TEST_DATABASE_DSN и очищает таблицы
+func newPostgresStores(t *testing.T) (BookStore, RevisionStore) {
+	db, err := gorm.Open(postgres.Open(os.Getenv("TEST_DATABASE_DSN")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(&model.Book{}, &model.BookRevision{}))
+	require.NoError(t, db.Exec("TRUNCATE TABLE books, book_revisions RESTART IDENTITY").Error)
+	return NewBookRepository(db), NewRevisionRepository(db)
+}
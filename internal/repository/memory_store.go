@@ -0,0 +1,446 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"gorm.io/gorm"
+)
+
+// MemoryBookStore хранит книги и их ревизии в памяти процесса.
+// Данные теряются при перезапуске; используется для разработки и тестов без сервера базы данных.
+// Ошибки совпадают с GORM-реализацией: gorm.ErrRecordNotFound и gorm.ErrDuplicatedKey.
+type MemoryBookStore struct {
+	mu   sync.RWMutex
+	data *memoryData
+}
+
+// NewMemoryBookStore создает новый экземпляр MemoryBookStore
+func NewMemoryBookStore() *MemoryBookStore {
+	return &MemoryBookStore{data: newMemoryData()}
+}
+
+// Create создает новую книгу и сохраняет ее первую ревизию
+func (s *MemoryBookStore) Create(ctx context.Context, book *model.Book) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Create(ctx, book)
+}
+
+// GetByID получает книгу по ID
+func (s *MemoryBookStore) GetByID(ctx context.Context, id uint) (*model.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.GetByID(ctx, id)
+}
+
+// GetAll получает все книги с пагинацией
+func (s *MemoryBookStore) GetAll(ctx context.Context, page, pageSize int) ([]model.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.GetAll(ctx, page, pageSize)
+}
+
+// Update обновляет информацию о книге и сохраняет новую ревизию
+func (s *MemoryBookStore) Update(ctx context.Context, book *model.Book) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Update(ctx, book)
+}
+
+// Delete удаляет книгу по ID
+func (s *MemoryBookStore) Delete(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Delete(ctx, id)
+}
+
+// GetByISBN получает книгу по ISBN
+func (s *MemoryBookStore) GetByISBN(ctx context.Context, isbn string) (*model.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.GetByISBN(ctx, isbn)
+}
+
+// GetByISBNs получает книги с указанными ISBN
+func (s *MemoryBookStore) GetByISBNs(ctx context.Context, isbns []string) ([]model.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.GetByISBNs(ctx, isbns)
+}
+
+// GetByIDs получает книги с указанными ID
+func (s *MemoryBookStore) GetByIDs(ctx context.Context, ids []uint) ([]model.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.GetByIDs(ctx, ids)
+}
+
+// Transaction выполняет fn над копией данных и сохраняет изменения, только если fn
+// завершилась без ошибки. На время транзакции остальные операции ожидают.
+func (s *MemoryBookStore) Transaction(ctx context.Context, fn func(store BookStore) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Transaction(ctx, fn)
+}
+
+// FindInBatches последовательно передает в fn книги, удовлетворяющие фильтру,
+// порциями по batchSize в порядке возрастания ID
+func (s *MemoryBookStore) FindInBatches(ctx context.Context, filter *model.BookFilter, batchSize int, fn func(books []model.Book) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.FindInBatches(ctx, filter, batchSize, fn)
+}
+
+// ListByUpdatedAt получает до limit книг, удовлетворяющих фильтру, в порядке изменения
+func (s *MemoryBookStore) ListByUpdatedAt(ctx context.Context, filter *model.BookFilter, afterUpdatedAt time.Time, afterID uint, limit int) ([]model.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.ListByUpdatedAt(ctx, filter, afterUpdatedAt, afterID, limit)
+}
+
+// Count возвращает количество книг, удовлетворяющих фильтру
+func (s *MemoryBookStore) Count(ctx context.Context, filter *model.BookFilter) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.Count(ctx, filter)
+}
+
+// EarliestUpdatedAt возвращает самую раннюю дату изменения книги
+func (s *MemoryBookStore) EarliestUpdatedAt(ctx context.Context) (time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.EarliestUpdatedAt(ctx)
+}
+
+// Search ищет книги по названию или автору без учета регистра
+func (s *MemoryBookStore) Search(ctx context.Context, query string) ([]model.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.Search(ctx, query)
+}
+
+// GetByBookID получает все ревизии книги в порядке возрастания номера
+func (s *MemoryBookStore) GetByBookID(ctx context.Context, bookID uint) ([]model.BookRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Пустая история, как и в GORM, - пустой список, а не nil
+	return append([]model.BookRevision{}, s.data.revisions[bookID]...), nil
+}
+
+// GetByRevision получает ревизию книги по ее номеру
+func (s *MemoryBookStore) GetByRevision(ctx context.Context, bookID uint, revision int) (*model.BookRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, rev := range s.data.revisions[bookID] {
+		if rev.Revision == revision {
+			return &rev, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// memoryData содержит данные MemoryBookStore и реализует BookStore без блокировок:
+// синхронизацию обеспечивает MemoryBookStore, а транзакции работают с копией.
+type memoryData struct {
+	books          map[uint]model.Book
+	revisions      map[uint][]model.BookRevision
+	lastBookID     uint
+	lastRevisionID uint
+}
+
+func newMemoryData() *memoryData {
+	return &memoryData{books: make(map[uint]model.Book), revisions: make(map[uint][]model.BookRevision)}
+}
+
+func (d *memoryData) clone() *memoryData {
+	c := &memoryData{
+		books:          make(map[uint]model.Book, len(d.books)),
+		revisions:      make(map[uint][]model.BookRevision, len(d.revisions)),
+		lastBookID:     d.lastBookID,
+		lastRevisionID: d.lastRevisionID,
+	}
+	for id, book := range d.books {
+		c.books[id] = book
+	}
+	for id, revs := range d.revisions {
+		c.revisions[id] = append([]model.BookRevision(nil), revs...)
+	}
+	return c
+}
+
+func (d *memoryData) Create(ctx context.Context, book *model.Book) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d.isbnTaken(book.ISBN, 0) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	now := time.Now()
+	if book.CreatedAt.IsZero() {
+		book.CreatedAt = now
+	}
+	if book.UpdatedAt.IsZero() {
+		book.UpdatedAt = now
+	}
+	d.lastBookID++
+	book.ID = d.lastBookID
+	d.books[book.ID] = *book
+	d.addRevision(book)
+	return nil
+}
+
+func (d *memoryData) GetByID(ctx context.Context, id uint) (*model.Book, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	book, ok := d.books[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &book, nil
+}
+
+func (d *memoryData) GetAll(ctx context.Context, page, pageSize int) ([]model.Book, error) {
+	books, err := d.find(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	offset := (page - 1) * pageSize
+	if offset >= len(books) {
+		return []model.Book{}, nil
+	}
+	end := offset + pageSize
+	if end > len(books) {
+		end = len(books)
+	}
+	return books[offset:end], nil
+}
+
+func (d *memoryData) Update(ctx context.Context, book *model.Book) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d.isbnTaken(book.ISBN, book.ID) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	book.UpdatedAt = time.Now()
+	d.books[book.ID] = *book
+	d.addRevision(book)
+	return nil
+}
+
+func (d *memoryData) Delete(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delete(d.books, id)
+	return nil
+}
+
+func (d *memoryData) GetByISBN(ctx context.Context, isbn string) (*model.Book, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, book := range d.books {
+		if book.ISBN == isbn {
+			return &book, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (d *memoryData) GetByISBNs(ctx context.Context, isbns []string) ([]model.Book, error) {
+	wanted := make(map[string]bool, len(isbns))
+	for _, isbn := range isbns {
+		wanted[isbn] = true
+	}
+	books, err := d.find(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := []model.Book{}
+	for _, book := range books {
+		if wanted[book.ISBN] {
+			result = append(result, book)
+		}
+	}
+	return result, nil
+}
+
+func (d *memoryData) GetByIDs(ctx context.Context, ids []uint) ([]model.Book, error) {
+	if len(ids) == 0 {
+		return []model.Book{}, ctx.Err()
+	}
+	return d.find(ctx, &model.BookFilter{IDs: ids})
+}
+
+func (d *memoryData) Transaction(ctx context.Context, fn func(store BookStore) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	tx := d.clone()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	*d = *tx
+	return nil
+}
+
+func (d *memoryData) FindInBatches(ctx context.Context, filter *model.BookFilter, batchSize int, fn func(books []model.Book) error) error {
+	books, err := d.find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(books); start += batchSize {
+		end := start + batchSize
+		if end > len(books) {
+			end = len(books)
+		}
+		if err := fn(books[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *memoryData) ListByUpdatedAt(ctx context.Context, filter *model.BookFilter, afterUpdatedAt time.Time, afterID uint, limit int) ([]model.Book, error) {
+	books, err := d.find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(books, func(i, j int) bool {
+		if !books[i].UpdatedAt.Equal(books[j].UpdatedAt) {
+			return books[i].UpdatedAt.Before(books[j].UpdatedAt)
+		}
+		return books[i].ID < books[j].ID
+	})
+
+	result := []model.Book{}
+	for _, book := range books {
+		if len(result) == limit {
+			break
+		}
+		if afterID != 0 && !(book.UpdatedAt.After(afterUpdatedAt) || book.UpdatedAt.Equal(afterUpdatedAt) && book.ID > afterID) {
+			continue
+		}
+		result = append(result, book)
+	}
+	return result, nil
+}
+
+func (d *memoryData) Count(ctx context.Context, filter *model.BookFilter) (int64, error) {
+	books, err := d.find(ctx, filter)
+	return int64(len(books)), err
+}
+
+func (d *memoryData) EarliestUpdatedAt(ctx context.Context) (time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+	var earliest time.Time
+	for _, book := range d.books {
+		if earliest.IsZero() || book.UpdatedAt.Before(earliest) {
+			earliest = book.UpdatedAt
+		}
+	}
+	return earliest, nil
+}
+
+func (d *memoryData) Search(ctx context.Context, query string) ([]model.Book, error) {
+	return d.find(ctx, &model.BookFilter{Query: query})
+}
+
+// find возвращает книги, удовлетворяющие фильтру, в порядке возрастания ID
+func (d *memoryData) find(ctx context.Context, filter *model.BookFilter) ([]model.Book, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	books := []model.Book{}
+	for _, book := range d.books {
+		if matchesFilter(&book, filter) {
+			books = append(books, book)
+		}
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	return books, nil
+}
+
+func (d *memoryData) isbnTaken(isbn string, exceptID uint) bool {
+	for id, book := range d.books {
+		if book.ISBN == isbn && id != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// addRevision сохраняет снимок книги со следующим по порядку номером ревизии
+func (d *memoryData) addRevision(book *model.Book) {
+	rev := model.NewBookRevision(book)
+	d.lastRevisionID++
+	rev.ID = d.lastRevisionID
+	rev.Revision = len(d.revisions[book.ID]) + 1
+	rev.CreatedAt = time.Now()
+	d.revisions[book.ID] = append(d.revisions[book.ID], *rev)
+}
+
+// matchesFilter повторяет условия BookRepository.applyFilter
+func matchesFilter(book *model.Book, filter *model.BookFilter) bool {
+	if filter == nil {
+		return true
+	}
+	if len(filter.IDs) > 0 && !containsID(filter.IDs, book.ID) {
+		return false
+	}
+	if filter.Query != "" && !containsFold(book.Title, filter.Query) && !containsFold(book.Author, filter.Query) {
+		return false
+	}
+	if filter.Author != "" && !containsFold(book.Author, filter.Author) {
+		return false
+	}
+	if filter.Publisher != "" && !containsFold(book.Publisher, filter.Publisher) {
+		return false
+	}
+	if filter.YearFrom != 0 && book.Year < filter.YearFrom {
+		return false
+	}
+	if filter.YearTo != 0 && book.Year > filter.YearTo {
+		return false
+	}
+	if filter.Available != nil && book.Available != *filter.Available {
+		return false
+	}
+	if filter.UpdatedFrom != nil && book.UpdatedAt.Before(*filter.UpdatedFrom) {
+		return false
+	}
+	if filter.UpdatedUntil != nil && !book.UpdatedAt.Before(*filter.UpdatedUntil) {
+		return false
+	}
+	return true
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
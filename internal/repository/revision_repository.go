@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevisionRepository представляет репозиторий для работы с ревизиями книг
+type RevisionRepository struct {
+	db *gorm.DB
+}
+
+// NewRevisionRepository создает новый экземпляр RevisionRepository
+func NewRevisionRepository(db *gorm.DB) *RevisionRepository {
+	return &RevisionRepository{db: db}
+}
+
+// GetByBookID получает все ревизии книги в порядке возрастания номера
+func (r *RevisionRepository) GetByBookID(ctx context.Context, bookID uint) ([]model.BookRevision, error) {
+	var revisions []model.BookRevision
+	err := r.db.WithContext(ctx).Where("book_id = ?", bookID).Order("revision").Find(&revisions).Error
+	return revisions, err
+}
+
+// GetByRevision получает ревизию книги по ее номеру
+func (r *RevisionRepository) GetByRevision(ctx context.Context, bookID uint, revision int) (*model.BookRevision, error) {
+	var rev model.BookRevision
+	err := r.db.WithContext(ctx).Where("book_id = ? AND revision = ?", bookID, revision).First(&rev).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// createRevision сохраняет снимок книги со следующим по порядку номером ревизии.
+// Должна вызываться в той же транзакции, что и изменение книги. Строка книги
+// блокируется до конца транзакции, поэтому параллельные изменения получают
+// номера по очереди, а не один и тот же номер. SQLite блокирует всю базу
+// на запись и пропускает FOR UPDATE.
+func createRevision(tx *gorm.DB, book *model.Book) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.Book{}, book.ID).Error
+	if err != nil {
+		return err
+	}
+
+	var last int
+	err = tx.Model(&model.BookRevision{}).
+		Where("book_id = ?", book.ID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&last).Error
+	if err != nil {
+		return err
+	}
+
+	rev := model.NewBookRevision(book)
+	rev.Revision = last + 1
+	return tx.Create(rev).Error
+}
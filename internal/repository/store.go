@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// BookStore описывает хранилище книг. Реализации: BookRepository поверх GORM
+// (PostgreSQL или SQLite) и MemoryBookStore в памяти процесса.
+// Если книга не найдена, методы возвращают gorm.ErrRecordNotFound.
+type BookStore interface {
+	// Create создает новую книгу и сохраняет ее первую ревизию
+	Create(ctx context.Context, book *model.Book) error
+	GetByID(ctx context.Context, id uint) (*model.Book, error)
+	GetAll(ctx context.Context, page, pageSize int) ([]model.Book, error)
+	// Update обновляет книгу и сохраняет новую ревизию
+	Update(ctx context.Context, book *model.Book) error
+	Delete(ctx context.Context, id uint) error
+	GetByISBN(ctx context.Context, isbn string) (*model.Book, error)
+	GetByISBNs(ctx context.Context, isbns []string) ([]model.Book, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]model.Book, error)
+	// Transaction выполняет fn в транзакции; если fn возвращает ошибку, изменения отменяются
+	Transaction(ctx context.Context, fn func(store BookStore) error) error
+	FindInBatches(ctx context.Context, filter *model.BookFilter, batchSize int, fn func(books []model.Book) error) error
+	ListByUpdatedAt(ctx context.Context, filter *model.BookFilter, afterUpdatedAt time.Time, afterID uint, limit int) ([]model.Book, error)
+	Count(ctx context.Context, filter *model.BookFilter) (int64, error)
+	EarliestUpdatedAt(ctx context.Context) (time.Time, error)
+	Search(ctx context.Context, query string) ([]model.Book, error)
+}
+
+// RevisionStore описывает хранилище ревизий книг. Ревизии создаются
+// хранилищем книг, поэтому реализации используются парами.
+type RevisionStore interface {
+	GetByBookID(ctx context.Context, bookID uint) ([]model.BookRevision, error)
+	GetByRevision(ctx context.Context, bookID uint, revision int) (*model.BookRevision, error)
+}
+
+var (
+	_ BookStore     = (*BookRepository)(nil)
+	_ RevisionStore = (*RevisionRepository)(nil)
+	_ BookStore     = (*MemoryBookStore)(nil)
+	_ RevisionStore = (*MemoryBookStore)(nil)
+)
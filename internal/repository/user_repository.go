@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"gorm.io/gorm"
+)
+
+// UserRepository представляет репозиторий для работы с пользователями и их токенами
+type UserRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository создает новый экземпляр UserRepository
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Create создает нового пользователя
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+// GetByID получает пользователя по ID
+func (r *UserRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
+	var user model.User
+	err := r.db.WithContext(ctx).First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetByExternalID получает пользователя по идентификатору внешнего провайдера
+func (r *UserRepository) GetByExternalID(ctx context.Context, externalID string) (*model.User, error) {
+	var user model.User
+	err := r.db.WithContext(ctx).Where("external_id = ?", externalID).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetAll получает всех пользователей
+func (r *UserRepository) GetAll(ctx context.Context) ([]model.User, error) {
+	var users []model.User
+	err := r.db.WithContext(ctx).Order("id").Find(&users).Error
+	return users, err
+}
+
+// UpdateRole изменяет роль пользователя
+func (r *UserRepository) UpdateRole(ctx context.Context, id uint, role string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
+// Delete удаляет пользователя вместе с его refresh-токенами
+func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&model.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.User{}, id).Error
+	})
+}
+
+// GetByUsername получает пользователя по имени
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	var user model.User
+	err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateRefreshToken сохраняет выданный refresh-токен
+func (r *UserRepository) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// GetRefreshToken получает refresh-токен по идентификатору
+func (r *UserRepository) GetRefreshToken(ctx context.Context, id string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeRefreshToken отзывает refresh-токен. Возвращает false, если токен уже был отозван.
+func (r *UserRepository) RevokeRefreshToken(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeUserRefreshTokens отзывает все действующие refresh-токены пользователя
+func (r *UserRepository) RevokeUserRefreshTokens(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,164 @@
+// Package seed заполняет базу данных книгами из файлов и встроенных наборов.
+// Книги создаются через BookService, поэтому к ним применяются те же проверки,
+// что и к запросам API, а уже существующие ISBN пропускаются.
+package seed
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/service"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DatasetDemo задает несколько известных книг для локальной разработки
+	DatasetDemo = "demo"
+	// DatasetLoadTest задает сгенерированные книги для нагрузочного тестирования
+	DatasetLoadTest = "load-test"
+
+	// DefaultLoadTestSize задает число книг набора load-test по умолчанию
+	DefaultLoadTestSize = 1000
+	// maxLoadTestSize ограничен числом уникальных сгенерированных ISBN
+	maxLoadTestSize = 1_000_000
+)
+
+//go:embed datasets/*.yaml
+var datasets embed.FS
+
+// Fixture представляет файл с данными для заполнения базы
+type Fixture struct {
+	Books []model.BookCreate `json:"books" yaml:"books"`
+}
+
+// Report представляет итог заполнения
+type Report struct {
+	Created int
+	// Skipped содержит число книг, ISBN которых уже есть в базе
+	Skipped int
+}
+
+// BookCreator создает книгу с проверкой данных; реализуется service.BookService
+type BookCreator interface {
+	CreateBook(ctx context.Context, bookCreate *model.BookCreate) (*model.Book, error)
+}
+
+// Seed создает книги из fixture. Книги с уже существующим ISBN пропускаются,
+// поэтому повторный запуск ничего не меняет. Первая ошибка прерывает заполнение.
+func Seed(ctx context.Context, books BookCreator, fixture *Fixture) (Report, error) {
+	var report Report
+	for i := range fixture.Books {
+		book := &fixture.Books[i]
+		_, err := books.CreateBook(ctx, book)
+		switch {
+		case err == nil:
+			report.Created++
+		case errors.Is(err, service.ErrDuplicateISBN):
+			report.Skipped++
+		default:
+			return report, fmt.Errorf("книга %d (ISBN %q): %w", i+1, book.ISBN, err)
+		}
+	}
+	return report, nil
+}
+
+// Dataset возвращает встроенный набор данных по имени. Параметр size
+// задает число книг набора load-test и не используется для demo.
+func Dataset(name string, size int) (*Fixture, error) {
+	switch name {
+	case DatasetDemo:
+		data, err := datasets.ReadFile("datasets/demo.yaml")
+		if err != nil {
+			return nil, err
+		}
+		return Parse(data, ".yaml")
+	case DatasetLoadTest:
+		return Generate(size)
+	default:
+		return nil, fmt.Errorf("неизвестный набор данных %q: доступны %s и %s", name, DatasetDemo, DatasetLoadTest)
+	}
+}
+
+// LoadFile читает fixture из файла JSON или YAML; формат определяется по расширению
+func LoadFile(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла данных: %w", err)
+	}
+	fixture, err := Parse(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора %s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// Parse разбирает fixture в формате, заданном расширением файла.
+// Неизвестные поля считаются ошибкой, чтобы опечатки не проходили незамеченными.
+func Parse(data []byte, ext string) (*Fixture, error) {
+	var fixture Fixture
+	switch strings.ToLower(ext) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&fixture); err != nil {
+			return nil, err
+		}
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&fixture); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат файла данных %q: ожидается .json, .yaml или .yml", ext)
+	}
+	return &fixture, nil
+}
+
+var (
+	loadTestAuthors    = []string{"Анна Иванова", "Борис Петров", "Вера Смирнова", "Глеб Кузнецов", "Дарья Соколова"}
+	loadTestPublishers = []string{"АСТ", "Эксмо", "Азбука", "Питер"}
+)
+
+// Generate создает size книг с детерминированными данными. ISBN вычисляются
+// по номеру книги, поэтому повторная генерация дает те же книги.
+func Generate(size int) (*Fixture, error) {
+	if size < 1 || size > maxLoadTestSize {
+		return nil, fmt.Errorf("число книг должно быть от 1 до %d, получено %d", maxLoadTestSize, size)
+	}
+
+	fixture := &Fixture{Books: make([]model.BookCreate, 0, size)}
+	for i := 1; i <= size; i++ {
+		fixture.Books = append(fixture.Books, model.BookCreate{
+			Title:       fmt.Sprintf("Книга для нагрузочного тестирования %d", i),
+			Author:      loadTestAuthors[i%len(loadTestAuthors)],
+			ISBN:        isbn13(fmt.Sprintf("97900%07d", i)),
+			Description: "Сгенерированная книга",
+			Year:        1900 + i%125,
+			Publisher:   loadTestPublishers[i%len(loadTestPublishers)],
+		})
+	}
+	return fixture, nil
+}
+
+// isbn13 дополняет 12 цифр контрольной цифрой ISBN-13
+func isbn13(digits string) string {
+	sum := 0
+	for i, d := range digits {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(d-'0') * weight
+	}
+	return fmt.Sprintf("%s%d", digits, (10-sum%10)%10)
+}
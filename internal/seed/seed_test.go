@@ -0,0 +1,171 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBooks запоминает созданные ISBN и возвращает ErrDuplicateISBN для повторов
+type fakeBooks struct {
+	isbns map[string]bool
+	err   error
+}
+
+func (f *fakeBooks) CreateBook(ctx context.Context, bookCreate *model.BookCreate) (*model.Book, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.isbns[bookCreate.ISBN] {
+		return nil, service.ErrDuplicateISBN
+	}
+	f.isbns[bookCreate.ISBN] = true
+	return &model.Book{ISBN: bookCreate.ISBN}, nil
+}
+
+func TestParse(t *testing.T) {
+	expected := &Fixture{Books: []model.BookCreate{{Title: "Книга", Author: "Автор", ISBN: "9780306406157", Year: 2020}}}
+
+	testCases := []struct {
+		name          string
+		data          string
+		ext           string
+		expected      *Fixture
+		expectedError bool
+	}{
+		{
+			name:     "JSON",
+			data:     `{"books": [{"title": "Книга", "author": "Автор", "isbn": "9780306406157", "year": 2020}]}`,
+			ext:      ".json",
+			expected: expected,
+		},
+		{
+			name:     "YAML",
+			data:     "books:\n  - title: Книга\n    author: Автор\n    isbn: \"9780306406157\"\n    year: 2020\n",
+			ext:      ".YML",
+			expected: expected,
+		},
+		{name: "Пустой YAML", data: "", ext: ".yaml", expected: &Fixture{}},
+		{name: "Неизвестное поле JSON", data: `{"books": [{"titel": "Книга"}]}`, ext: ".json", expectedError: true},
+		{name: "Неизвестное поле YAML", data: "books:\n  - titel: Книга\n", ext: ".yaml", expectedError: true},
+		{name: "Неподдерживаемый формат", data: "title,author", ext: ".csv", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			fixture, err := Parse([]byte(tc.data), tc.ext)
+
+			// Assert
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, fixture)
+		})
+	}
+}
+
+func TestDataset(t *testing.T) {
+	t.Run("Набор demo", func(t *testing.T) {
+		// Act
+		fixture, err := Dataset(DatasetDemo, 0)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, fixture.Books, 3)
+		for _, book := range fixture.Books {
+			assert.NotEmpty(t, book.Title)
+			assert.Len(t, book.ISBN, 13)
+			assert.NotZero(t, book.Year)
+		}
+	})
+
+	t.Run("Набор load-test", func(t *testing.T) {
+		// Act
+		fixture, err := Dataset(DatasetLoadTest, 25)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, fixture.Books, 25)
+	})
+
+	t.Run("Неизвестный набор", func(t *testing.T) {
+		// Act
+		_, err := Dataset("unknown", 0)
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	// Act
+	first, err := Generate(500)
+	require.NoError(t, err)
+	second, err := Generate(500)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, first, second, "генерация должна быть детерминированной")
+	seen := make(map[string]bool)
+	for _, book := range first.Books {
+		assert.Len(t, book.ISBN, 13)
+		assert.Equal(t, isbn13(book.ISBN[:12]), book.ISBN)
+		assert.False(t, seen[book.ISBN], "ISBN %s повторяется", book.ISBN)
+		seen[book.ISBN] = true
+	}
+
+	_, err = Generate(0)
+	assert.Error(t, err)
+	_, err = Generate(maxLoadTestSize + 1)
+	assert.Error(t, err)
+}
+
+func TestISBN13(t *testing.T) {
+	// Act & Assert
+	assert.Equal(t, "9780306406157", isbn13("978030640615"))
+	assert.Equal(t, "9785171147440", isbn13("978517114744"))
+}
+
+func TestSeed(t *testing.T) {
+	fixture := &Fixture{Books: []model.BookCreate{
+		{Title: "Первая", Author: "Автор", ISBN: "9780306406157", Year: 2020},
+		{Title: "Вторая", Author: "Автор", ISBN: "9785171147440", Year: 2021},
+	}}
+
+	t.Run("Повторный запуск пропускает существующие книги", func(t *testing.T) {
+		// Arrange
+		books := &fakeBooks{isbns: map[string]bool{"9785171147440": true}}
+
+		// Act
+		first, err := Seed(context.Background(), books, fixture)
+		require.NoError(t, err)
+		second, err := Seed(context.Background(), books, fixture)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, Report{Created: 1, Skipped: 1}, first)
+		assert.Equal(t, Report{Skipped: 2}, second)
+	})
+
+	t.Run("Ошибка прерывает заполнение", func(t *testing.T) {
+		// Arrange
+		errInvalid := errors.New("не указан год издания книги")
+		books := &fakeBooks{isbns: map[string]bool{}, err: errInvalid}
+
+		// Act
+		report, err := Seed(context.Background(), books, fixture)
+
+		// Assert
+		assert.ErrorIs(t, err, errInvalid)
+		assert.Contains(t, err.Error(), "книга 1")
+		assert.Equal(t, Report{}, report)
+	})
+}
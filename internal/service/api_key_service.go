@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Формат API-ключа: blk_<префикс>_<секрет>. Префикс хранится открыто и служит
+// для поиска ключа, секрет проверяется по SHA-256 хешу. Медленный хеш не нужен:
+// в секрете 256 бит случайных данных, подбирать его по хешу бессмысленно.
+const (
+	apiKeyScheme       = "blk"
+	apiKeyPrefixBytes  = 6
+	apiKeySecretBytes  = 32
+	apiKeyTouchTimeout = time.Minute
+)
+
+var (
+	// ErrInvalidAPIKey возвращается при неверном, отозванном или истекшем API-ключе
+	ErrInvalidAPIKey = errors.New("недействительный API-ключ")
+	// ErrAPIKeyNotFound возвращается, если API-ключ не найден
+	ErrAPIKeyNotFound = errors.New("API-ключ не найден")
+	// ErrInvalidScope возвращается при неизвестной области API-ключа
+	ErrInvalidScope = errors.New("неизвестная область API-ключа")
+	// ErrInvalidExpiry возвращается, если срок действия ключа уже истек
+	ErrInvalidExpiry = errors.New("срок действия API-ключа уже истек")
+)
+
+// APIKeyService представляет сервис выпуска и проверки API-ключей
+type APIKeyService struct {
+	repo *repository.APIKeyRepository
+	now  func() time.Time
+}
+
+// NewAPIKeyService создает новый экземпляр APIKeyService
+func NewAPIKeyService(repo *repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo, now: time.Now}
+}
+
+// CreateKey выпускает API-ключ. Ключ может получить только те права, которые есть у создателя.
+func (s *APIKeyService) CreateKey(ctx context.Context, keyCreate *model.APIKeyCreate) (*model.APIKeyCreated, error) {
+	if err := authorize(ctx, model.PermissionManageAPIKeys); err != nil {
+		return nil, err
+	}
+
+	actor := model.PrincipalFromContext(ctx)
+	for _, scope := range keyCreate.Scopes {
+		if !model.ValidPermission(scope) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+		if !actor.Can(scope) {
+			return nil, ErrForbidden
+		}
+	}
+	if keyCreate.ExpiresAt != nil && !keyCreate.ExpiresAt.After(s.now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	value, prefix, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &model.APIKey{
+		Name:      strings.TrimSpace(keyCreate.Name),
+		Prefix:    prefix,
+		KeyHash:   hashAPIKey(value),
+		Scopes:    keyCreate.Scopes,
+		CreatedBy: actor.UserID,
+		ExpiresAt: keyCreate.ExpiresAt,
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &model.APIKeyCreated{APIKey: *key, Key: value}, nil
+}
+
+// ListKeys получает все API-ключи без их значений
+func (s *APIKeyService) ListKeys(ctx context.Context) ([]model.APIKey, error) {
+	if err := authorize(ctx, model.PermissionManageAPIKeys); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAll(ctx)
+}
+
+// RevokeKey отзывает API-ключ. Повторный отзыв не является ошибкой.
+func (s *APIKeyService) RevokeKey(ctx context.Context, id uint) error {
+	if err := authorize(ctx, model.PermissionManageAPIKeys); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+	return s.repo.Revoke(ctx, id, s.now())
+}
+
+// VerifyAPIKey проверяет API-ключ и возвращает его как пользователя запроса
+func (s *APIKeyService) VerifyAPIKey(ctx context.Context, value string) (*model.Principal, error) {
+	prefix, ok := parseAPIKey(value)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := s.now()
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashAPIKey(value))) != 1 || !key.Active(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// Время использования обновляется не чаще раза в минуту, чтобы не писать в базу на каждый запрос
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchTimeout {
+		if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.Principal{
+		Username: "apikey:" + key.Name,
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+// generateAPIKey возвращает новый ключ и его префикс
+func generateAPIKey() (string, string, error) {
+	prefix := make([]byte, apiKeyPrefixBytes)
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(prefix); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefixHex := hex.EncodeToString(prefix)
+	return apiKeyScheme + "_" + prefixHex + "_" + base64.RawURLEncoding.EncodeToString(secret), prefixHex, nil
+}
+
+// parseAPIKey проверяет формат ключа и извлекает префикс
+func parseAPIKey(value string) (string, bool) {
+	parts := strings.SplitN(value, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyScheme || len(parts[1]) != apiKeyPrefixBytes*2 || parts[2] == "" {
+		return "", false
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil {
+		return "", false
+	}
+	return parts[1], true
+}
+
+func hashAPIKey(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
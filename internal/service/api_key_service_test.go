@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	// Act
+	value, prefix, err := generateAPIKey()
+	other, _, _ := generateAPIKey()
+
+	// Assert
+	require.NoError(t, err)
+	parsed, ok := parseAPIKey(value)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsed)
+	assert.NotEqual(t, value, other)
+	assert.NotEqual(t, value, hashAPIKey(value))
+}
+
+func TestParseAPIKey(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected bool
+	}{
+		{name: "Секрет содержит подчеркивание", value: "blk_0123456789ab_abc_def", expected: true},
+		{name: "Другая схема", value: "key_0123456789ab_secret", expected: false},
+		{name: "Короткий префикс", value: "blk_0123_secret", expected: false},
+		{name: "Префикс не в hex", value: "blk_zzzzzzzzzzzz_secret", expected: false},
+		{name: "Нет секрета", value: "blk_0123456789ab_", expected: false},
+		{name: "Не ключ", value: "eyJhbGciOiJIUzI1NiIs", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, ok := parseAPIKey(tc.value)
+
+			// Assert
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestCreateKeyValidation(t *testing.T) {
+	admin := &model.Principal{UserID: 1, Role: model.RoleAdmin}
+	past := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		name          string
+		actor         *model.Principal
+		keyCreate     model.APIKeyCreate
+		expectedError error
+	}{
+		{
+			name:          "Библиотекарь не выпускает ключи",
+			actor:         &model.Principal{UserID: 2, Role: model.RoleLibrarian},
+			keyCreate:     model.APIKeyCreate{Name: "kiosk", Scopes: []model.Permission{model.PermissionReadBooks}},
+			expectedError: ErrForbidden,
+		},
+		{
+			name:          "Неизвестная область",
+			actor:         admin,
+			keyCreate:     model.APIKeyCreate{Name: "kiosk", Scopes: []model.Permission{"books:burn"}},
+			expectedError: ErrInvalidScope,
+		},
+		{
+			name:          "Срок действия в прошлом",
+			actor:         admin,
+			keyCreate:     model.APIKeyCreate{Name: "kiosk", Scopes: []model.Permission{model.PermissionReadBooks}, ExpiresAt: &past},
+			expectedError: ErrInvalidExpiry,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			s := NewAPIKeyService(nil)
+
+			// Act
+			key, err := s.CreateKey(model.ContextWithPrincipal(context.Background(), tc.actor), &tc.keyCreate)
+
+			// Assert
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Nil(t, key)
+		})
+	}
+}
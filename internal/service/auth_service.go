@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidCredentials возвращается при неверном имени пользователя или пароле
+	ErrInvalidCredentials = errors.New("неверное имя пользователя или пароль")
+	// ErrInvalidToken возвращается при недействительном, просроченном или отозванном токене
+	ErrInvalidToken = errors.New("недействительный токен")
+	// ErrWeakPassword возвращается, если пароль короче MinPasswordLength
+	ErrWeakPassword = errors.New("пароль слишком короткий")
+)
+
+// MinPasswordLength задает минимальную длину пароля
+const MinPasswordLength = 8
+
+// Типы токенов, записываемые в claim typ
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+// tokenClaims представляет содержимое JWT. В sub хранится ID пользователя,
+// в jti - идентификатор refresh-токена. Роль в токене справочная: права запроса
+// берутся из учетной записи при проверке access-токена.
+type tokenClaims struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Type     string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// AuthService представляет сервис аутентификации пользователей
+type AuthService struct {
+	users *repository.UserRepository
+	cfg   config.AuthConfig
+	now   func() time.Time
+}
+
+// NewAuthService создает новый экземпляр AuthService
+func NewAuthService(users *repository.UserRepository, cfg config.AuthConfig) *AuthService {
+	return &AuthService{users: users, cfg: cfg, now: time.Now}
+}
+
+// EnsureUser создает пользователя с указанной ролью, если пользователя с таким именем еще нет.
+// Используется для создания администратора при первом запуске.
+func (s *AuthService) EnsureUser(ctx context.Context, username, password, role string) error {
+	username = strings.TrimSpace(username)
+	if _, err := s.users.GetByUsername(ctx, username); err == nil {
+		return nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.users.Create(ctx, &model.User{Username: username, PasswordHash: hash, Role: role})
+}
+
+// Login проверяет имя пользователя и пароль и выдает пару токенов
+func (s *AuthService) Login(ctx context.Context, username, password string) (*model.TokenPair, error) {
+	user, err := s.users.GetByUsername(ctx, strings.TrimSpace(username))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Сравниваем с фиктивным хешем, чтобы время ответа не выдавало существование пользователя
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+			slog.WarnContext(ctx, "неудачный вход", "username", username, "reason", "пользователь не найден")
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		slog.WarnContext(ctx, "неудачный вход", "username", user.Username, "reason", "неверный пароль")
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.issueTokenPair(ctx, user)
+}
+
+// LoginExternal выдает пару токенов пользователю, подтвержденному внешним провайдером.
+// При первом входе пользователь создается без пароля; роль обновляется при каждом входе,
+// так как источником ролей остается провайдер. Локальная учетная запись с тем же
+// именем не связывается автоматически, чтобы провайдер не мог получить чужие права.
+func (s *AuthService) LoginExternal(ctx context.Context, identity *model.ExternalIdentity) (*model.TokenPair, error) {
+	externalID := identity.Issuer + "|" + identity.Subject
+
+	user, err := s.users.GetByExternalID(ctx, externalID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if _, err := s.users.GetByUsername(ctx, identity.Username); err == nil {
+			return nil, ErrDuplicateUsername
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		user = &model.User{Username: identity.Username, Role: identity.Role, ExternalID: &externalID}
+		if err := s.users.Create(ctx, user); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case user.Role != identity.Role:
+		if err := s.users.UpdateRole(ctx, user.ID, identity.Role); err != nil {
+			return nil, err
+		}
+		user.Role = identity.Role
+	}
+
+	return s.issueTokenPair(ctx, user)
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов. Использованный токен отзывается;
+// повторное предъявление отозванного токена отзывает все refresh-токены пользователя.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*model.TokenPair, error) {
+	claims, err := s.parseToken(refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := claimsUserID(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.users.RevokeRefreshToken(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		// Подпись верна, но токен уже использован: вероятно, он похищен
+		if err := s.users.RevokeUserRefreshTokens(ctx, userID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return s.issueTokenPair(ctx, user)
+}
+
+// Logout отзывает refresh-токен. Access-токен остается действительным до истечения срока.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	claims, err := s.parseToken(refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return err
+	}
+	_, err = s.users.RevokeRefreshToken(ctx, claims.ID)
+	return err
+}
+
+// VerifyAccessToken проверяет access-токен и возвращает его владельца. Роль
+// читается из учетной записи при каждом запросе, поэтому смена роли и удаление
+// пользователя действуют сразу, не дожидаясь истечения токена.
+func (s *AuthService) VerifyAccessToken(ctx context.Context, accessToken string) (*model.Principal, error) {
+	claims, err := s.parseToken(accessToken, tokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := claimsUserID(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	return &model.Principal{UserID: user.ID, Username: user.Username, Role: user.Role}, nil
+}
+
+// issueTokenPair выдает access-токен и сохраняет новый refresh-токен
+func (s *AuthService) issueTokenPair(ctx context.Context, user *model.User) (*model.TokenPair, error) {
+	now := s.now()
+
+	accessExpiresAt := now.Add(s.cfg.AccessTokenTTL.Duration)
+	accessToken, err := s.signToken(user, tokenTypeAccess, randomID(), now, accessExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh := &model.RefreshToken{
+		ID:        randomID(),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(s.cfg.RefreshTokenTTL.Duration),
+	}
+	refreshToken, err := s.signToken(user, tokenTypeRefresh, refresh.ID, now, refresh.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.CreateRefreshToken(ctx, refresh); err != nil {
+		return nil, err
+	}
+
+	return &model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.cfg.AccessTokenTTL.Seconds()),
+		ExpiresAt:    accessExpiresAt,
+	}, nil
+}
+
+func (s *AuthService) signToken(user *model.User, tokenType, id string, issuedAt, expiresAt time.Time) (string, error) {
+	claims := tokenClaims{
+		Username: user.Username,
+		Role:     user.Role,
+		Type:     tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			Issuer:    s.cfg.Issuer,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.cfg.JWTSecret))
+}
+
+// parseToken проверяет подпись, срок действия, издателя и тип токена
+func (s *AuthService) parseToken(token, tokenType string) (*tokenClaims, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(s.cfg.JWTSecret), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.cfg.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if err != nil || claims.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+func claimsUserID(claims *tokenClaims) (uint, error) {
+	id, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil || id == 0 {
+		return 0, ErrInvalidToken
+	}
+	return uint(id), nil
+}
+
+// HashPassword возвращает bcrypt-хеш пароля
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	})
+	return dummyHash
+}
+
+// randomID возвращает случайный идентификатор токена
+func randomID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
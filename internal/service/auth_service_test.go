@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestAuthService создает сервис с базой SQLite в памяти, в которой есть
+// библиотекарь с ID 7
+func newTestAuthService(t *testing.T) *AuthService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// База в памяти принадлежит одному соединению
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.RefreshToken{}))
+
+	users := repository.NewUserRepository(db)
+	require.NoError(t, users.Create(context.Background(), &model.User{ID: 7, Username: "librarian", Role: model.RoleLibrarian}))
+	return NewAuthService(users, config.AuthConfig{
+		JWTSecret:       "test-secret",
+		Issuer:          "book-library-api",
+		AccessTokenTTL:  config.Duration{Duration: 15 * time.Minute},
+		RefreshTokenTTL: config.Duration{Duration: time.Hour},
+	})
+}
+
+func TestVerifyAccessToken(t *testing.T) {
+	user := &model.User{ID: 7, Username: "librarian", Role: model.RoleLibrarian}
+	issuedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	accessToken := func(s *AuthService) string {
+		token, _ := s.signToken(user, tokenTypeAccess, "a", issuedAt, issuedAt.Add(15*time.Minute))
+		return token
+	}
+
+	testCases := []struct {
+		name          string
+		token         func(s *AuthService) string
+		arrange       func(t *testing.T, users *repository.UserRepository)
+		now           time.Time
+		expectedRole  string
+		expectedError bool
+	}{
+		{
+			name:         "Действительный токен",
+			token:        accessToken,
+			now:          issuedAt.Add(time.Minute),
+			expectedRole: model.RoleLibrarian,
+		},
+		{
+			name:  "Роль изменена после выдачи токена",
+			token: accessToken,
+			arrange: func(t *testing.T, users *repository.UserRepository) {
+				require.NoError(t, users.UpdateRole(context.Background(), 7, model.RolePatron))
+			},
+			now:          issuedAt.Add(time.Minute),
+			expectedRole: model.RolePatron,
+		},
+		{
+			name:  "Пользователь удален после выдачи токена",
+			token: accessToken,
+			arrange: func(t *testing.T, users *repository.UserRepository) {
+				require.NoError(t, users.Delete(context.Background(), 7))
+			},
+			now:           issuedAt.Add(time.Minute),
+			expectedError: true,
+		},
+		{
+			name:          "Просроченный токен",
+			token:         accessToken,
+			now:           issuedAt.Add(time.Hour),
+			expectedError: true,
+		},
+		{
+			name: "Refresh-токен вместо access-токена",
+			token: func(s *AuthService) string {
+				token, _ := s.signToken(user, tokenTypeRefresh, "r", issuedAt, issuedAt.Add(time.Hour))
+				return token
+			},
+			now:           issuedAt.Add(time.Minute),
+			expectedError: true,
+		},
+		{
+			name: "Другой ключ подписи",
+			token: func(s *AuthService) string {
+				other := *s
+				other.cfg.JWTSecret = "other-secret"
+				return accessToken(&other)
+			},
+			now:           issuedAt.Add(time.Minute),
+			expectedError: true,
+		},
+		{
+			name:          "Не JWT",
+			token:         func(*AuthService) string { return "not-a-token" },
+			now:           issuedAt,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			s := newTestAuthService(t)
+			s.now = func() time.Time { return tc.now }
+			token := tc.token(s)
+			if tc.arrange != nil {
+				tc.arrange(t, s.users)
+			}
+
+			// Act
+			principal, err := s.VerifyAccessToken(context.Background(), token)
+
+			// Assert
+			if tc.expectedError {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, uint(7), principal.UserID)
+			assert.Equal(t, "librarian", principal.Username)
+			assert.Equal(t, tc.expectedRole, principal.Role)
+		})
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	// Act
+	hash, err := HashPassword("correct horse")
+	_, weakErr := HashPassword("short")
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEqual(t, "correct horse", hash)
+	assert.ErrorIs(t, weakErr, ErrWeakPassword)
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+)
+
+// MaxBulkOperations ограничивает количество операций в одном пакетном запросе
+const MaxBulkOperations = 1000
+
+var (
+	// ErrTooManyOperations возвращается, если пакет превышает MaxBulkOperations
+	ErrTooManyOperations = fmt.Errorf("слишком много операций в пакете (максимум %d)", MaxBulkOperations)
+	// ErrUnknownBulkMode возвращается при неизвестном режиме выполнения пакета
+	ErrUnknownBulkMode = errors.New("неизвестный режим выполнения пакета")
+
+	errBulkRollback = errors.New("пакет отменен")
+)
+
+// BulkApply выполняет пакет операций над книгами.
+// В режиме transaction первая ошибка откатывает весь пакет, в режиме best_effort
+// каждая операция выполняется независимо. Проверка ISBN на дубликаты выполняется
+// одним запросом для всего пакета. Удаление в пакете, как и по отдельности,
+// доступно только администратору.
+func (s *BookService) BulkApply(ctx context.Context, req *model.BulkRequest) (_ *model.BulkResult, err error) {
+	ctx, span := startSpan(ctx, "BookService.BulkApply")
+	defer func() { endSpan(span, err) }()
+	mode := req.Mode
+	if mode == "" {
+		mode = model.BulkModeTransaction
+	}
+	if mode != model.BulkModeTransaction && mode != model.BulkModeBestEffort {
+		return nil, ErrUnknownBulkMode
+	}
+	if len(req.Operations) > MaxBulkOperations {
+		return nil, ErrTooManyOperations
+	}
+	for _, op := range req.Operations {
+		if err := authorize(ctx, bulkPermission(op.Op)); err != nil {
+			return nil, err
+		}
+	}
+
+	if mode == model.BulkModeBestEffort {
+		exec, err := newBulkExecutor(ctx, s.repo, req.Operations)
+		if err != nil {
+			return nil, err
+		}
+		result := exec.run(ctx, req.Operations, mode, false)
+		s.reportChanges(ctx, result)
+		return result, nil
+	}
+
+	var result *model.BulkResult
+	err = s.repo.Transaction(ctx, func(repo repository.BookStore) error {
+		exec, err := newBulkExecutor(ctx, repo, req.Operations)
+		if err != nil {
+			return err
+		}
+		result = exec.run(ctx, req.Operations, mode, true)
+		if result.Failed > 0 {
+			return errBulkRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkRollback) {
+		return nil, err
+	}
+	if errors.Is(err, errBulkRollback) {
+		slog.InfoContext(ctx, "пакет отменен", "operations", len(req.Operations), "failed", result.Failed)
+		markRolledBack(result)
+	}
+	s.reportChanges(ctx, result)
+
+	return result, nil
+}
+
+// reportChanges сбрасывает кэш каталога, если пакет что-то изменил, и сообщает
+// о созданных книгах; откаченные операции не учитываются
+func (s *BookService) reportChanges(ctx context.Context, result *model.BulkResult) {
+	if result.Succeeded > 0 {
+		s.cache.invalidate(ctx)
+	}
+	for _, item := range result.Results {
+		if item.Status == model.BulkStatusCreated {
+			s.events.BookCreated()
+		}
+	}
+}
+
+// bulkExecutor выполняет операции пакета, отслеживая занятые ISBN
+// и загруженные книги без повторных запросов к базе
+type bulkExecutor struct {
+	repo       repository.BookStore
+	isbnOwners map[string]uint
+	books      map[uint]*model.Book
+}
+
+func newBulkExecutor(ctx context.Context, repo repository.BookStore, ops []model.BulkOperation) (*bulkExecutor, error) {
+	var isbns []string
+	var ids []uint
+	for _, op := range ops {
+		if op.Book != nil && op.Book.ISBN != "" {
+			isbns = append(isbns, op.Book.ISBN)
+		}
+		if op.ID != 0 {
+			ids = append(ids, op.ID)
+		}
+	}
+
+	existing, err := repo.GetByISBNs(ctx, isbns)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	exec := &bulkExecutor{
+		repo:       repo,
+		isbnOwners: make(map[string]uint, len(existing)+len(targets)),
+		books:      make(map[uint]*model.Book, len(targets)),
+	}
+	for _, book := range existing {
+		exec.isbnOwners[book.ISBN] = book.ID
+	}
+	for i := range targets {
+		exec.isbnOwners[targets[i].ISBN] = targets[i].ID
+		exec.books[targets[i].ID] = &targets[i]
+	}
+	return exec, nil
+}
+
+// run выполняет операции по порядку. При stopOnError оставшиеся после
+// первой ошибки операции не выполняются.
+func (e *bulkExecutor) run(ctx context.Context, ops []model.BulkOperation, mode string, stopOnError bool) *model.BulkResult {
+	result := &model.BulkResult{Mode: mode, Results: make([]model.BulkItemResult, 0, len(ops))}
+	failed := false
+
+	for i, op := range ops {
+		item := model.BulkItemResult{Index: i, Op: op.Op, ID: op.ID}
+		if failed && stopOnError {
+			item.Status = model.BulkStatusRolledBack
+			result.Results = append(result.Results, item)
+			continue
+		}
+
+		if err := e.apply(ctx, op, &item); err != nil {
+			item.Status = model.BulkStatusFailed
+			item.Error = err.Error()
+			item.Book = nil
+			failed = true
+			result.Failed++
+		} else {
+			result.Succeeded++
+		}
+		result.Results = append(result.Results, item)
+	}
+
+	return result
+}
+
+func (e *bulkExecutor) apply(ctx context.Context, op model.BulkOperation, item *model.BulkItemResult) error {
+	switch op.Op {
+	case model.BulkOpCreate:
+		if err := validateBookCreate(op.Book); err != nil {
+			return err
+		}
+		if _, taken := e.isbnOwners[op.Book.ISBN]; taken {
+			return ErrDuplicateISBN
+		}
+
+		book := newBook(op.Book)
+		if err := e.repo.Create(ctx, book); err != nil {
+			return err
+		}
+		e.isbnOwners[book.ISBN] = book.ID
+		e.books[book.ID] = book
+
+		item.ID = book.ID
+		item.Book = book
+		item.Status = model.BulkStatusCreated
+
+	case model.BulkOpUpdate:
+		if op.ID == 0 {
+			return errors.New("не указан ID книги")
+		}
+		if err := validateBookCreate(op.Book); err != nil {
+			return err
+		}
+		current, ok := e.books[op.ID]
+		if !ok {
+			return ErrBookNotFound
+		}
+		if owner, taken := e.isbnOwners[op.Book.ISBN]; taken && owner != op.ID {
+			return ErrDuplicateISBN
+		}
+
+		book := *current
+		applyBookUpdate(&book, op.Book)
+		if err := e.repo.Update(ctx, &book); err != nil {
+			return err
+		}
+		delete(e.isbnOwners, current.ISBN)
+		e.isbnOwners[book.ISBN] = book.ID
+		e.books[book.ID] = &book
+
+		item.Book = &book
+		item.Status = model.BulkStatusUpdated
+
+	case model.BulkOpDelete:
+		if op.ID == 0 {
+			return errors.New("не указан ID книги")
+		}
+		current, ok := e.books[op.ID]
+		if !ok {
+			return ErrBookNotFound
+		}
+		if err := e.repo.Delete(ctx, op.ID); err != nil {
+			return err
+		}
+		delete(e.isbnOwners, current.ISBN)
+		delete(e.books, op.ID)
+
+		item.Status = model.BulkStatusDeleted
+
+	default:
+		return fmt.Errorf("неизвестная операция %q", op.Op)
+	}
+
+	return nil
+}
+
+// markRolledBack помечает успешно выполненные операции откаченного пакета
+func markRolledBack(result *model.BulkResult) {
+	for i := range result.Results {
+		item := &result.Results[i]
+		if item.Status == model.BulkStatusFailed {
+			continue
+		}
+		if item.Op == model.BulkOpCreate {
+			item.ID = 0
+		}
+		item.Book = nil
+		item.Status = model.BulkStatusRolledBack
+	}
+	result.Succeeded = 0
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBulkService создает сервис с одной книгой (ID 1, ISBN 1111111111)
+func newBulkService(t *testing.T) *BookService {
+	t.Helper()
+	service := NewBookService(repository.NewMemoryBookStore())
+	_, err := service.CreateBook(adminContext(), &model.BookCreate{Title: "Война и мир", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869})
+	require.NoError(t, err)
+	return service
+}
+
+// catalogTitles возвращает названия всех книг каталога
+func catalogTitles(t *testing.T, service *BookService) []string {
+	t.Helper()
+	books, err := service.GetAllBooks(context.Background(), 1, 100)
+	require.NoError(t, err)
+	titles := make([]string, 0, len(books))
+	for _, book := range books {
+		titles = append(titles, book.Title)
+	}
+	return titles
+}
+
+func bulkStatuses(result *model.BulkResult) []string {
+	statuses := make([]string, 0, len(result.Results))
+	for _, item := range result.Results {
+		statuses = append(statuses, item.Status)
+	}
+	return statuses
+}
+
+func TestBulkApply(t *testing.T) {
+	annaKarenina := &model.BookCreate{Title: "Анна Каренина", Author: "Лев Толстой", ISBN: "2222222222", Year: 1877}
+	voskresenie := &model.BookCreate{Title: "Воскресение", Author: "Лев Толстой", ISBN: "3333333333", Year: 1899}
+	renamed := &model.BookCreate{Title: "Война и мiръ", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869}
+
+	testCases := []struct {
+		name              string
+		mode              string
+		operations        []model.BulkOperation
+		expectedStatuses  []string
+		expectedSucceeded int
+		expectedFailed    int
+		expectedTitles    []string
+	}{
+		{
+			name: "Транзакция выполняется целиком",
+			operations: []model.BulkOperation{
+				{Op: model.BulkOpCreate, Book: annaKarenina},
+				{Op: model.BulkOpUpdate, ID: 1, Book: renamed},
+			},
+			expectedStatuses:  []string{model.BulkStatusCreated, model.BulkStatusUpdated},
+			expectedSucceeded: 2,
+			expectedTitles:    []string{"Война и мiръ", "Анна Каренина"},
+		},
+		{
+			name: "Ошибка откатывает всю транзакцию",
+			mode: model.BulkModeTransaction,
+			operations: []model.BulkOperation{
+				{Op: model.BulkOpCreate, Book: annaKarenina},
+				{Op: model.BulkOpUpdate, ID: 1, Book: renamed},
+				{Op: model.BulkOpDelete, ID: 42},
+				{Op: model.BulkOpCreate, Book: voskresenie},
+			},
+			expectedStatuses: []string{model.BulkStatusRolledBack, model.BulkStatusRolledBack, model.BulkStatusFailed, model.BulkStatusRolledBack},
+			expectedFailed:   1,
+			expectedTitles:   []string{"Война и мир"},
+		},
+		{
+			name: "best_effort сообщает о частичном успехе",
+			mode: model.BulkModeBestEffort,
+			operations: []model.BulkOperation{
+				{Op: model.BulkOpCreate, Book: annaKarenina},
+				{Op: model.BulkOpDelete, ID: 42},
+				{Op: model.BulkOpCreate, Book: voskresenie},
+			},
+			expectedStatuses:  []string{model.BulkStatusCreated, model.BulkStatusFailed, model.BulkStatusCreated},
+			expectedSucceeded: 2,
+			expectedFailed:    1,
+			expectedTitles:    []string{"Война и мир", "Анна Каренина", "Воскресение"},
+		},
+		{
+			name: "Дубликат ISBN внутри пакета",
+			mode: model.BulkModeBestEffort,
+			operations: []model.BulkOperation{
+				{Op: model.BulkOpCreate, Book: annaKarenina},
+				{Op: model.BulkOpCreate, Book: &model.BookCreate{Title: "Дубликат", Author: "Автор", ISBN: "2222222222", Year: 2024}},
+			},
+			expectedStatuses:  []string{model.BulkStatusCreated, model.BulkStatusFailed},
+			expectedSucceeded: 1,
+			expectedFailed:    1,
+			expectedTitles:    []string{"Война и мир", "Анна Каренина"},
+		},
+		{
+			name: "Неизвестная операция",
+			mode: model.BulkModeBestEffort,
+			operations: []model.BulkOperation{
+				{Op: "merge", ID: 1},
+				{Op: model.BulkOpCreate, Book: annaKarenina},
+			},
+			expectedStatuses:  []string{model.BulkStatusFailed, model.BulkStatusCreated},
+			expectedSucceeded: 1,
+			expectedFailed:    1,
+			expectedTitles:    []string{"Война и мир", "Анна Каренина"},
+		},
+		{
+			name:             "Пустой пакет",
+			operations:       []model.BulkOperation{},
+			expectedStatuses: []string{},
+			expectedTitles:   []string{"Война и мир"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			service := newBulkService(t)
+
+			// Act
+			result, err := service.BulkApply(adminContext(), &model.BulkRequest{Mode: tc.mode, Operations: tc.operations})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatuses, bulkStatuses(result))
+			assert.Equal(t, tc.expectedSucceeded, result.Succeeded)
+			assert.Equal(t, tc.expectedFailed, result.Failed)
+			assert.ElementsMatch(t, tc.expectedTitles, catalogTitles(t, service))
+			for _, item := range result.Results {
+				if item.Status == model.BulkStatusFailed {
+					assert.NotEmpty(t, item.Error)
+				}
+				if item.Status == model.BulkStatusRolledBack {
+					assert.Nil(t, item.Book)
+				}
+			}
+		})
+	}
+}
+
+func TestBulkApplyRejectsRequest(t *testing.T) {
+	testCases := []struct {
+		name          string
+		request       *model.BulkRequest
+		expectedError error
+	}{
+		{
+			name:          "Неизвестный режим",
+			request:       &model.BulkRequest{Mode: "eventually", Operations: []model.BulkOperation{{Op: model.BulkOpDelete, ID: 1}}},
+			expectedError: ErrUnknownBulkMode,
+		},
+		{
+			name:          "Слишком много операций",
+			request:       &model.BulkRequest{Operations: make([]model.BulkOperation, MaxBulkOperations+1)},
+			expectedError: ErrTooManyOperations,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			service := newBulkService(t)
+
+			// Act
+			result, err := service.BulkApply(adminContext(), tc.request)
+
+			// Assert
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Nil(t, result)
+			assert.Equal(t, []string{"Война и мир"}, catalogTitles(t, service))
+		})
+	}
+}
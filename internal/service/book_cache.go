@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/cache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// catalogGenerationKey хранит поколение кэша каталога. Ключи записей содержат
+// поколение, поэтому изменение каталога сбрасывает все записи одной операцией,
+// а результат чтения, начатого до изменения, не попадает в новое поколение.
+const catalogGenerationKey = "books:generation"
+
+// bookCache кэширует чтение каталога. Ошибки кэша не прерывают запрос:
+// данные читаются из хранилища, а ошибка записывается в журнал.
+type bookCache struct {
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+// SetCache включает кэширование книг, списков и результатов поиска на ttl.
+// Кэш сбрасывается при любом изменении каталога через сервис.
+func (s *BookService) SetCache(c cache.Cache, ttl time.Duration) {
+	s.cache = &bookCache{cache: c, ttl: ttl}
+}
+
+// cachedRead возвращает значение из кэша по имени записи или загружает его через load
+// и сохраняет. Ошибки load, например отсутствие книги, не кэшируются.
+func cachedRead[T any](ctx context.Context, c *bookCache, name string, load func(ctx context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+	generation, err := c.generation(ctx)
+	if err != nil {
+		c.warn(ctx, err)
+		return load(ctx)
+	}
+	key := "books:" + generation + ":" + name
+
+	data, ok, err := c.cache.Get(ctx, key)
+	if err != nil {
+		c.warn(ctx, err)
+	}
+	if ok {
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("cache.hit", true))
+			return value, nil
+		}
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("cache.hit", false))
+
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+	if data, err := json.Marshal(value); err == nil {
+		if err := c.cache.Set(ctx, key, data, c.ttl); err != nil {
+			c.warn(ctx, err)
+		}
+	}
+	return value, nil
+}
+
+// invalidate сбрасывает кэш каталога после изменения
+func (c *bookCache) invalidate(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	if err := c.cache.Set(ctx, catalogGenerationKey, []byte(newGeneration()), 0); err != nil {
+		c.warn(ctx, err)
+	}
+}
+
+// generation возвращает текущее поколение кэша, создавая его при первом обращении
+// или после вытеснения
+func (c *bookCache) generation(ctx context.Context) (string, error) {
+	data, ok, err := c.cache.Get(ctx, catalogGenerationKey)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return string(data), nil
+	}
+	generation := newGeneration()
+	return generation, c.cache.Set(ctx, catalogGenerationKey, []byte(generation), 0)
+}
+
+func (c *bookCache) warn(ctx context.Context, err error) {
+	slog.WarnContext(ctx, "ошибка кэша каталога", "error", err)
+}
+
+// newGeneration создает случайное поколение, чтобы экземпляры с общим кэшем
+// не получили одинаковые значения
+func newGeneration() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format(time.RFC3339Nano)
+	}
+	return hex.EncodeToString(b)
+}
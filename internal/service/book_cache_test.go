@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/cache"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStore подсчитывает чтения каталога из хранилища
+type countingStore struct {
+	repository.BookStore
+	reads int
+}
+
+func (s *countingStore) GetByID(ctx context.Context, id uint) (*model.Book, error) {
+	s.reads++
+	return s.BookStore.GetByID(ctx, id)
+}
+
+func (s *countingStore) GetAll(ctx context.Context, page, pageSize int) ([]model.Book, error) {
+	s.reads++
+	return s.BookStore.GetAll(ctx, page, pageSize)
+}
+
+func (s *countingStore) Search(ctx context.Context, query string) ([]model.Book, error) {
+	s.reads++
+	return s.BookStore.Search(ctx, query)
+}
+
+// failingCache отвечает ошибкой на любое обращение
+type failingCache struct{}
+
+func (failingCache) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("кэш недоступен")
+}
+
+func (failingCache) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("кэш недоступен")
+}
+
+func (failingCache) Delete(context.Context, ...string) error {
+	return errors.New("кэш недоступен")
+}
+
+// readCatalog читает книгу, страницу списка и результаты поиска
+func readCatalog(t *testing.T, service *BookService, id uint) *model.Book {
+	ctx := context.Background()
+	book, err := service.GetBookByID(ctx, id)
+	require.NoError(t, err)
+	_, err = service.GetAllBooks(ctx, 1, 10)
+	require.NoError(t, err)
+	_, err = service.SearchBooks(ctx, "Толстой")
+	require.NoError(t, err)
+	return book
+}
+
+func TestBookServiceCache(t *testing.T) {
+	testCases := []struct {
+		name          string
+		change        func(ctx context.Context, s *BookService, id uint) error
+		expectedTitle string
+	}{
+		{name: "Создание книги", change: func(ctx context.Context, s *BookService, id uint) error {
+			_, err := s.CreateBook(ctx, &model.BookCreate{Title: "Анна Каренина", Author: "Лев Толстой", ISBN: "2222222222", Year: 1877})
+			return err
+		}, expectedTitle: "Война и мир"},
+		{name: "Обновление книги", change: func(ctx context.Context, s *BookService, id uint) error {
+			_, err := s.UpdateBook(ctx, id, &model.BookCreate{Title: "Война и мiръ", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869})
+			return err
+		}, expectedTitle: "Война и мiръ"},
+		{name: "Изменение доступности", change: func(ctx context.Context, s *BookService, id uint) error {
+			_, err := s.ToggleBookAvailability(ctx, id)
+			return err
+		}, expectedTitle: "Война и мир"},
+		{name: "Пакетное обновление", change: func(ctx context.Context, s *BookService, id uint) error {
+			_, err := s.BulkApply(ctx, &model.BulkRequest{Operations: []model.BulkOperation{
+				{Op: model.BulkOpUpdate, ID: id, Book: &model.BookCreate{Title: "Война и мiръ", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869}},
+			}})
+			return err
+		}, expectedTitle: "Война и мiръ"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctx := adminContext()
+			store := &countingStore{BookStore: repository.NewMemoryBookStore()}
+			service := NewBookService(store)
+			service.SetCache(cache.NewLRU(100), time.Minute)
+			book, err := service.CreateBook(ctx, &model.BookCreate{Title: "Война и мир", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869})
+			require.NoError(t, err)
+			readCatalog(t, service, book.ID)
+			readCatalog(t, service, book.ID)
+			require.Equal(t, 3, store.reads, "повторное чтение берется из кэша")
+
+			// Act
+			require.NoError(t, tc.change(ctx, service, book.ID))
+			store.reads = 0
+			cached := readCatalog(t, service, book.ID)
+
+			// Assert
+			assert.Equal(t, 3, store.reads, "после изменения каталог читается из хранилища")
+			assert.Equal(t, tc.expectedTitle, cached.Title)
+		})
+	}
+}
+
+func TestBookServiceCacheDelete(t *testing.T) {
+	// Arrange
+	ctx := adminContext()
+	service := NewBookService(repository.NewMemoryBookStore())
+	service.SetCache(cache.NewLRU(100), time.Minute)
+	book, err := service.CreateBook(ctx, &model.BookCreate{Title: "Война и мир", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869})
+	require.NoError(t, err)
+	readCatalog(t, service, book.ID)
+
+	// Act
+	require.NoError(t, service.DeleteBook(ctx, book.ID))
+	_, getErr := service.GetBookByID(ctx, book.ID)
+	books, listErr := service.GetAllBooks(ctx, 1, 10)
+
+	// Assert
+	assert.Error(t, getErr)
+	assert.NoError(t, listErr)
+	assert.Empty(t, books)
+}
+
+func TestBookServiceCacheFailure(t *testing.T) {
+	// Arrange: при недоступном кэше данные читаются из хранилища
+	ctx := adminContext()
+	store := &countingStore{BookStore: repository.NewMemoryBookStore()}
+	service := NewBookService(store)
+	service.SetCache(failingCache{}, time.Minute)
+	book, err := service.CreateBook(ctx, &model.BookCreate{Title: "Война и мир", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869})
+	require.NoError(t, err)
+
+	// Act
+	readCatalog(t, service, book.ID)
+	cached := readCatalog(t, service, book.ID)
+
+	// Assert
+	assert.Equal(t, 6, store.reads)
+	assert.Equal(t, "Война и мир", cached.Title)
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// exportBatchSize задает количество книг, загружаемых из базы за один запрос при выгрузке
+const exportBatchSize = 500
+
+// csvExportHeader задает колонки выгружаемого CSV
+var csvExportHeader = []string{
+	"id", "title", "author", "isbn", "description", "year", "publisher", "available", "created_at", "updated_at",
+}
+
+// csvImportFields задает поля книги, которые можно загрузить из CSV
+var csvImportFields = []string{"title", "author", "isbn", "description", "year", "publisher"}
+
+// csvFormulaPrefixes - первые символы ячейки, с которых табличные редакторы
+// начинают формулу
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// ErrInvalidCSV возвращается, если файл импорта не удалось разобрать
+var ErrInvalidCSV = errors.New("неверный формат CSV")
+
+// CSVImportOptions представляет параметры импорта каталога из CSV
+type CSVImportOptions struct {
+	// Mapping сопоставляет поле книги (title, author, isbn, description, year, publisher)
+	// с заголовком колонки файла. Для полей без сопоставления ищется колонка с именем поля.
+	Mapping map[string]string
+	// Delimiter задает разделитель колонок, по умолчанию запятая
+	Delimiter rune
+	// DryRun включает режим проверки без сохранения
+	DryRun bool
+}
+
+// ExportCSV записывает в w книги, удовлетворяющие фильтру, в формате CSV.
+// Книги загружаются из базы порциями, поэтому каталог любого размера
+// выгружается без загрузки в память целиком. Текстовые поля экранируются
+// через csvSafe, чтобы выгрузка не выполняла формулы в табличном редакторе.
+func (s *BookService) ExportCSV(ctx context.Context, w io.Writer, filter *model.BookFilter) (err error) {
+	ctx, span := startSpan(ctx, "BookService.ExportCSV")
+	defer func() { endSpan(span, err) }()
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvExportHeader); err != nil {
+		return err
+	}
+
+	err = s.repo.FindInBatches(ctx, filter, exportBatchSize, func(books []model.Book) error {
+		for _, book := range books {
+			record := []string{
+				strconv.FormatUint(uint64(book.ID), 10),
+				csvSafe(book.Title),
+				csvSafe(book.Author),
+				csvSafe(book.ISBN),
+				csvSafe(book.Description),
+				strconv.Itoa(book.Year),
+				csvSafe(book.Publisher),
+				strconv.FormatBool(book.Available),
+				book.CreatedAt.Format(time.RFC3339),
+				book.UpdatedAt.Format(time.RFC3339),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ImportCSV загружает книги из CSV. Книги с существующим ISBN обновляются
+// через UpdateBook, новые создаются через CreateBook.
+func (s *BookService) ImportCSV(ctx context.Context, r io.Reader, opts CSVImportOptions) (_ *model.ImportReport, err error) {
+	ctx, span := startSpan(ctx, "BookService.ImportCSV")
+	defer func() { endSpan(span, err) }()
+	records, err := parseCSVRecords(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.importBooks(ctx, records, opts.DryRun)
+}
+
+// csvFormulaStart сообщает, что значение нужно экранировать при выгрузке:
+// оно начинается с формулы или с апострофа перед таким значением. Второе
+// правило нужно, чтобы csvUnescape не снял апостроф, который был в данных.
+func csvFormulaStart(value string) bool {
+	if value == "" {
+		return false
+	}
+	if value[0] == '\'' {
+		return csvFormulaStart(value[1:])
+	}
+	return strings.ContainsRune(csvFormulaPrefixes, rune(value[0]))
+}
+
+// csvSafe добавляет апостроф перед значением, которое табличный редактор
+// принял бы за формулу
+func csvSafe(value string) string {
+	if csvFormulaStart(value) {
+		return "'" + value
+	}
+	return value
+}
+
+// csvUnescape снимает апостроф, добавленный csvSafe, чтобы повторный импорт
+// выгрузки не менял данные. Прочие апострофы в начале значения сохраняются.
+func csvUnescape(value string) string {
+	if strings.HasPrefix(value, "'") && csvFormulaStart(value[1:]) {
+		return value[1:]
+	}
+	return value
+}
+
+// parseCSVRecords разбирает CSV в записи импорта. Ошибки отдельных строк
+// сохраняются в записях, ошибка возвращается только для файла целиком.
+func parseCSVRecords(r io.Reader, opts CSVImportOptions) ([]importRecord, error) {
+	reader := csv.NewReader(r)
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: не удалось прочитать заголовок: %v", ErrInvalidCSV, err)
+	}
+
+	columns, err := resolveCSVColumns(header, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []importRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				records = append(records, importRecord{Line: parseErr.StartLine, Err: parseErr.Err})
+				continue
+			}
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		book, err := csvRowToBook(row, columns)
+		records = append(records, importRecord{Line: line, Book: book, Err: err})
+	}
+
+	return records, nil
+}
+
+// resolveCSVColumns находит номера колонок для полей книги
+func resolveCSVColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	for field := range mapping {
+		if !slices.Contains(csvImportFields, field) {
+			return nil, fmt.Errorf("%w: неизвестное поле %q в сопоставлении колонок", ErrInvalidCSV, field)
+		}
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	columns := make(map[string]int, len(csvImportFields))
+	for _, field := range csvImportFields {
+		name := field
+		if mapped, ok := mapping[field]; ok {
+			name = mapped
+		}
+		if i, ok := index[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+
+	for _, field := range []string{"title", "author", "isbn", "year"} {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("%w: не найдена колонка для поля %q", ErrInvalidCSV, field)
+		}
+	}
+
+	return columns, nil
+}
+
+// csvRowToBook преобразует строку CSV в данные книги
+func csvRowToBook(row []string, columns map[string]int) (*model.BookCreate, error) {
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(csvUnescape(row[i]))
+	}
+
+	book := &model.BookCreate{
+		Title:       value("title"),
+		Author:      value("author"),
+		ISBN:        value("isbn"),
+		Description: value("description"),
+		Publisher:   value("publisher"),
+	}
+
+	if year := value("year"); year != "" {
+		parsed, err := strconv.Atoi(year)
+		if err != nil {
+			return book, fmt.Errorf("неверный год издания %q", year)
+		}
+		book.Year = parsed
+	}
+
+	return book, nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSVRecords(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		opts          CSVImportOptions
+		expectedCount int
+		expectedError bool
+		check         func(t *testing.T, records []importRecord)
+	}{
+		{
+			name: "Колонки по умолчанию",
+			input: "title,author,isbn,year,publisher\n" +
+				"Война и мир,Лев Толстой,9785171147440,1869,АСТ\n" +
+				"Анна Каренина,Лев Толстой,9785171147457,1878,АСТ\n",
+			expectedCount: 2,
+			check: func(t *testing.T, records []importRecord) {
+				assert.NoError(t, records[0].Err)
+				assert.Equal(t, 2, records[0].Line)
+				assert.Equal(t, "Война и мир", records[0].Book.Title)
+				assert.Equal(t, 1869, records[0].Book.Year)
+				assert.Equal(t, 3, records[1].Line)
+			},
+		},
+		{
+			name: "Сопоставление колонок и разделитель",
+			input: "\ufeffНазвание;Автор;ISBN;Год\n" +
+				"Мастер и Маргарита;Михаил Булгаков;9785171147464;1967\n",
+			opts: CSVImportOptions{
+				Delimiter: ';',
+				Mapping:   map[string]string{"title": "Название", "author": "Автор", "year": "Год"},
+			},
+			expectedCount: 1,
+			check: func(t *testing.T, records []importRecord) {
+				assert.NoError(t, records[0].Err)
+				assert.Equal(t, "Михаил Булгаков", records[0].Book.Author)
+				assert.Equal(t, "9785171147464", records[0].Book.ISBN)
+			},
+		},
+		{
+			name: "Неверный год в строке",
+			input: "title,author,isbn,year\n" +
+				"Книга,Автор,1111111111,давно\n",
+			expectedCount: 1,
+			check: func(t *testing.T, records []importRecord) {
+				assert.Error(t, records[0].Err)
+			},
+		},
+		{
+			name: "Апостроф в начале названия",
+			input: "title,author,isbn,year\n" +
+				"'Tis Pity She's a Whore,Джон Форд,1111111111,1633\n" +
+				"'Salem's Lot,Стивен Кинг,2222222222,1975\n" +
+				"'=1+1,Автор,3333333333,2024\n",
+			expectedCount: 3,
+			check: func(t *testing.T, records []importRecord) {
+				assert.Equal(t, "'Tis Pity She's a Whore", records[0].Book.Title)
+				assert.Equal(t, "'Salem's Lot", records[1].Book.Title)
+				assert.Equal(t, "=1+1", records[2].Book.Title, "апостроф выгрузки снимается")
+			},
+		},
+		{
+			name:          "Нет обязательной колонки",
+			input:         "title,author,year\nКнига,Автор,2024\n",
+			expectedError: true,
+		},
+		{
+			name:          "Неизвестное поле в сопоставлении",
+			input:         "title,author,isbn,year\n",
+			opts:          CSVImportOptions{Mapping: map[string]string{"price": "Цена"}},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			records, err := parseCSVRecords(strings.NewReader(tc.input), tc.opts)
+
+			// Assert
+			if tc.expectedError {
+				assert.ErrorIs(t, err, ErrInvalidCSV)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, records, tc.expectedCount)
+			if tc.check != nil {
+				tc.check(t, records)
+			}
+		})
+	}
+}
+
+func TestExportCSVEscapesFormulas(t *testing.T) {
+	testCases := []struct {
+		name          string
+		title         string
+		expectedTitle string
+	}{
+		{name: "Обычное название", title: "Война и мир", expectedTitle: "Война и мир"},
+		{name: "Знак равенства", title: "=HYPERLINK(\"http://evil\")", expectedTitle: "'=HYPERLINK(\"http://evil\")"},
+		{name: "Плюс", title: "+7 способов", expectedTitle: "'+7 способов"},
+		{name: "Минус", title: "-1 глава", expectedTitle: "'-1 глава"},
+		{name: "Собака", title: "@SUM(A1:A2)", expectedTitle: "'@SUM(A1:A2)"},
+		{name: "Табуляция", title: "\tЗаголовок", expectedTitle: "'\tЗаголовок"},
+		{name: "Возврат каретки", title: "\rЗаголовок", expectedTitle: "'\rЗаголовок"},
+		{name: "Апостроф в названии", title: "'Salem's Lot", expectedTitle: "'Salem's Lot"},
+		{name: "Апостроф перед формулой", title: "'=1+1", expectedTitle: "''=1+1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctx := adminContext()
+			service := NewBookService(repository.NewMemoryBookStore())
+			_, err := service.CreateBook(ctx, &model.BookCreate{
+				Title: tc.title, Author: "Лев Толстой", ISBN: "1111111111", Year: 1869, Description: "=1+1",
+			})
+			require.NoError(t, err)
+			var buf bytes.Buffer
+
+			// Act
+			require.NoError(t, service.ExportCSV(ctx, &buf, &model.BookFilter{}))
+			rows, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+			require.NoError(t, err)
+			records, parseErr := parseCSVRecords(bytes.NewReader(buf.Bytes()), CSVImportOptions{})
+
+			// Assert
+			require.Len(t, rows, 2)
+			assert.Equal(t, tc.expectedTitle, rows[1][1])
+			assert.Equal(t, "Лев Толстой", rows[1][2])
+			assert.Equal(t, "'=1+1", rows[1][4])
+			require.NoError(t, parseErr)
+			require.Len(t, records, 1)
+			assert.Equal(t, strings.TrimSpace(tc.title), records[0].Book.Title, "импорт снимает апостроф")
+			assert.Equal(t, "=1+1", records[0].Book.Description)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// importRecord представляет запись, разобранную из импортируемого файла
+type importRecord struct {
+	Line int
+	Book *model.BookCreate
+	Err  error
+}
+
+// importBooks создает или обновляет книги по ISBN через CreateBook и UpdateBook.
+// В режиме dryRun данные только проверяются, а в отчете указывается, что было бы сделано.
+func (s *BookService) importBooks(ctx context.Context, records []importRecord, dryRun bool) (*model.ImportReport, error) {
+	if err := authorize(ctx, model.PermissionWriteBooks); err != nil {
+		return nil, err
+	}
+	var isbns []string
+	for _, rec := range records {
+		if rec.Err == nil && rec.Book != nil && rec.Book.ISBN != "" {
+			isbns = append(isbns, rec.Book.ISBN)
+		}
+	}
+
+	existing, err := s.repo.GetByISBNs(ctx, isbns)
+	if err != nil {
+		return nil, err
+	}
+	owners := make(map[string]uint, len(existing))
+	for _, book := range existing {
+		owners[book.ISBN] = book.ID
+	}
+
+	report := &model.ImportReport{DryRun: dryRun, Total: len(records), Rows: make([]model.ImportRowResult, 0, len(records))}
+	seen := make(map[string]int, len(records))
+
+	for _, rec := range records {
+		// При отмене запроса остальные записи не обрабатываются, а частичный отчет не возвращается
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		row := model.ImportRowResult{Line: rec.Line}
+		if rec.Book != nil {
+			row.ISBN = rec.Book.ISBN
+			row.Title = rec.Book.Title
+		}
+
+		if err := s.importRecord(ctx, rec, dryRun, owners, seen, &row); err != nil {
+			row.Action = model.ImportActionFailed
+			row.Error = err.Error()
+		}
+
+		switch row.Action {
+		case model.ImportActionCreated, model.ImportActionWouldCreate:
+			report.Created++
+		case model.ImportActionUpdated, model.ImportActionWouldUpdate:
+			report.Updated++
+		default:
+			report.Failed++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	slog.InfoContext(ctx, "импорт книг завершен", "dry_run", dryRun, "total", report.Total,
+		"created", report.Created, "updated", report.Updated, "failed", report.Failed)
+	return report, nil
+}
+
+func (s *BookService) importRecord(ctx context.Context, rec importRecord, dryRun bool, owners map[string]uint, seen map[string]int, row *model.ImportRowResult) error {
+	if rec.Err != nil {
+		return rec.Err
+	}
+	if err := validateBookCreate(rec.Book); err != nil {
+		return err
+	}
+	if line, dup := seen[rec.Book.ISBN]; dup {
+		return fmt.Errorf("ISBN уже встречался в записи %d", line)
+	}
+	seen[rec.Book.ISBN] = rec.Line
+
+	id, exists := owners[rec.Book.ISBN]
+	switch {
+	case dryRun && exists:
+		row.ID = id
+		row.Action = model.ImportActionWouldUpdate
+	case dryRun:
+		row.Action = model.ImportActionWouldCreate
+	case exists:
+		book, err := s.UpdateBook(ctx, id, rec.Book)
+		if err != nil {
+			return err
+		}
+		row.ID = book.ID
+		row.Action = model.ImportActionUpdated
+	default:
+		book, err := s.CreateBook(ctx, rec.Book)
+		if err != nil {
+			return err
+		}
+		row.ID = book.ID
+		row.Action = model.ImportActionCreated
+	}
+	return nil
+}
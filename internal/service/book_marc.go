@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/pkg/marc"
+)
+
+// Форматы обмена записями MARC21
+const (
+	MARCFormatISO2709 = "marc"
+	MARCFormatXML     = "marcxml"
+)
+
+// ErrUnsupportedFormat возвращается при неизвестном формате импорта или выгрузки
+var ErrUnsupportedFormat = errors.New("неподдерживаемый формат")
+
+var yearPattern = regexp.MustCompile(`\d{4}`)
+
+// ExportMARC записывает в w книги, удовлетворяющие фильтру, в формате MARC21
+// (ISO 2709 или MARCXML)
+func (s *BookService) ExportMARC(ctx context.Context, w io.Writer, filter *model.BookFilter, format string) (err error) {
+	ctx, span := startSpan(ctx, "BookService.ExportMARC")
+	defer func() { endSpan(span, err) }()
+	var write func(*marc.Record) error
+	closeWriter := func() error { return nil }
+
+	switch format {
+	case MARCFormatISO2709:
+		write = marc.NewWriter(w).Write
+	case MARCFormatXML:
+		xw := marc.NewXMLWriter(w)
+		write = xw.Write
+		closeWriter = xw.Close
+	default:
+		return ErrUnsupportedFormat
+	}
+
+	err = s.repo.FindInBatches(ctx, filter, exportBatchSize, func(books []model.Book) error {
+		for i := range books {
+			if err := write(bookToMARC(&books[i])); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return closeWriter()
+}
+
+// ImportMARC загружает книги из записей MARC21 с обновлением существующих по ISBN.
+// Номер строки в отчете соответствует порядковому номеру записи в файле.
+func (s *BookService) ImportMARC(ctx context.Context, r io.Reader, format string, dryRun bool) (_ *model.ImportReport, err error) {
+	ctx, span := startSpan(ctx, "BookService.ImportMARC")
+	defer func() { endSpan(span, err) }()
+	var read func() (*marc.Record, error)
+	switch format {
+	case MARCFormatISO2709:
+		read = marc.NewReader(r).Read
+	case MARCFormatXML:
+		read = marc.NewXMLReader(r).Read
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	var records []importRecord
+	for n := 1; ; n++ {
+		rec, err := read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Поврежденную запись ISO 2709 можно пропустить, ошибка разбора XML фатальна
+			if format == MARCFormatISO2709 && errors.Is(err, marc.ErrInvalidRecord) {
+				records = append(records, importRecord{Line: n, Err: err})
+				continue
+			}
+			return nil, err
+		}
+		records = append(records, importRecord{Line: n, Book: bookFromMARC(rec)})
+	}
+
+	return s.importBooks(ctx, records, dryRun)
+}
+
+// bookToMARC формирует запись MARC21 для книги. Неизвестный год записывается
+// в 008 как uuuu, а подполе 264$c опускается.
+func bookToMARC(book *model.Book) *marc.Record {
+	year, date := "uuuu", ""
+	if book.Year > 0 && book.Year <= 9999 {
+		year, date = fmt.Sprintf("%04d", book.Year), strconv.Itoa(book.Year)
+	}
+
+	rec := &marc.Record{
+		Leader: marc.DefaultLeader,
+		ControlFields: []marc.ControlField{
+			{Tag: "001", Value: strconv.FormatUint(uint64(book.ID), 10)},
+			{Tag: "005", Value: book.UpdatedAt.UTC().Format("20060102150405.0")},
+			{Tag: "008", Value: book.CreatedAt.UTC().Format("060102") + "s" + year + strings.Repeat(" ", 29)},
+		},
+	}
+	rec.AddDataField(marc.NewDataField("020", ' ', ' ', marc.Subfield{Code: 'a', Value: book.ISBN}))
+	rec.AddDataField(marc.NewDataField("100", '0', ' ', marc.Subfield{Code: 'a', Value: book.Author}))
+	rec.AddDataField(marc.NewDataField("245", '1', '0', marc.Subfield{Code: 'a', Value: book.Title}))
+	rec.AddDataField(marc.NewDataField("264", ' ', '1',
+		marc.Subfield{Code: 'b', Value: book.Publisher},
+		marc.Subfield{Code: 'c', Value: date},
+	))
+	rec.AddDataField(marc.NewDataField("520", ' ', ' ', marc.Subfield{Code: 'a', Value: book.Description}))
+	return rec
+}
+
+// bookFromMARC извлекает данные книги из записи MARC21:
+// 020 - ISBN, 100 - автор, 245 - название, 260/264 - издательство и год, 520 - аннотация
+func bookFromMARC(rec *marc.Record) *model.BookCreate {
+	book := &model.BookCreate{
+		ISBN:        normalizeISBN(rec.SubfieldValue("020", 'a')),
+		Description: strings.TrimSpace(rec.SubfieldValue("520", 'a')),
+	}
+
+	if fields := rec.Fields("100"); len(fields) > 0 {
+		book.Author = trimISBD(fields[0].Subfield('a'))
+		// Первый индикатор 1 означает инверсию "Фамилия, Имя"
+		if fields[0].Ind1 == '1' {
+			if surname, name, ok := strings.Cut(book.Author, ", "); ok {
+				book.Author = name + " " + surname
+			}
+		}
+	}
+
+	if fields := rec.Fields("245"); len(fields) > 0 {
+		book.Title = trimISBD(fields[0].Subfield('a'))
+		if subtitle := trimISBD(fields[0].Subfield('b')); subtitle != "" {
+			book.Title += " : " + subtitle
+		}
+	}
+
+	publication := publicationField(rec)
+	if publication != nil {
+		book.Publisher = trimISBD(publication.Subfield('b'))
+		if year := yearPattern.FindString(publication.Subfield('c')); year != "" {
+			book.Year, _ = strconv.Atoi(year)
+		}
+	}
+	if book.Year == 0 {
+		// Позиции 7-10 поля 008 содержат год издания
+		if f008 := rec.ControlField("008"); len(f008) >= 11 {
+			book.Year, _ = strconv.Atoi(f008[7:11])
+		}
+	}
+
+	return book
+}
+
+// publicationField возвращает поле 264 со сведениями о публикации или, при его отсутствии, поле 260
+func publicationField(rec *marc.Record) *marc.DataField {
+	for _, f := range rec.Fields("264") {
+		if f.Ind2 == '1' {
+			return &f
+		}
+	}
+	if fields := rec.Fields("260"); len(fields) > 0 {
+		return &fields[0]
+	}
+	return nil
+}
+
+// trimISBD удаляет завершающие знаки пунктуации ISBD
+func trimISBD(value string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(value), " /:;,.="))
+}
+
+// normalizeISBN оставляет в ISBN только цифры и контрольный символ X,
+// отбрасывая уточнения вида "(переплет)"
+func normalizeISBN(value string) string {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == 'X' {
+			return r
+		}
+		return -1
+	}, strings.ToUpper(fields[0]))
+}
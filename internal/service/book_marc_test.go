@@ -0,0 +1,134 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/pkg/marc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookFromMARC(t *testing.T) {
+	testCases := []struct {
+		name     string
+		record   func() *marc.Record
+		expected *model.BookCreate
+	}{
+		{
+			name: "Запись с полем 260 и пунктуацией ISBD",
+			record: func() *marc.Record {
+				rec := &marc.Record{}
+				rec.AddDataField(marc.NewDataField("020", ' ', ' ', marc.Subfield{Code: 'a', Value: "978-5-17-114744-0 (в пер.)"}))
+				rec.AddDataField(marc.NewDataField("100", '1', ' ', marc.Subfield{Code: 'a', Value: "Толстой, Лев,"}))
+				rec.AddDataField(marc.NewDataField("245", '1', '0',
+					marc.Subfield{Code: 'a', Value: "Война и мир :"},
+					marc.Subfield{Code: 'b', Value: "роман /"},
+				))
+				rec.AddDataField(marc.NewDataField("260", ' ', ' ',
+					marc.Subfield{Code: 'b', Value: "АСТ,"},
+					marc.Subfield{Code: 'c', Value: "c1869."},
+				))
+				rec.AddDataField(marc.NewDataField("520", ' ', ' ', marc.Subfield{Code: 'a', Value: "Роман-эпопея"}))
+				return rec
+			},
+			expected: &model.BookCreate{
+				Title:       "Война и мир : роман",
+				Author:      "Лев Толстой",
+				ISBN:        "9785171147440",
+				Description: "Роман-эпопея",
+				Year:        1869,
+				Publisher:   "АСТ",
+			},
+		},
+		{
+			name: "Поле 264 и год из поля 008",
+			record: func() *marc.Record {
+				rec := &marc.Record{
+					ControlFields: []marc.ControlField{{Tag: "008", Value: "240101s1967    ru            000 1 rus d"}},
+				}
+				rec.AddDataField(marc.NewDataField("020", ' ', ' ', marc.Subfield{Code: 'a', Value: "9785171147464"}))
+				rec.AddDataField(marc.NewDataField("100", '0', ' ', marc.Subfield{Code: 'a', Value: "Михаил Булгаков"}))
+				rec.AddDataField(marc.NewDataField("245", '1', '0', marc.Subfield{Code: 'a', Value: "Мастер и Маргарита"}))
+				rec.AddDataField(marc.NewDataField("264", ' ', '4', marc.Subfield{Code: 'c', Value: "©2020"}))
+				rec.AddDataField(marc.NewDataField("264", ' ', '1', marc.Subfield{Code: 'b', Value: "Азбука"}))
+				return rec
+			},
+			expected: &model.BookCreate{
+				Title:     "Мастер и Маргарита",
+				Author:    "Михаил Булгаков",
+				ISBN:      "9785171147464",
+				Year:      1967,
+				Publisher: "Азбука",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			book := bookFromMARC(tc.record())
+
+			// Assert
+			assert.Equal(t, tc.expected, book)
+		})
+	}
+}
+
+func TestBookToMARCRoundTrip(t *testing.T) {
+	// Arrange
+	book := &model.Book{
+		ID:          7,
+		Title:       "Преступление и наказание",
+		Author:      "Федор Достоевский",
+		ISBN:        "9785171147457",
+		Description: "Социально-психологический роман",
+		Year:        1866,
+		Publisher:   "АСТ",
+		CreatedAt:   time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC),
+		UpdatedAt:   time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Act
+	rec := bookToMARC(book)
+	restored := bookFromMARC(rec)
+
+	// Assert
+	assert.Equal(t, "7", rec.ControlField("001"))
+	assert.Len(t, rec.ControlField("008"), 40)
+	assert.Equal(t, &model.BookCreate{
+		Title:       book.Title,
+		Author:      book.Author,
+		ISBN:        book.ISBN,
+		Description: book.Description,
+		Year:        book.Year,
+		Publisher:   book.Publisher,
+	}, restored)
+}
+
+func TestBookToMARCWithoutYear(t *testing.T) {
+	testCases := []struct {
+		name          string
+		publisher     string
+		expectedField bool
+	}{
+		{name: "Издательство без года", publisher: "АСТ", expectedField: true},
+		{name: "Нет ни издательства, ни года"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			book := &model.Book{ID: 7, Title: "Слово о полку Игореве", ISBN: "9785171147457", Publisher: tc.publisher}
+
+			// Act
+			rec := bookToMARC(book)
+
+			// Assert
+			assert.Equal(t, "uuuu", rec.ControlField("008")[7:11])
+			assert.Empty(t, rec.SubfieldValue("264", 'c'))
+			assert.Equal(t, tc.expectedField, len(rec.Fields("264")) > 0)
+			assert.Equal(t, 0, bookFromMARC(rec).Year)
+		})
+	}
+}
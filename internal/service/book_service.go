@@ -1,114 +1,293 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/krawwwwy/book-library-api/internal/model"
 	"github.com/krawwwwy/book-library-api/internal/repository"
+	"gorm.io/gorm"
 )
 
+var (
+	// ErrDuplicateISBN возвращается, если книга с таким ISBN уже существует
+	ErrDuplicateISBN = errors.New("книга с таким ISBN уже существует")
+	// ErrBookNotFound возвращается, если книга не найдена
+	ErrBookNotFound = errors.New("книга не найдена")
+	// ErrInvalidBook возвращается, если не заполнены обязательные поля книги
+	ErrInvalidBook = errors.New("неверные данные книги")
+)
+
+// BookEvents получает уведомления о событиях каталога, например для сбора метрик
+type BookEvents interface {
+	BookCreated()
+	BookCheckedOut()
+	BookReturned()
+	SearchCompleted(results int)
+}
+
+// noBookEvents используется, пока получатель событий не задан
+type noBookEvents struct{}
+
+func (noBookEvents) BookCreated()        {}
+func (noBookEvents) BookCheckedOut()     {}
+func (noBookEvents) BookReturned()       {}
+func (noBookEvents) SearchCompleted(int) {}
+
 // BookService представляет сервис для работы с книгами
 type BookService struct {
-	repo *repository.BookRepository
+	repo   repository.BookStore
+	events BookEvents
+	cache  *bookCache
 }
 
 // NewBookService создает новый экземпляр BookService
-func NewBookService(repo *repository.BookRepository) *BookService {
-	return &BookService{repo: repo}
+func NewBookService(repo repository.BookStore) *BookService {
+	return &BookService{repo: repo, events: noBookEvents{}}
+}
+
+// SetEvents задает получателя событий каталога
+func (s *BookService) SetEvents(events BookEvents) {
+	s.events = events
 }
 
 // CreateBook создает новую книгу
-func (s *BookService) CreateBook(bookCreate *model.BookCreate) (*model.Book, error) {
+func (s *BookService) CreateBook(ctx context.Context, bookCreate *model.BookCreate) (_ *model.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.CreateBook")
+	defer func() { endSpan(span, err) }()
+	if err := authorize(ctx, model.PermissionWriteBooks); err != nil {
+		return nil, err
+	}
+	if err := validateBookCreate(bookCreate); err != nil {
+		return nil, err
+	}
+
 	// Проверяем, существует ли книга с таким ISBN
-	existingBook, err := s.repo.GetByISBN(bookCreate.ISBN)
+	existingBook, err := s.repo.GetByISBN(ctx, bookCreate.ISBN)
 	if err == nil && existingBook != nil {
-		return nil, errors.New("книга с таким ISBN уже существует")
+		return nil, ErrDuplicateISBN
 	}
 
 	// Создаем новую книгу
-	book := &model.Book{
-		Title:       bookCreate.Title,
-		Author:      bookCreate.Author,
-		ISBN:        bookCreate.ISBN,
-		Description: bookCreate.Description,
-		Year:        bookCreate.Year,
-		Publisher:   bookCreate.Publisher,
-		Available:   true,
-	}
+	book := newBook(bookCreate)
 
-	if err := s.repo.Create(book); err != nil {
+	if err := s.repo.Create(ctx, book); err != nil {
 		return nil, err
 	}
+	s.cache.invalidate(ctx)
+	s.events.BookCreated()
 
 	return book, nil
 }
 
 // GetBookByID получает книгу по ID
-func (s *BookService) GetBookByID(id uint) (*model.Book, error) {
-	return s.repo.GetByID(id)
+func (s *BookService) GetBookByID(ctx context.Context, id uint) (_ *model.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.GetBookByID")
+	defer func() { endSpan(span, err) }()
+	book, err := cachedRead(ctx, s.cache, fmt.Sprintf("book:%d", id), func(ctx context.Context) (*model.Book, error) {
+		return s.repo.GetByID(ctx, id)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBookNotFound
+	}
+	return book, err
+}
+
+// GetBooksByIDs получает книги с указанными ID в порядке их перечисления.
+// Если какая-либо книга не найдена, возвращается ErrBookNotFound.
+func (s *BookService) GetBooksByIDs(ctx context.Context, ids []uint) (_ []model.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.GetBooksByIDs")
+	defer func() { endSpan(span, err) }()
+	found, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]model.Book, len(found))
+	for _, book := range found {
+		byID[book.ID] = book
+	}
+
+	books := make([]model.Book, 0, len(ids))
+	for _, id := range ids {
+		book, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", ErrBookNotFound, id)
+		}
+		books = append(books, book)
+	}
+	return books, nil
 }
 
 // GetAllBooks получает список всех книг с пагинацией
-func (s *BookService) GetAllBooks(page, pageSize int) ([]model.Book, error) {
+func (s *BookService) GetAllBooks(ctx context.Context, page, pageSize int) (_ []model.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.GetAllBooks")
+	defer func() { endSpan(span, err) }()
 	if page < 1 {
 		page = 1
 	}
 	if pageSize < 1 {
 		pageSize = 10
 	}
-	return s.repo.GetAll(page, pageSize)
+	return cachedRead(ctx, s.cache, fmt.Sprintf("list:%d:%d", page, pageSize), func(ctx context.Context) ([]model.Book, error) {
+		return s.repo.GetAll(ctx, page, pageSize)
+	})
 }
 
 // UpdateBook обновляет информацию о книге
-func (s *BookService) UpdateBook(id uint, bookUpdate *model.BookCreate) (*model.Book, error) {
-	book, err := s.repo.GetByID(id)
+func (s *BookService) UpdateBook(ctx context.Context, id uint, bookUpdate *model.BookCreate) (_ *model.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.UpdateBook")
+	defer func() { endSpan(span, err) }()
+	if err := authorize(ctx, model.PermissionWriteBooks); err != nil {
+		return nil, err
+	}
+	if err := validateBookCreate(bookUpdate); err != nil {
+		return nil, err
+	}
+
+	book, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBookNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	// Проверяем, не пытаемся ли мы обновить ISBN на уже существующий
 	if book.ISBN != bookUpdate.ISBN {
-		existingBook, err := s.repo.GetByISBN(bookUpdate.ISBN)
+		existingBook, err := s.repo.GetByISBN(ctx, bookUpdate.ISBN)
 		if err == nil && existingBook != nil && existingBook.ID != id {
-			return nil, errors.New("книга с таким ISBN уже существует")
+			return nil, ErrDuplicateISBN
 		}
 	}
 
-	book.Title = bookUpdate.Title
-	book.Author = bookUpdate.Author
-	book.ISBN = bookUpdate.ISBN
-	book.Description = bookUpdate.Description
-	book.Year = bookUpdate.Year
-	book.Publisher = bookUpdate.Publisher
+	applyBookUpdate(book, bookUpdate)
 
-	if err := s.repo.Update(book); err != nil {
+	if err := s.repo.Update(ctx, book); err != nil {
 		return nil, err
 	}
+	s.cache.invalidate(ctx)
 
 	return book, nil
 }
 
 // DeleteBook удаляет книгу
-func (s *BookService) DeleteBook(id uint) error {
-	return s.repo.Delete(id)
+func (s *BookService) DeleteBook(ctx context.Context, id uint) (err error) {
+	ctx, span := startSpan(ctx, "BookService.DeleteBook")
+	defer func() { endSpan(span, err) }()
+	if err := authorize(ctx, model.PermissionDeleteBooks); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.cache.invalidate(ctx)
+	return nil
 }
 
 // SearchBooks ищет книги по названию или автору
-func (s *BookService) SearchBooks(query string) ([]model.Book, error) {
-	return s.repo.Search(query)
+func (s *BookService) SearchBooks(ctx context.Context, query string) (_ []model.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.SearchBooks")
+	defer func() { endSpan(span, err) }()
+	books, err := cachedRead(ctx, s.cache, "search:"+query, func(ctx context.Context) ([]model.Book, error) {
+		return s.repo.Search(ctx, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.events.SearchCompleted(len(books))
+	return books, nil
 }
 
 // ToggleBookAvailability изменяет статус доступности книги
-func (s *BookService) ToggleBookAvailability(id uint) (*model.Book, error) {
-	book, err := s.repo.GetByID(id)
+func (s *BookService) ToggleBookAvailability(ctx context.Context, id uint) (_ *model.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.ToggleBookAvailability")
+	defer func() { endSpan(span, err) }()
+	if err := authorize(ctx, model.PermissionWriteBooks); err != nil {
+		return nil, err
+	}
+	book, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	book.Available = !book.Available
-	if err := s.repo.Update(book); err != nil {
+	if err := s.repo.Update(ctx, book); err != nil {
 		return nil, err
 	}
+	s.cache.invalidate(ctx)
+	if book.Available {
+		s.events.BookReturned()
+	} else {
+		s.events.BookCheckedOut()
+	}
 
 	return book, nil
-} 
\ No newline at end of file
+}
+
+// ListChangedBooks получает страницу книг в порядке изменения, начиная после
+// книги afterID, измененной в afterUpdatedAt
+func (s *BookService) ListChangedBooks(ctx context.Context, filter *model.BookFilter, afterUpdatedAt time.Time, afterID uint, limit int) (_ []model.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.ListChangedBooks")
+	defer func() { endSpan(span, err) }()
+	return s.repo.ListByUpdatedAt(ctx, filter, afterUpdatedAt, afterID, limit)
+}
+
+// CountBooks возвращает количество книг, удовлетворяющих фильтру
+func (s *BookService) CountBooks(ctx context.Context, filter *model.BookFilter) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "BookService.CountBooks")
+	defer func() { endSpan(span, err) }()
+	return s.repo.Count(ctx, filter)
+}
+
+// EarliestUpdate возвращает самую раннюю дату изменения книги в каталоге
+func (s *BookService) EarliestUpdate(ctx context.Context) (_ time.Time, err error) {
+	ctx, span := startSpan(ctx, "BookService.EarliestUpdate")
+	defer func() { endSpan(span, err) }()
+	return s.repo.EarliestUpdatedAt(ctx)
+}
+
+// newBook создает модель новой доступной книги из входных данных
+func newBook(bookCreate *model.BookCreate) *model.Book {
+	return &model.Book{
+		Title:       bookCreate.Title,
+		Author:      bookCreate.Author,
+		ISBN:        bookCreate.ISBN,
+		Description: bookCreate.Description,
+		Year:        bookCreate.Year,
+		Publisher:   bookCreate.Publisher,
+		Available:   true,
+	}
+}
+
+// applyBookUpdate переносит входные данные в существующую книгу
+func applyBookUpdate(book *model.Book, bookUpdate *model.BookCreate) {
+	book.Title = bookUpdate.Title
+	book.Author = bookUpdate.Author
+	book.ISBN = bookUpdate.ISBN
+	book.Description = bookUpdate.Description
+	book.Year = bookUpdate.Year
+	book.Publisher = bookUpdate.Publisher
+}
+
+// validateBookCreate проверяет обязательные поля книги.
+// Повторяет правила binding из model.BookCreate, чтобы они применялись
+// и к данным, не проходящим через привязку Gin.
+func validateBookCreate(bookCreate *model.BookCreate) error {
+	switch {
+	case bookCreate == nil:
+		return fmt.Errorf("%w: не указаны данные книги", ErrInvalidBook)
+	case strings.TrimSpace(bookCreate.Title) == "":
+		return fmt.Errorf("%w: не указано название книги", ErrInvalidBook)
+	case strings.TrimSpace(bookCreate.Author) == "":
+		return fmt.Errorf("%w: не указан автор книги", ErrInvalidBook)
+	case strings.TrimSpace(bookCreate.ISBN) == "":
+		return fmt.Errorf("%w: не указан ISBN книги", ErrInvalidBook)
+	case bookCreate.Year == 0:
+		return fmt.Errorf("%w: не указан год издания книги", ErrInvalidBook)
+	}
+	return nil
+}
@@ -1,12 +1,20 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
 )
 
 // MockBookRepository - мок для репозитория книг
@@ -14,56 +22,87 @@ type MockBookRepository struct {
 	mock.Mock
 }
 
-func (m *MockBookRepository) Create(book *model.Book) error {
-	args := m.Called(book)
+func (m *MockBookRepository) Create(ctx context.Context, book *model.Book) error {
+	args := m.Called(ctx, book)
 	return args.Error(0)
 }
 
-func (m *MockBookRepository) GetByID(id uint) (*model.Book, error) {
-	args := m.Called(id)
+func (m *MockBookRepository) GetByID(ctx context.Context, id uint) (*model.Book, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.Book), args.Error(1)
 }
 
-func (m *MockBookRepository) GetAll(page, pageSize int) ([]model.Book, error) {
-	args := m.Called(page, pageSize)
+func (m *MockBookRepository) GetAll(ctx context.Context, page, pageSize int) ([]model.Book, error) {
+	args := m.Called(ctx, page, pageSize)
 	return args.Get(0).([]model.Book), args.Error(1)
 }
 
-func (m *MockBookRepository) Update(book *model.Book) error {
-	args := m.Called(book)
+func (m *MockBookRepository) Update(ctx context.Context, book *model.Book) error {
+	args := m.Called(ctx, book)
 	return args.Error(0)
 }
 
-func (m *MockBookRepository) Delete(id uint) error {
-	args := m.Called(id)
+func (m *MockBookRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockBookRepository) GetByISBN(isbn string) (*model.Book, error) {
-	args := m.Called(isbn)
+func (m *MockBookRepository) GetByISBN(ctx context.Context, isbn string) (*model.Book, error) {
+	args := m.Called(ctx, isbn)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.Book), args.Error(1)
 }
 
-func (m *MockBookRepository) Search(query string) ([]model.Book, error) {
-	args := m.Called(query)
+func (m *MockBookRepository) GetByISBNs(ctx context.Context, isbns []string) ([]model.Book, error) {
+	args := m.Called(ctx, isbns)
+	return args.Get(0).([]model.Book), args.Error(1)
+}
+
+func (m *MockBookRepository) GetByIDs(ctx context.Context, ids []uint) ([]model.Book, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]model.Book), args.Error(1)
+}
+
+func (m *MockBookRepository) Transaction(ctx context.Context, fn func(store repository.BookStore) error) error {
+	m.Called(ctx)
+	return fn(m)
+}
+
+func (m *MockBookRepository) FindInBatches(ctx context.Context, filter *model.BookFilter, batchSize int, fn func(books []model.Book) error) error {
+	args := m.Called(ctx, filter, batchSize)
+	return args.Error(0)
+}
+
+func (m *MockBookRepository) ListByUpdatedAt(ctx context.Context, filter *model.BookFilter, afterUpdatedAt time.Time, afterID uint, limit int) ([]model.Book, error) {
+	args := m.Called(ctx, filter, afterUpdatedAt, afterID, limit)
+	return args.Get(0).([]model.Book), args.Error(1)
+}
+
+func (m *MockBookRepository) Count(ctx context.Context, filter *model.BookFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBookRepository) EarliestUpdatedAt(ctx context.Context) (time.Time, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockBookRepository) Search(ctx context.Context, query string) ([]model.Book, error) {
+	args := m.Called(ctx, query)
 	return args.Get(0).([]model.Book), args.Error(1)
 }
 
 func TestCreateBook(t *testing.T) {
-	// Arrange
-	mockRepo := new(MockBookRepository)
-	service := NewBookService(mockRepo)
-	
 	testCases := []struct {
 		name          string
 		input         *model.BookCreate
-		setupMock     func()
+		setupMock     func(mockRepo *MockBookRepository)
 		expectedError bool
 	}{
 		{
@@ -76,9 +115,9 @@ func TestCreateBook(t *testing.T) {
 				Year:        1869,
 				Publisher:   "Русский вестник",
 			},
-			setupMock: func() {
-				mockRepo.On("GetByISBN", "1234567890").Return(nil, errors.New("not found"))
-				mockRepo.On("Create", mock.AnythingOfType("*model.Book")).Return(nil)
+			setupMock: func(mockRepo *MockBookRepository) {
+				mockRepo.On("GetByISBN", mock.Anything, "1234567890").Return(nil, errors.New("not found"))
+				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*model.Book")).Return(nil)
 			},
 			expectedError: false,
 		},
@@ -92,9 +131,9 @@ func TestCreateBook(t *testing.T) {
 				Year:        2024,
 				Publisher:   "Издательство",
 			},
-			setupMock: func() {
+			setupMock: func(mockRepo *MockBookRepository) {
 				existingBook := &model.Book{ID: 1, ISBN: "1234567890"}
-				mockRepo.On("GetByISBN", "1234567890").Return(existingBook, nil)
+				mockRepo.On("GetByISBN", mock.Anything, "1234567890").Return(existingBook, nil)
 			},
 			expectedError: true,
 		},
@@ -103,10 +142,12 @@ func TestCreateBook(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Arrange
-			tc.setupMock()
+			mockRepo := new(MockBookRepository)
+			tc.setupMock(mockRepo)
+			service := NewBookService(mockRepo)
 
 			// Act
-			book, err := service.CreateBook(tc.input)
+			book, err := service.CreateBook(adminContext(), tc.input)
 
 			// Assert
 			if tc.expectedError {
@@ -124,50 +165,57 @@ func TestCreateBook(t *testing.T) {
 }
 
 func TestGetBookByID(t *testing.T) {
-	// Arrange
-	mockRepo := new(MockBookRepository)
-	service := NewBookService(mockRepo)
+	storageErr := errors.New("connection refused")
 
 	testCases := []struct {
 		name          string
 		bookID        uint
-		setupMock     func()
-		expectedError bool
+		setupMock     func(mockRepo *MockBookRepository)
+		expectedError error
 	}{
 		{
 			name:   "Успешное получение книги",
 			bookID: 1,
-			setupMock: func() {
+			setupMock: func(mockRepo *MockBookRepository) {
 				book := &model.Book{
 					ID:     1,
 					Title:  "Тестовая книга",
 					Author: "Тестовый автор",
 				}
-				mockRepo.On("GetByID", uint(1)).Return(book, nil)
+				mockRepo.On("GetByID", mock.Anything, uint(1)).Return(book, nil)
 			},
-			expectedError: false,
 		},
 		{
 			name:   "Книга не найдена",
 			bookID: 999,
-			setupMock: func() {
-				mockRepo.On("GetByID", uint(999)).Return(nil, errors.New("not found"))
+			setupMock: func(mockRepo *MockBookRepository) {
+				mockRepo.On("GetByID", mock.Anything, uint(999)).Return(nil, gorm.ErrRecordNotFound)
 			},
-			expectedError: true,
+			expectedError: ErrBookNotFound,
+		},
+		{
+			name:   "Ошибка хранилища не считается отсутствием книги",
+			bookID: 1,
+			setupMock: func(mockRepo *MockBookRepository) {
+				mockRepo.On("GetByID", mock.Anything, uint(1)).Return(nil, storageErr)
+			},
+			expectedError: storageErr,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Arrange
-			tc.setupMock()
+			mockRepo := new(MockBookRepository)
+			tc.setupMock(mockRepo)
+			service := NewBookService(mockRepo)
 
 			// Act
-			book, err := service.GetBookByID(tc.bookID)
+			book, err := service.GetBookByID(context.Background(), tc.bookID)
 
 			// Assert
-			if tc.expectedError {
-				assert.Error(t, err)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
 				assert.Nil(t, book)
 			} else {
 				assert.NoError(t, err)
@@ -179,26 +227,22 @@ func TestGetBookByID(t *testing.T) {
 }
 
 func TestSearchBooks(t *testing.T) {
-	// Arrange
-	mockRepo := new(MockBookRepository)
-	service := NewBookService(mockRepo)
-
 	testCases := []struct {
-		name           string
-		searchQuery    string
-		setupMock      func()
-		expectedCount  int
-		expectedError  bool
+		name          string
+		searchQuery   string
+		setupMock     func(mockRepo *MockBookRepository)
+		expectedCount int
+		expectedError bool
 	}{
 		{
 			name:        "Успешный поиск книг",
 			searchQuery: "Толстой",
-			setupMock: func() {
+			setupMock: func(mockRepo *MockBookRepository) {
 				books := []model.Book{
 					{ID: 1, Title: "Война и мир", Author: "Лев Толстой"},
 					{ID: 2, Title: "Анна Каренина", Author: "Лев Толстой"},
 				}
-				mockRepo.On("Search", "Толстой").Return(books, nil)
+				mockRepo.On("Search", mock.Anything, "Толстой").Return(books, nil)
 			},
 			expectedCount: 2,
 			expectedError: false,
@@ -206,8 +250,8 @@ func TestSearchBooks(t *testing.T) {
 		{
 			name:        "Поиск без результатов",
 			searchQuery: "Несуществующий автор",
-			setupMock: func() {
-				mockRepo.On("Search", "Несуществующий автор").Return([]model.Book{}, nil)
+			setupMock: func(mockRepo *MockBookRepository) {
+				mockRepo.On("Search", mock.Anything, "Несуществующий автор").Return([]model.Book{}, nil)
 			},
 			expectedCount: 0,
 			expectedError: false,
@@ -217,10 +261,12 @@ func TestSearchBooks(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Arrange
-			tc.setupMock()
+			mockRepo := new(MockBookRepository)
+			tc.setupMock(mockRepo)
+			service := NewBookService(mockRepo)
 
 			// Act
-			books, err := service.SearchBooks(tc.searchQuery)
+			books, err := service.SearchBooks(context.Background(), tc.searchQuery)
 
 			// Assert
 			if tc.expectedError {
@@ -231,4 +277,130 @@ func TestSearchBooks(t *testing.T) {
 			}
 		})
 	}
-} 
\ No newline at end of file
+}
+
+func TestBookServiceWithMemoryStore(t *testing.T) {
+	// Arrange
+	ctx := adminContext()
+	service := NewBookService(repository.NewMemoryBookStore())
+	first, err := service.CreateBook(ctx, &model.BookCreate{Title: "Война и мир", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869})
+	assert.NoError(t, err)
+	second, err := service.CreateBook(ctx, &model.BookCreate{Title: "Анна Каренина", Author: "Лев Толстой", ISBN: "2222222222", Year: 1877})
+	assert.NoError(t, err)
+
+	// Act
+	_, duplicateErr := service.CreateBook(ctx, &model.BookCreate{Title: "Дубликат", Author: "Автор", ISBN: "1111111111", Year: 2024})
+	_, updateErr := service.UpdateBook(ctx, second.ID, &model.BookCreate{Title: "Анна Каренина", Author: "Лев Толстой", ISBN: "1111111111", Year: 1877})
+	toggled, toggleErr := service.ToggleBookAvailability(ctx, first.ID)
+	books, searchErr := service.SearchBooks(ctx, "каренина")
+
+	// Assert
+	assert.ErrorIs(t, duplicateErr, ErrDuplicateISBN)
+	assert.ErrorIs(t, updateErr, ErrDuplicateISBN)
+	assert.NoError(t, toggleErr)
+	assert.False(t, toggled.Available)
+	assert.NoError(t, searchErr)
+	assert.Len(t, books, 1)
+}
+
+// recordedEvents подсчитывает события каталога
+type recordedEvents struct {
+	created, checkedOut, returned, emptySearches int
+}
+
+func (e *recordedEvents) BookCreated()    { e.created++ }
+func (e *recordedEvents) BookCheckedOut() { e.checkedOut++ }
+func (e *recordedEvents) BookReturned()   { e.returned++ }
+func (e *recordedEvents) SearchCompleted(results int) {
+	if results == 0 {
+		e.emptySearches++
+	}
+}
+
+func TestBookEvents(t *testing.T) {
+	// Arrange
+	ctx := adminContext()
+	events := &recordedEvents{}
+	service := NewBookService(repository.NewMemoryBookStore())
+	service.SetEvents(events)
+
+	// Act
+	book, err := service.CreateBook(ctx, &model.BookCreate{Title: "Война и мир", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869})
+	assert.NoError(t, err)
+	_, err = service.ToggleBookAvailability(ctx, book.ID)
+	assert.NoError(t, err)
+	_, err = service.ToggleBookAvailability(ctx, book.ID)
+	assert.NoError(t, err)
+	_, err = service.SearchBooks(ctx, "Достоевский")
+	assert.NoError(t, err)
+	_, err = service.BulkApply(ctx, &model.BulkRequest{Operations: []model.BulkOperation{
+		{Op: model.BulkOpCreate, Book: &model.BookCreate{Title: "Анна Каренина", Author: "Лев Толстой", ISBN: "2222222222", Year: 1877}},
+		{Op: model.BulkOpCreate, Book: &model.BookCreate{Title: "Дубликат", Author: "Автор", ISBN: "1111111111", Year: 2024}},
+	}})
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, &recordedEvents{created: 1, checkedOut: 1, returned: 1, emptySearches: 1}, events, "откаченный пакет не учитывается")
+}
+
+func TestBookServiceTracing(t *testing.T) {
+	testCases := []struct {
+		name         string
+		call         func(ctx context.Context, s *BookService) error
+		expectedName string
+		expectError  bool
+	}{
+		{
+			name: "Успешный вызов",
+			call: func(ctx context.Context, s *BookService) error {
+				_, err := s.CreateBook(ctx, &model.BookCreate{Title: "Война и мир", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869})
+				return err
+			},
+			expectedName: "BookService.CreateBook",
+		},
+		{
+			name: "Ненайденная книга не считается ошибкой",
+			call: func(ctx context.Context, s *BookService) error {
+				_, err := s.GetBookByID(ctx, 42)
+				return err
+			},
+			expectedName: "BookService.GetBookByID",
+		},
+		{
+			name: "Ошибка хранилища",
+			call: func(ctx context.Context, s *BookService) error {
+				canceled, cancel := context.WithCancel(ctx)
+				cancel()
+				_, err := s.SearchBooks(canceled, "мир")
+				return err
+			},
+			expectedName: "BookService.SearchBooks",
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			recorder := tracetest.NewSpanRecorder()
+			previous := otel.GetTracerProvider()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+			t.Cleanup(func() { otel.SetTracerProvider(previous) })
+			service := NewBookService(repository.NewMemoryBookStore())
+
+			// Act
+			err := tc.call(adminContext(), service)
+
+			// Assert
+			spans := recorder.Ended()
+			assert.Len(t, spans, 1)
+			assert.Equal(t, tc.expectedName, spans[0].Name())
+			if tc.expectError {
+				assert.Error(t, err)
+				assert.Equal(t, codes.Error, spans[0].Status().Code)
+			} else {
+				assert.Equal(t, codes.Unset, spans[0].Status().Code)
+			}
+		})
+	}
+}
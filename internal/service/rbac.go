@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+)
+
+// ErrForbidden возвращается, если у пользователя нет права на действие
+var ErrForbidden = errors.New("недостаточно прав")
+
+// authorize проверяет, что пользователь запроса аутентифицирован и имеет право.
+// Пользователя помещает в контекст запроса middleware.Authenticate.
+func authorize(ctx context.Context, permission model.Permission) error {
+	if !model.PrincipalFromContext(ctx).Can(permission) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// bulkPermission возвращает право, необходимое для операции пакета
+func bulkPermission(op string) model.Permission {
+	if op == model.BulkOpDelete {
+		return model.PermissionDeleteBooks
+	}
+	return model.PermissionWriteBooks
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkApplyPermissions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		actor *model.Principal
+		op    string
+	}{
+		{name: "Анонимный пользователь", actor: nil, op: model.BulkOpCreate},
+		{name: "Читатель создает книгу", actor: &model.Principal{UserID: 1, Role: model.RolePatron}, op: model.BulkOpCreate},
+		{name: "Библиотекарь удаляет книгу", actor: &model.Principal{UserID: 2, Role: model.RoleLibrarian}, op: model.BulkOpDelete},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			s := &BookService{}
+			req := &model.BulkRequest{Operations: []model.BulkOperation{{Op: tc.op, ID: 1}}}
+
+			// Act
+			result, err := s.BulkApply(model.ContextWithPrincipal(context.Background(), tc.actor), req)
+
+			// Assert
+			assert.ErrorIs(t, err, ErrForbidden)
+			assert.Nil(t, result)
+		})
+	}
+}
+
+func TestUserServicePermissions(t *testing.T) {
+	admin := adminContext()
+	librarian := model.ContextWithPrincipal(context.Background(), &model.Principal{UserID: 2, Role: model.RoleLibrarian})
+
+	testCases := []struct {
+		name          string
+		act           func(s *UserService) error
+		expectedError error
+	}{
+		{
+			name: "Библиотекарь не видит пользователей",
+			act: func(s *UserService) error {
+				_, err := s.ListUsers(librarian)
+				return err
+			},
+			expectedError: ErrForbidden,
+		},
+		{
+			name: "Библиотекарь не создает пользователей",
+			act: func(s *UserService) error {
+				_, err := s.CreateUser(librarian, &model.UserCreate{Username: "user", Password: "password123"})
+				return err
+			},
+			expectedError: ErrForbidden,
+		},
+		{
+			name: "Неизвестная роль",
+			act: func(s *UserService) error {
+				_, err := s.SetRole(admin, 5, "superuser")
+				return err
+			},
+			expectedError: ErrInvalidRole,
+		},
+		{
+			name: "Администратор не меняет свою роль",
+			act: func(s *UserService) error {
+				_, err := s.SetRole(admin, 1, model.RolePatron)
+				return err
+			},
+			expectedError: ErrSelfModification,
+		},
+		{
+			name:          "Администратор не удаляет себя",
+			act:           func(s *UserService) error { return s.DeleteUser(admin, 1) },
+			expectedError: ErrSelfModification,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			s := NewUserService(nil)
+
+			// Act
+			err := tc.act(s)
+
+			// Assert
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+// adminContext возвращает контекст запроса администратора
+func adminContext() context.Context {
+	return model.ContextWithPrincipal(context.Background(), &model.Principal{UserID: 1, Role: model.RoleAdmin})
+}
+
+func TestBookServicePermissions(t *testing.T) {
+	patron := model.ContextWithPrincipal(context.Background(), &model.Principal{UserID: 3, Role: model.RolePatron})
+	librarian := model.ContextWithPrincipal(context.Background(), &model.Principal{UserID: 2, Role: model.RoleLibrarian})
+	book := &model.BookCreate{Title: "Война и мир", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869}
+	csv := "title,author,isbn,year\nАнна Каренина,Лев Толстой,2222222222,1877\n"
+
+	testCases := []struct {
+		name string
+		act  func(books *BookService, revisions *RevisionService) error
+	}{
+		{name: "Анонимный пользователь создает книгу", act: func(books *BookService, _ *RevisionService) error {
+			_, err := books.CreateBook(context.Background(), book)
+			return err
+		}},
+		{name: "Читатель создает книгу", act: func(books *BookService, _ *RevisionService) error {
+			_, err := books.CreateBook(patron, book)
+			return err
+		}},
+		{name: "Читатель обновляет книгу", act: func(books *BookService, _ *RevisionService) error {
+			_, err := books.UpdateBook(patron, 1, book)
+			return err
+		}},
+		{name: "Читатель меняет доступность", act: func(books *BookService, _ *RevisionService) error {
+			_, err := books.ToggleBookAvailability(patron, 1)
+			return err
+		}},
+		{name: "Читатель импортирует книги", act: func(books *BookService, _ *RevisionService) error {
+			_, err := books.ImportCSV(patron, strings.NewReader(csv), CSVImportOptions{})
+			return err
+		}},
+		{name: "Читатель откатывает книгу", act: func(_ *BookService, revisions *RevisionService) error {
+			_, err := revisions.RevertBook(patron, 1, 1)
+			return err
+		}},
+		{name: "Читатель удаляет книгу", act: func(books *BookService, _ *RevisionService) error {
+			return books.DeleteBook(patron, 1)
+		}},
+		{name: "Библиотекарь удаляет книгу", act: func(books *BookService, _ *RevisionService) error {
+			return books.DeleteBook(librarian, 1)
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange: без хранилища любое обращение к нему завершилось бы паникой
+			books := &BookService{}
+			revisions := &RevisionService{books: books}
+
+			// Act
+			err := tc.act(books, revisions)
+
+			// Assert
+			assert.ErrorIs(t, err, ErrForbidden)
+		})
+	}
+}
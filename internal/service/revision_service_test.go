@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRevisionService создает сервисы с книгой, у которой две ревизии:
+// исходная и с измененным названием
+func newRevisionService(t *testing.T) (*RevisionService, *model.Book) {
+	t.Helper()
+	store := repository.NewMemoryBookStore()
+	books := NewBookService(store)
+	book, err := books.CreateBook(adminContext(), &model.BookCreate{Title: "Война и мир", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869})
+	require.NoError(t, err)
+	book, err = books.UpdateBook(adminContext(), book.ID, &model.BookCreate{Title: "Война и мiръ", Author: "Лев Толстой", ISBN: "1111111111", Year: 1869})
+	require.NoError(t, err)
+	return NewRevisionService(store, books), book
+}
+
+func TestGetRevisionsOfUnknownBook(t *testing.T) {
+	// Arrange
+	revisions, _ := newRevisionService(t)
+
+	// Act
+	history, err := revisions.GetRevisions(context.Background(), 42)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrBookNotFound)
+	assert.Nil(t, history)
+}
+
+func TestGetRevision(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name          string
+		ctx           context.Context
+		revision      int
+		expectedTitle string
+		expectedError error
+	}{
+		{name: "Существующая ревизия", ctx: context.Background(), revision: 1, expectedTitle: "Война и мир"},
+		{name: "Неизвестная ревизия", ctx: context.Background(), revision: 3, expectedError: ErrRevisionNotFound},
+		{name: "Ошибка хранилища не считается отсутствием ревизии", ctx: canceled, revision: 1, expectedError: context.Canceled},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			revisions, book := newRevisionService(t)
+
+			// Act
+			rev, err := revisions.GetRevision(tc.ctx, book.ID, tc.revision)
+
+			// Assert
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTitle, rev.Title)
+		})
+	}
+}
+
+func TestRevertBook(t *testing.T) {
+	// Arrange
+	revisions, book := newRevisionService(t)
+	_, err := revisions.books.ToggleBookAvailability(adminContext(), book.ID)
+	require.NoError(t, err)
+
+	// Act
+	reverted, err := revisions.RevertBook(adminContext(), book.ID, 1)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Война и мир", reverted.Title)
+	assert.False(t, reverted.Available, "доступность не откатывается")
+	history, err := revisions.GetRevisions(context.Background(), book.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 4, "откат сохраняется новой ревизией")
+	assert.Equal(t, 4, history[3].Revision)
+	assert.Equal(t, "Война и мир", history[3].Title)
+	assert.Equal(t, "Война и мiръ", history[1].Title, "прежние ревизии не меняются")
+}
+
+func TestRevertBookToUnknownRevision(t *testing.T) {
+	// Arrange
+	revisions, book := newRevisionService(t)
+
+	// Act
+	_, err := revisions.RevertBook(adminContext(), book.ID, 5)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+	history, err := revisions.GetRevisions(context.Background(), book.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+}
+
+func TestRevertBookErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		arrange       func(t *testing.T, books *BookService, bookID uint)
+		expectedError error
+	}{
+		{
+			name: "Книга удалена",
+			arrange: func(t *testing.T, books *BookService, bookID uint) {
+				require.NoError(t, books.DeleteBook(adminContext(), bookID))
+			},
+			expectedError: ErrBookNotFound,
+		},
+		{
+			name: "ISBN ревизии занят другой книгой",
+			arrange: func(t *testing.T, books *BookService, bookID uint) {
+				_, err := books.UpdateBook(adminContext(), bookID, &model.BookCreate{Title: "Война и мiръ", Author: "Лев Толстой", ISBN: "2222222222", Year: 1869})
+				require.NoError(t, err)
+				_, err = books.CreateBook(adminContext(), &model.BookCreate{Title: "Анна Каренина", Author: "Лев Толстой", ISBN: "1111111111", Year: 1877})
+				require.NoError(t, err)
+			},
+			expectedError: ErrDuplicateISBN,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			revisions, book := newRevisionService(t)
+			tc.arrange(t, revisions.books, book.ID)
+
+			// Act
+			reverted, err := revisions.RevertBook(adminContext(), book.ID, 1)
+
+			// Assert
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Nil(t, reverted)
+		})
+	}
+}
+
+func TestDiffRevisions(t *testing.T) {
+	testCases := []struct {
+		name            string
+		from            int
+		to              int
+		expectedChanges []model.FieldChange
+		expectedError   error
+	}{
+		{name: "От старой ревизии к новой", from: 1, to: 2, expectedChanges: []model.FieldChange{
+			{Field: "title", From: "Война и мир", To: "Война и мiръ"},
+		}},
+		{name: "От новой ревизии к старой", from: 2, to: 1, expectedChanges: []model.FieldChange{
+			{Field: "title", From: "Война и мiръ", To: "Война и мир"},
+		}},
+		{name: "Ревизия с самой собой", from: 2, to: 2, expectedChanges: []model.FieldChange{}},
+		{name: "Неизвестная исходная ревизия", from: 7, to: 2, expectedError: ErrRevisionNotFound},
+		{name: "Неизвестная конечная ревизия", from: 1, to: 7, expectedError: ErrRevisionNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			revisions, book := newRevisionService(t)
+
+			// Act
+			diff, err := revisions.DiffRevisions(context.Background(), book.ID, tc.from, tc.to)
+
+			// Assert
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, diff)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.from, diff.From)
+			assert.Equal(t, tc.to, diff.To)
+			assert.Equal(t, tc.expectedChanges, diff.Changes)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const tracerName = "github.com/krawwwwy/book-library-api/internal/service"
+
+// startSpan начинает спан метода сервиса у текущего глобального провайдера
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name)
+}
+
+// endSpan завершает спан и отмечает его ошибкой, если метод ее вернул.
+// Ненайденная книга, повтор ISBN и неверные данные ошибками трассы не считаются,
+// это обычные ответы клиенту.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !isClientError(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func isClientError(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, ErrBookNotFound) || errors.Is(err, ErrDuplicateISBN) || errors.Is(err, ErrInvalidBook)
+}
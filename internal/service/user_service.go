@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/krawwwwy/book-library-api/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUserNotFound возвращается, если пользователь не найден
+	ErrUserNotFound = errors.New("пользователь не найден")
+	// ErrDuplicateUsername возвращается, если имя пользователя уже занято
+	ErrDuplicateUsername = errors.New("пользователь с таким именем уже существует")
+	// ErrInvalidUsername возвращается при пустом имени пользователя
+	ErrInvalidUsername = errors.New("имя пользователя не может быть пустым")
+	// ErrInvalidRole возвращается при неизвестной роли
+	ErrInvalidRole = errors.New("неизвестная роль")
+	// ErrSelfModification возвращается при попытке изменить роль или удалить собственную учетную запись.
+	// Так администратор не может случайно лишить систему последнего администратора.
+	ErrSelfModification = errors.New("нельзя изменить роль или удалить собственную учетную запись")
+)
+
+// UserService представляет сервис управления пользователями. Все методы
+// требуют права PermissionManageUsers.
+type UserService struct {
+	repo *repository.UserRepository
+}
+
+// NewUserService создает новый экземпляр UserService
+func NewUserService(repo *repository.UserRepository) *UserService {
+	return &UserService{repo: repo}
+}
+
+// ListUsers получает всех пользователей
+func (s *UserService) ListUsers(ctx context.Context) ([]model.User, error) {
+	if err := authorize(ctx, model.PermissionManageUsers); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAll(ctx)
+}
+
+// CreateUser создает пользователя. Если роль не указана, назначается RolePatron.
+func (s *UserService) CreateUser(ctx context.Context, userCreate *model.UserCreate) (*model.User, error) {
+	if err := authorize(ctx, model.PermissionManageUsers); err != nil {
+		return nil, err
+	}
+
+	role := userCreate.Role
+	if role == "" {
+		role = model.RolePatron
+	}
+	if !model.ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	username := strings.TrimSpace(userCreate.Username)
+	if username == "" {
+		return nil, ErrInvalidUsername
+	}
+	if _, err := s.repo.GetByUsername(ctx, username); err == nil {
+		return nil, ErrDuplicateUsername
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	hash, err := HashPassword(userCreate.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{Username: username, PasswordHash: hash, Role: role}
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetRole изменяет роль пользователя. Новая роль попадает в токены
+// при следующем обновлении пары токенов.
+func (s *UserService) SetRole(ctx context.Context, id uint, role string) (*model.User, error) {
+	if err := authorize(ctx, model.PermissionManageUsers); err != nil {
+		return nil, err
+	}
+	if !model.ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if model.PrincipalFromContext(ctx).UserID == id {
+		return nil, ErrSelfModification
+	}
+
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateRole(ctx, id, role); err != nil {
+		return nil, err
+	}
+	user.Role = role
+	return user, nil
+}
+
+// DeleteUser удаляет пользователя и отзывает его refresh-токены
+func (s *UserService) DeleteUser(ctx context.Context, id uint) error {
+	if err := authorize(ctx, model.PermissionManageUsers); err != nil {
+		return err
+	}
+	if model.PrincipalFromContext(ctx).UserID == id {
+		return ErrSelfModification
+	}
+
+	if _, err := s.getUser(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *UserService) getUser(ctx context.Context, id uint) (*model.User, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,198 @@
+// Package sso реализует вход через внешний провайдер OpenID Connect
+// по схеме authorization code с PKCE
+package sso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"golang.org/x/oauth2"
+)
+
+// loginTTL ограничивает время между переходом к провайдеру и возвратом на callback
+const loginTTL = 10 * time.Minute
+
+// maxPendingLogins ограничивает число незавершенных входов в памяти, чтобы
+// запросы на начало входа без возврата на callback не исчерпали память процесса
+const maxPendingLogins = 10000
+
+var (
+	// ErrUnknownState возвращается при неизвестном, повторном или просроченном state
+	ErrUnknownState = errors.New("неизвестный или просроченный параметр state")
+	// ErrInvalidIDToken возвращается, если провайдер не вернул действительный ID-токен
+	ErrInvalidIDToken = errors.New("недействительный ID-токен")
+	// ErrTooManyLogins возвращается, если достигнут предел незавершенных входов
+	ErrTooManyLogins = errors.New("слишком много незавершенных входов, повторите позже")
+)
+
+// pendingLogin хранит секреты начатого входа до возврата пользователя от провайдера
+type pendingLogin struct {
+	verifier  string
+	nonce     string
+	expiresAt time.Time
+}
+
+// Client выполняет вход через провайдер OpenID Connect. Незавершенные входы
+// хранятся в памяти процесса, поэтому callback должен попасть на тот же экземпляр.
+type Client struct {
+	cfg      config.OIDCConfig
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	now      func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]pendingLogin
+}
+
+// NewClient получает настройки провайдера через discovery и создает клиент
+func NewClient(ctx context.Context, cfg config.OIDCConfig) (*Client, error) {
+	if !model.ValidRole(cfg.DefaultRole) {
+		return nil, fmt.Errorf("неизвестная роль по умолчанию %q", cfg.DefaultRole)
+	}
+	for value, role := range cfg.RoleMapping {
+		if !model.ValidRole(role) {
+			return nil, fmt.Errorf("неизвестная роль %q для %q", role, value)
+		}
+	}
+
+	provider, err := oidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения настроек провайдера OIDC: %w", err)
+	}
+
+	return &Client{
+		cfg: cfg,
+		oauth: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       cfg.Scopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		now:      time.Now,
+		pending:  make(map[string]pendingLogin),
+	}, nil
+}
+
+// AuthCodeURL начинает вход и возвращает адрес страницы входа провайдера
+// вместе со значением state, которое нужно привязать к браузеру пользователя.
+// Если незавершенных входов уже maxPendingLogins, возвращается ErrTooManyLogins.
+func (c *Client) AuthCodeURL() (string, string, error) {
+	state := oauth2.GenerateVerifier()
+	login := pendingLogin{
+		verifier:  oauth2.GenerateVerifier(),
+		nonce:     oauth2.GenerateVerifier(),
+		expiresAt: c.now().Add(loginTTL),
+	}
+
+	c.mu.Lock()
+	c.removeExpired()
+	if len(c.pending) >= maxPendingLogins {
+		c.mu.Unlock()
+		return "", "", ErrTooManyLogins
+	}
+	c.pending[state] = login
+	c.mu.Unlock()
+
+	authURL := c.oauth.AuthCodeURL(state,
+		oauth2.S256ChallengeOption(login.verifier),
+		oidc.Nonce(login.nonce),
+	)
+	return authURL, state, nil
+}
+
+// Exchange завершает вход: обменивает код на токены, проверяет подпись
+// ID-токена по JWKS провайдера и сопоставляет claims локальной роли
+func (c *Client) Exchange(ctx context.Context, state, code string) (*model.ExternalIdentity, error) {
+	c.mu.Lock()
+	login, ok := c.pending[state]
+	delete(c.pending, state)
+	c.mu.Unlock()
+	if !ok || c.now().After(login.expiresAt) {
+		return nil, ErrUnknownState
+	}
+
+	token, err := c.oauth.Exchange(ctx, code, oauth2.VerifierOption(login.verifier))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обмена кода авторизации: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, ErrInvalidIDToken
+	}
+	idToken, err := c.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if idToken.Nonce != login.nonce {
+		return nil, ErrInvalidIDToken
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	email, _ := claims["email"].(string)
+	username, _ := claims[c.cfg.UsernameClaim].(string)
+	if username == "" {
+		username = email
+	}
+	if username == "" {
+		username = idToken.Subject
+	}
+
+	return &model.ExternalIdentity{
+		Issuer:   idToken.Issuer,
+		Subject:  idToken.Subject,
+		Username: username,
+		Email:    email,
+		Role:     c.mapRole(claims[c.cfg.RoleClaim]),
+	}, nil
+}
+
+// mapRole выбирает наибольшую из ролей, сопоставленных значениям claim.
+// Claim может быть строкой или списком строк.
+func (c *Client) mapRole(claim interface{}) string {
+	var values []string
+	switch v := claim.(type) {
+	case string:
+		values = strings.Fields(v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	var roles []string
+	for _, value := range values {
+		if role, ok := c.cfg.RoleMapping[value]; ok {
+			roles = append(roles, role)
+		}
+	}
+	if role := model.HighestRole(roles...); role != "" {
+		return role
+	}
+	return c.cfg.DefaultRole
+}
+
+// removeExpired удаляет просроченные входы. Вызывается под c.mu.
+func (c *Client) removeExpired() {
+	now := c.now()
+	for state, login := range c.pending {
+		if now.After(login.expiresAt) {
+			delete(c.pending, state)
+		}
+	}
+}
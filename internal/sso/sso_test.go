@@ -0,0 +1,292 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/krawwwwy/book-library-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockIdP представляет локальный провайдер OpenID Connect для тестов:
+// discovery, JWKS и token endpoint с проверкой PKCE
+type mockIdP struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+
+	mu    sync.Mutex
+	codes map[string]issuedCode
+}
+
+// issuedCode представляет выданный код авторизации
+type issuedCode struct {
+	challenge string
+	claims    jwt.MapClaims
+	signer    *rsa.PrivateKey
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	idp := &mockIdP{key: key, clientID: "book-library", codes: make(map[string]issuedCode)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, _ *http.Request) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                idp.server.URL,
+		"authorization_endpoint":                idp.server.URL + "/authorize",
+		"token_endpoint":                        idp.server.URL + "/token",
+		"jwks_uri":                              idp.server.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, _ *http.Request) {
+	pub := idp.key.PublicKey
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test-key",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+
+	idp.mu.Lock()
+	issued, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != issued.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, issued.claims)
+	token.Header["kid"] = "test-key"
+	idToken, _ := token.SignedString(issued.signer)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "idp-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// authorize имитирует вход пользователя на странице провайдера и возвращает state и код
+func (idp *mockIdP) authorize(t *testing.T, authURL string, claims jwt.MapClaims, signer *rsa.PrivateKey) (string, string) {
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := u.Query()
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	now := time.Now()
+	idClaims := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"aud":   idp.clientID,
+		"sub":   "staff-42",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Minute).Unix(),
+		"nonce": query.Get("nonce"),
+	}
+	for k, v := range claims {
+		idClaims[k] = v
+	}
+	if signer == nil {
+		signer = idp.key
+	}
+
+	code := "code-" + query.Get("state")
+	idp.mu.Lock()
+	idp.codes[code] = issuedCode{challenge: query.Get("code_challenge"), claims: idClaims, signer: signer}
+	idp.mu.Unlock()
+	return query.Get("state"), code
+}
+
+func newTestClient(t *testing.T, idp *mockIdP) *Client {
+	client, err := NewClient(context.Background(), config.OIDCConfig{
+		IssuerURL:     idp.server.URL,
+		ClientID:      idp.clientID,
+		ClientSecret:  "secret",
+		RedirectURL:   "http://localhost:8080/api/auth/oidc/callback",
+		Scopes:        []string{"openid", "profile", "email"},
+		UsernameClaim: "preferred_username",
+		RoleClaim:     "groups",
+		RoleMapping:   map[string]string{"library-staff": model.RoleLibrarian, "it-admins": model.RoleAdmin},
+		DefaultRole:   model.RolePatron,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestExchange(t *testing.T) {
+	idp := newMockIdP(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name             string
+		claims           jwt.MapClaims
+		signer           *rsa.PrivateKey
+		tamper           func(c *Client, state, code string) (string, string)
+		expectedError    error
+		expectedUsername string
+		expectedRole     string
+	}{
+		{
+			name:             "Группы сопоставлены наибольшей роли",
+			claims:           jwt.MapClaims{"preferred_username": "ivanova", "email": "ivanova@uni.example", "groups": []string{"library-staff", "it-admins", "students"}},
+			expectedUsername: "ivanova",
+			expectedRole:     model.RoleAdmin,
+		},
+		{
+			name:             "Без групп назначается роль по умолчанию",
+			claims:           jwt.MapClaims{"email": "student@uni.example"},
+			expectedUsername: "student@uni.example",
+			expectedRole:     model.RolePatron,
+		},
+		{
+			name:          "Неизвестный state",
+			tamper:        func(_ *Client, _, code string) (string, string) { return "forged", code },
+			expectedError: ErrUnknownState,
+		},
+		{
+			name: "Повторное использование state",
+			tamper: func(c *Client, state, code string) (string, string) {
+				_, _ = c.Exchange(context.Background(), state, code)
+				return state, code
+			},
+			expectedError: ErrUnknownState,
+		},
+		{
+			name: "Просроченный вход",
+			tamper: func(c *Client, state, code string) (string, string) {
+				c.now = func() time.Time { return time.Now().Add(loginTTL + time.Minute) }
+				return state, code
+			},
+			expectedError: ErrUnknownState,
+		},
+		{
+			name:          "Чужой nonce",
+			claims:        jwt.MapClaims{"nonce": "replayed"},
+			expectedError: ErrInvalidIDToken,
+		},
+		{
+			name:          "ID-токен подписан чужим ключом",
+			signer:        otherKey,
+			expectedError: ErrInvalidIDToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			client := newTestClient(t, idp)
+			authURL, _, err := client.AuthCodeURL()
+			require.NoError(t, err)
+			state, code := idp.authorize(t, authURL, tc.claims, tc.signer)
+			if tc.tamper != nil {
+				state, code = tc.tamper(client, state, code)
+			}
+
+			// Act
+			identity, err := client.Exchange(context.Background(), state, code)
+
+			// Assert
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, idp.server.URL, identity.Issuer)
+			assert.Equal(t, "staff-42", identity.Subject)
+			assert.Equal(t, tc.expectedUsername, identity.Username)
+			assert.Equal(t, tc.expectedRole, identity.Role)
+		})
+	}
+}
+
+func TestExchangeRejectsCodeOfAnotherLogin(t *testing.T) {
+	// Arrange: код выдан для первого входа, а предъявлен с state второго,
+	// поэтому code_verifier не совпадает с code_challenge
+	idp := newMockIdP(t)
+	client := newTestClient(t, idp)
+	firstURL, _, err := client.AuthCodeURL()
+	require.NoError(t, err)
+	_, code := idp.authorize(t, firstURL, nil, nil)
+	_, secondState, err := client.AuthCodeURL()
+	require.NoError(t, err)
+
+	// Act
+	identity, err := client.Exchange(context.Background(), secondState, code)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, identity)
+}
+
+func TestAuthCodeURLLimitsPendingLogins(t *testing.T) {
+	// Arrange
+	client := newTestClient(t, newMockIdP(t))
+	for i := 0; i < maxPendingLogins; i++ {
+		client.pending[strconv.Itoa(i)] = pendingLogin{expiresAt: time.Now().Add(loginTTL)}
+	}
+
+	// Act
+	_, _, limitErr := client.AuthCodeURL()
+	client.now = func() time.Time { return time.Now().Add(loginTTL + time.Minute) }
+	_, state, err := client.AuthCodeURL()
+
+	// Assert
+	assert.ErrorIs(t, limitErr, ErrTooManyLogins)
+	require.NoError(t, err, "просроченные входы освобождают место")
+	assert.NotEmpty(t, state)
+	assert.Len(t, client.pending, 1)
+}
+
+func TestNewClientRejectsUnknownRole(t *testing.T) {
+	// Act
+	_, err := NewClient(context.Background(), config.OIDCConfig{
+		IssuerURL:   "http://127.0.0.1:0",
+		DefaultRole: model.RolePatron,
+		RoleMapping: map[string]string{"staff": "superuser"},
+	})
+
+	// Assert
+	assert.ErrorContains(t, err, "superuser")
+}
@@ -0,0 +1,60 @@
+// Package tracing настраивает OpenTelemetry: провайдер спанов с экспортером из
+// конфигурации и передачу контекста трассировки в заголовках W3C traceparent
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// Setup настраивает глобальные провайдер спанов и пропагатор. Экспортер stdout
+// пишет спаны в w. Возвращаемая функция отправляет оставшиеся спаны и
+// останавливает провайдер; при экспортере none она ничего не делает.
+func Setup(ctx context.Context, cfg config.TracingConfig, w io.Writer) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+	if !cfg.Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg, w)
+	if err != nil {
+		return nil, fmt.Errorf("экспортер трассировки %s: %w", cfg.Exporter, err)
+	}
+	provider := NewProvider(cfg, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider создает провайдер спанов с именем сервиса и долей записываемых
+// трасс из конфигурации. Решение вызывающего сервиса о записи трассы сохраняется.
+func NewProvider(cfg config.TracingConfig, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName))
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}, opts...)
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+func newExporter(ctx context.Context, cfg config.TracingConfig, w io.Writer) (sdktrace.SpanExporter, error) {
+	if cfg.Exporter == config.TracingExporterStdout {
+		return stdouttrace.New(stdouttrace.WithWriter(w))
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.New(ctx, opts...)
+}
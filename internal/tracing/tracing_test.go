@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/krawwwwy/book-library-api/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup(t *testing.T) {
+	testCases := []struct {
+		name         string
+		cfg          config.TracingConfig
+		expectOutput bool
+	}{
+		{name: "Экспортер stdout", cfg: config.TracingConfig{Exporter: config.TracingExporterStdout, ServiceName: "book-library-api", SampleRatio: 1}, expectOutput: true},
+		{name: "Трассы не записываются при доле 0", cfg: config.TracingConfig{Exporter: config.TracingExporterStdout, ServiceName: "book-library-api", SampleRatio: 0}},
+		{name: "Без экспортера", cfg: config.TracingConfig{Exporter: config.TracingExporterNone}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			previous := otel.GetTracerProvider()
+			t.Cleanup(func() { otel.SetTracerProvider(previous) })
+			var buf bytes.Buffer
+			shutdown, err := Setup(context.Background(), tc.cfg, &buf)
+			require.NoError(t, err)
+
+			// Act
+			_, span := otel.Tracer("test").Start(context.Background(), "BookService.CreateBook")
+			span.End()
+			require.NoError(t, shutdown(context.Background()))
+
+			// Assert
+			if tc.expectOutput {
+				assert.Contains(t, buf.String(), `"Name":"BookService.CreateBook"`)
+				assert.Contains(t, buf.String(), `"Value":"book-library-api"`)
+			} else {
+				assert.Empty(t, buf.String())
+			}
+		})
+	}
+}
+
+func TestSetupPropagator(t *testing.T) {
+	// Arrange
+	_, err := Setup(context.Background(), config.TracingConfig{Exporter: config.TracingExporterNone}, nil)
+	require.NoError(t, err)
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// Act
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+	out := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(out))
+
+	// Assert
+	sc := trace.SpanContextFromContext(ctx)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+	assert.True(t, sc.IsRemote())
+	assert.Equal(t, header.Get("traceparent"), out.Get("traceparent"))
+}
@@ -0,0 +1,187 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Служебные символы ISO 2709
+const (
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+)
+
+const (
+	leaderLength         = 24
+	directoryEntryLength = 12
+	maxRecordLength      = 99999
+	maxFieldLength       = 9999
+)
+
+// DefaultLeader используется при записи, если у записи нет собственного маркера.
+// Позиции длины записи и базового адреса заполняются при записи.
+const DefaultLeader = "00000nam a2200000 i 4500"
+
+// Reader читает записи MARC21 в формате ISO 2709
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader создает Reader, читающий записи из r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Read читает следующую запись. В конце потока возвращается io.EOF.
+// Если запись повреждена, возвращается ошибка, обернутая в ErrInvalidRecord,
+// и чтение можно продолжить со следующей записи.
+func (r *Reader) Read() (*Record, error) {
+	data, err := r.r.ReadBytes(recordTerminator)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	// Между записями часто встречаются переводы строк
+	data = bytes.TrimLeft(data, "\r\n")
+	if len(data) == 0 && err == io.EOF {
+		return nil, io.EOF
+	}
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: запись не завершена", ErrInvalidRecord)
+	}
+
+	return parseISO2709(data)
+}
+
+func parseISO2709(data []byte) (*Record, error) {
+	if len(data) < leaderLength+1 {
+		return nil, fmt.Errorf("%w: запись короче маркера", ErrInvalidRecord)
+	}
+
+	leader := string(data[:leaderLength])
+	base, err := strconv.Atoi(leader[12:17])
+	if err != nil || base <= leaderLength || base > len(data) {
+		return nil, fmt.Errorf("%w: неверный базовый адрес данных", ErrInvalidRecord)
+	}
+
+	directory := data[leaderLength : base-1]
+	if len(directory)%directoryEntryLength != 0 {
+		return nil, fmt.Errorf("%w: неверная длина справочника", ErrInvalidRecord)
+	}
+
+	rec := &Record{Leader: leader}
+	for i := 0; i < len(directory); i += directoryEntryLength {
+		entry := directory[i : i+directoryEntryLength]
+		tag := string(entry[:3])
+		length, errLen := strconv.Atoi(string(entry[3:7]))
+		start, errStart := strconv.Atoi(string(entry[7:12]))
+		if errLen != nil || errStart != nil || length < 0 || start < 0 || base+start+length > len(data) {
+			return nil, fmt.Errorf("%w: неверная запись справочника для поля %s", ErrInvalidRecord, tag)
+		}
+
+		field := bytes.TrimSuffix(data[base+start:base+start+length], []byte{fieldTerminator})
+		if isControlTag(tag) {
+			rec.ControlFields = append(rec.ControlFields, ControlField{Tag: tag, Value: string(field)})
+			continue
+		}
+
+		if len(field) < 2 {
+			return nil, fmt.Errorf("%w: у поля %s нет индикаторов", ErrInvalidRecord, tag)
+		}
+		df := DataField{Tag: tag, Ind1: field[0], Ind2: field[1]}
+		for _, chunk := range bytes.Split(field[2:], []byte{subfieldDelimiter})[1:] {
+			if len(chunk) == 0 {
+				continue
+			}
+			df.Subfields = append(df.Subfields, Subfield{Code: chunk[0], Value: string(chunk[1:])})
+		}
+		rec.DataFields = append(rec.DataFields, df)
+	}
+
+	return rec, nil
+}
+
+// Writer записывает записи MARC21 в формате ISO 2709
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter создает Writer, пишущий записи в w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write записывает запись. Длина записи, базовый адрес и кодировка (UTF-8)
+// в маркере вычисляются заново.
+func (w *Writer) Write(rec *Record) error {
+	var directory, data bytes.Buffer
+
+	addField := func(tag string, body []byte) error {
+		length := len(body) + 1
+		if len(tag) != 3 || length > maxFieldLength {
+			return fmt.Errorf("%w: поле %s не может быть записано", ErrInvalidRecord, tag)
+		}
+		fmt.Fprintf(&directory, "%s%04d%05d", tag, length, data.Len())
+		data.Write(body)
+		data.WriteByte(fieldTerminator)
+		return nil
+	}
+
+	for _, f := range rec.ControlFields {
+		if err := addField(f.Tag, []byte(f.Value)); err != nil {
+			return err
+		}
+	}
+	for _, f := range rec.DataFields {
+		var body bytes.Buffer
+		body.WriteByte(indicator(f.Ind1))
+		body.WriteByte(indicator(f.Ind2))
+		for _, sf := range f.Subfields {
+			body.WriteByte(subfieldDelimiter)
+			body.WriteByte(sf.Code)
+			body.WriteString(sf.Value)
+		}
+		if err := addField(f.Tag, body.Bytes()); err != nil {
+			return err
+		}
+	}
+	directory.WriteByte(fieldTerminator)
+
+	base := leaderLength + directory.Len()
+	total := base + data.Len() + 1
+	if total > maxRecordLength {
+		return fmt.Errorf("%w: запись длиннее %d байт", ErrInvalidRecord, maxRecordLength)
+	}
+
+	leader := []byte(DefaultLeader)
+	if len(rec.Leader) == leaderLength {
+		leader = []byte(rec.Leader)
+	}
+	copy(leader[0:5], fmt.Sprintf("%05d", total))
+	leader[9] = 'a'
+	copy(leader[10:12], "22")
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+	copy(leader[20:24], "4500")
+
+	var out bytes.Buffer
+	out.Grow(total)
+	out.Write(leader)
+	out.Write(directory.Bytes())
+	out.Write(data.Bytes())
+	out.WriteByte(recordTerminator)
+
+	_, err := w.w.Write(out.Bytes())
+	return err
+}
+
+// indicator заменяет незаданный индикатор пробелом
+func indicator(b byte) byte {
+	if b == 0 {
+		return ' '
+	}
+	return b
+}
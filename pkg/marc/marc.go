@@ -0,0 +1,104 @@
+// Package marc реализует чтение и запись библиографических записей MARC21
+// в форматах ISO 2709 и MARCXML.
+package marc
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidRecord возвращается, если запись MARC не удалось разобрать
+var ErrInvalidRecord = errors.New("неверная запись MARC")
+
+// Record представляет библиографическую запись MARC21
+type Record struct {
+	Leader        string
+	ControlFields []ControlField
+	DataFields    []DataField
+}
+
+// ControlField представляет управляющее поле (001-009) без индикаторов и подполей
+type ControlField struct {
+	Tag   string
+	Value string
+}
+
+// DataField представляет поле данных с индикаторами и подполями
+type DataField struct {
+	Tag       string
+	Ind1      byte
+	Ind2      byte
+	Subfields []Subfield
+}
+
+// Subfield представляет подполе поля данных
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// NewDataField создает поле данных. Пустые подполя пропускаются.
+func NewDataField(tag string, ind1, ind2 byte, subfields ...Subfield) DataField {
+	field := DataField{Tag: tag, Ind1: ind1, Ind2: ind2}
+	for _, sf := range subfields {
+		if sf.Value != "" {
+			field.Subfields = append(field.Subfields, sf)
+		}
+	}
+	return field
+}
+
+// ControlField возвращает значение первого управляющего поля с тегом tag
+func (r *Record) ControlField(tag string) string {
+	for _, f := range r.ControlFields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Fields возвращает все поля данных с тегом tag
+func (r *Record) Fields(tag string) []DataField {
+	var fields []DataField
+	for _, f := range r.DataFields {
+		if f.Tag == tag {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// SubfieldValue возвращает значение первого подполя code первого поля с тегом tag
+func (r *Record) SubfieldValue(tag string, code byte) string {
+	for _, f := range r.DataFields {
+		if f.Tag == tag {
+			if v := f.Subfield(code); v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// AddDataField добавляет поле данных, если в нем есть хотя бы одно подполе
+func (r *Record) AddDataField(field DataField) {
+	if len(field.Subfields) > 0 {
+		r.DataFields = append(r.DataFields, field)
+	}
+}
+
+// Subfield возвращает значение первого подполя с кодом code
+func (f DataField) Subfield(code byte) string {
+	for _, sf := range f.Subfields {
+		if sf.Code == code {
+			return sf.Value
+		}
+	}
+	return ""
+}
+
+// isControlTag сообщает, является ли тег управляющим полем
+func isControlTag(tag string) bool {
+	return strings.HasPrefix(tag, "00")
+}
@@ -0,0 +1,148 @@
+package marc
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRecord() *Record {
+	rec := &Record{
+		ControlFields: []ControlField{{Tag: "001", Value: "42"}},
+	}
+	rec.AddDataField(NewDataField("020", ' ', ' ', Subfield{Code: 'a', Value: "9785171147440"}))
+	rec.AddDataField(NewDataField("100", '1', ' ', Subfield{Code: 'a', Value: "Толстой, Лев"}))
+	rec.AddDataField(NewDataField("245", '1', '0',
+		Subfield{Code: 'a', Value: "Война и мир"},
+		Subfield{Code: 'b', Value: ""},
+	))
+	rec.AddDataField(NewDataField("520", ' ', ' '))
+	return rec
+}
+
+func TestISO2709RoundTrip(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	writer := NewWriter(&buf)
+
+	// Act
+	require.NoError(t, writer.Write(testRecord()))
+	require.NoError(t, writer.Write(testRecord()))
+
+	reader := NewReader(&buf)
+	first, err := reader.Read()
+	require.NoError(t, err)
+	_, err = reader.Read()
+	require.NoError(t, err)
+	_, err = reader.Read()
+
+	// Assert
+	assert.Equal(t, io.EOF, err)
+	assert.Len(t, first.Leader, 24)
+	assert.Equal(t, "42", first.ControlField("001"))
+	assert.Equal(t, "9785171147440", first.SubfieldValue("020", 'a'))
+	assert.Equal(t, "Толстой, Лев", first.SubfieldValue("100", 'a'))
+	assert.Equal(t, "Война и мир", first.SubfieldValue("245", 'a'))
+	assert.Len(t, first.Fields("245")[0].Subfields, 1, "пустые подполя не записываются")
+	assert.Empty(t, first.Fields("520"), "поля без подполей не записываются")
+	assert.Equal(t, byte('1'), first.Fields("245")[0].Ind1)
+}
+
+func TestISO2709RecordLength(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+
+	// Act
+	require.NoError(t, NewWriter(&buf).Write(testRecord()))
+
+	// Assert: длина в маркере совпадает с длиной записи в байтах
+	length, err := strconv.Atoi(buf.String()[0:5])
+	require.NoError(t, err)
+	assert.Equal(t, buf.Len(), length)
+}
+
+func TestISO2709InvalidRecord(t *testing.T) {
+	testCases := []struct {
+		name   string
+		record string
+	}{
+		{name: "Неверный базовый адрес", record: "00010nam a22ABCDE i 4500\x1e\x1d"},
+		{name: "Отрицательная длина поля", record: "00042nam a2200037 i 4500245-00100000\x1e10\x1fa\x1e\x1d"},
+		{name: "Отрицательное начало поля", record: "00042nam a2200037 i 45002450004-0001\x1e10\x1fa\x1e\x1d"},
+		{name: "Поле за концом записи", record: "00042nam a2200037 i 4500245999900000\x1e10\x1fa\x1e\x1d"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			buf.WriteString(tc.record)
+			require.NoError(t, NewWriter(&buf).Write(testRecord()))
+			reader := NewReader(&buf)
+
+			// Act
+			_, errBroken := reader.Read()
+			next, errNext := reader.Read()
+
+			// Assert: поврежденная запись не мешает читать следующие
+			assert.ErrorIs(t, errBroken, ErrInvalidRecord)
+			assert.NoError(t, errNext)
+			assert.Equal(t, "42", next.ControlField("001"))
+		})
+	}
+}
+
+func TestMARCXMLRoundTrip(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	writer := NewXMLWriter(&buf)
+
+	// Act
+	require.NoError(t, writer.Write(testRecord()))
+	require.NoError(t, writer.Close())
+
+	reader := NewXMLReader(&buf)
+	rec, err := reader.Read()
+	require.NoError(t, err)
+	_, err = reader.Read()
+
+	// Assert
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, "42", rec.ControlField("001"))
+	assert.Equal(t, "Война и мир", rec.SubfieldValue("245", 'a'))
+	assert.Equal(t, byte('0'), rec.Fields("245")[0].Ind2)
+}
+
+func TestMARCXMLReadSingleRecord(t *testing.T) {
+	// Arrange
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<marc:record xmlns:marc="http://www.loc.gov/MARC21/slim">
+  <marc:leader>00000nam a2200000 i 4500</marc:leader>
+  <marc:datafield tag="020" ind1=" " ind2=" ">
+    <marc:subfield code="a">9785171147457</marc:subfield>
+  </marc:datafield>
+</marc:record>`
+
+	// Act
+	rec, err := NewXMLReader(strings.NewReader(input)).Read()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "9785171147457", rec.SubfieldValue("020", 'a'))
+}
+
+func TestMARCXMLEmptyCollection(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+
+	// Act
+	require.NoError(t, NewXMLWriter(&buf).Close())
+
+	// Assert
+	assert.Contains(t, buf.String(), `<collection xmlns="http://www.loc.gov/MARC21/slim"></collection>`)
+}
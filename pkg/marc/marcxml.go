@@ -0,0 +1,166 @@
+package marc
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// XMLNamespace задает пространство имен MARCXML
+const XMLNamespace = "http://www.loc.gov/MARC21/slim"
+
+type xmlRecord struct {
+	XMLName       xml.Name          `xml:"record"`
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// XMLReader читает записи MARCXML. Поддерживаются как отдельная запись,
+// так и коллекция записей; документ разбирается потоково.
+type XMLReader struct {
+	d *xml.Decoder
+}
+
+// NewXMLReader создает XMLReader, читающий записи из r
+func NewXMLReader(r io.Reader) *XMLReader {
+	return &XMLReader{d: xml.NewDecoder(r)}
+}
+
+// Read читает следующую запись. В конце документа возвращается io.EOF.
+func (r *XMLReader) Read() (*Record, error) {
+	for {
+		token, err := r.d.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		var xr xmlRecord
+		if err := r.d.DecodeElement(&xr, &start); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+		return fromXMLRecord(&xr), nil
+	}
+}
+
+func fromXMLRecord(xr *xmlRecord) *Record {
+	rec := &Record{Leader: xr.Leader}
+	for _, f := range xr.ControlFields {
+		rec.ControlFields = append(rec.ControlFields, ControlField{Tag: f.Tag, Value: f.Value})
+	}
+	for _, f := range xr.DataFields {
+		df := DataField{Tag: f.Tag, Ind1: firstByte(f.Ind1), Ind2: firstByte(f.Ind2)}
+		for _, sf := range f.Subfields {
+			df.Subfields = append(df.Subfields, Subfield{Code: firstByte(sf.Code), Value: sf.Value})
+		}
+		rec.DataFields = append(rec.DataFields, df)
+	}
+	return rec
+}
+
+func toXMLRecord(rec *Record) *xmlRecord {
+	xr := &xmlRecord{Leader: rec.Leader}
+	if xr.Leader == "" {
+		xr.Leader = DefaultLeader
+	}
+	for _, f := range rec.ControlFields {
+		xr.ControlFields = append(xr.ControlFields, xmlControlField{Tag: f.Tag, Value: f.Value})
+	}
+	for _, f := range rec.DataFields {
+		df := xmlDataField{
+			Tag:  f.Tag,
+			Ind1: string(indicator(f.Ind1)),
+			Ind2: string(indicator(f.Ind2)),
+		}
+		for _, sf := range f.Subfields {
+			df.Subfields = append(df.Subfields, xmlSubfield{Code: string(sf.Code), Value: sf.Value})
+		}
+		xr.DataFields = append(xr.DataFields, df)
+	}
+	return xr
+}
+
+func firstByte(s string) byte {
+	if s == "" {
+		return ' '
+	}
+	return s[0]
+}
+
+// XMLWriter записывает записи в коллекцию MARCXML.
+// После записи всех записей необходимо вызвать Close.
+type XMLWriter struct {
+	w       io.Writer
+	e       *xml.Encoder
+	started bool
+}
+
+// NewXMLWriter создает XMLWriter, пишущий коллекцию в w
+func NewXMLWriter(w io.Writer) *XMLWriter {
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	return &XMLWriter{w: w, e: e}
+}
+
+// Write записывает запись в коллекцию
+func (w *XMLWriter) Write(rec *Record) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if err := w.e.Encode(toXMLRecord(rec)); err != nil {
+		return err
+	}
+	return w.e.Flush()
+}
+
+// Close завершает коллекцию. Writer, в который не записано ни одной записи,
+// выводит пустую коллекцию.
+func (w *XMLWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if err := w.e.EncodeToken(xml.EndElement{Name: xml.Name{Local: "collection"}}); err != nil {
+		return err
+	}
+	return w.e.Flush()
+}
+
+func (w *XMLWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+
+	if _, err := io.WriteString(w.w, xml.Header); err != nil {
+		return err
+	}
+	return w.e.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "collection"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: XMLNamespace}},
+	})
+}
@@ -159,7 +159,7 @@ function saveBook() {
         publisher: document.getElementById('publisher').value
     };
     
-    fetch(`${API_URL}/books`, {
+    authFetch(`${API_URL}/books`, {
         method: 'POST',
         headers: {
             'Content-Type': 'application/json'
@@ -228,7 +228,7 @@ function updateBook() {
         publisher: document.getElementById('edit-publisher').value
     };
     
-    fetch(`${API_URL}/books/${bookId}`, {
+    authFetch(`${API_URL}/books/${bookId}`, {
         method: 'PUT',
         headers: {
             'Content-Type': 'application/json'
@@ -257,7 +257,7 @@ function updateBook() {
 
 // Изменение доступности книги
 function toggleAvailability(bookId) {
-    fetch(`${API_URL}/books/${bookId}/toggle-availability`, {
+    authFetch(`${API_URL}/books/${bookId}/toggle-availability`, {
         method: 'POST'
     })
     .then(response => {
@@ -280,7 +280,7 @@ function toggleAvailability(bookId) {
 // Удаление книги
 function deleteBook(bookId) {
     if (confirm('Вы уверены, что хотите удалить эту книгу?')) {
-        fetch(`${API_URL}/books/${bookId}`, {
+        authFetch(`${API_URL}/books/${bookId}`, {
             method: 'DELETE'
         })
         .then(response => {
@@ -297,6 +297,77 @@ function deleteBook(bookId) {
     }
 }
 
+// Запрос с access-токеном. При ответе 401 пытается обновить токены
+// или запрашивает вход и повторяет запрос один раз.
+function authFetch(url, options = {}) {
+    const send = () => fetch(url, {
+        ...options,
+        headers: { ...(options.headers || {}), ...authHeader() }
+    });
+
+    return send().then(response => {
+        if (response.status === 403) {
+            throw new Error('Недостаточно прав для этого действия');
+        }
+        if (response.status !== 401) {
+            return response;
+        }
+        return refreshTokens()
+            .catch(() => login())
+            .then(send);
+    });
+}
+
+function authHeader() {
+    const token = localStorage.getItem('accessToken');
+    return token ? { 'Authorization': `Bearer ${token}` } : {};
+}
+
+function storeTokens(tokens) {
+    localStorage.setItem('accessToken', tokens.access_token);
+    localStorage.setItem('refreshToken', tokens.refresh_token);
+}
+
+function refreshTokens() {
+    const refreshToken = localStorage.getItem('refreshToken');
+    if (!refreshToken) {
+        return Promise.reject(new Error('Нет refresh-токена'));
+    }
+    return fetch(`${API_URL}/auth/refresh`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: refreshToken })
+    })
+    .then(response => {
+        if (!response.ok) {
+            localStorage.removeItem('refreshToken');
+            throw new Error('Сессия истекла');
+        }
+        return response.json();
+    })
+    .then(storeTokens);
+}
+
+function login() {
+    const username = prompt('Имя пользователя');
+    const password = username ? prompt('Пароль') : null;
+    if (!username || !password) {
+        return Promise.reject(new Error('Требуется вход'));
+    }
+    return fetch(`${API_URL}/auth/login`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ username, password })
+    })
+    .then(response => {
+        if (!response.ok) {
+            throw new Error('Неверное имя пользователя или пароль');
+        }
+        return response.json();
+    })
+    .then(storeTokens);
+}
+
 // Показать сообщение
 function showMessage(message, type) {
     const messageBox = document.getElementById('message-box');